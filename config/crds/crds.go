@@ -17,8 +17,10 @@ var crdYamlFiles = []string{
 	"migration.openshift.io_migclusters.yaml",
 	"migration.openshift.io_migmigrations.yaml",
 	"migration.openshift.io_mighooks.yaml",
+	"migration.openshift.io_migschedules.yaml",
 	"migration.openshift.io_migstorages.yaml",
 	"migration.openshift.io_migplans.yaml",
+	"migration.openshift.io_migwaves.yaml",
 }
 
 func Crds() ([]*apiextv1.CustomResourceDefinition, error) {
@@ -10,6 +10,7 @@ require (
 	github.com/aws/aws-sdk-go v1.44.253
 	github.com/containers/image/v5 v5.17.0
 	github.com/deckarep/golang-set v1.7.1
+	github.com/evanphx/json-patch v5.6.0+incompatible
 	github.com/gin-contrib/cors v1.3.1
 	github.com/gin-gonic/gin v1.9.1
 	github.com/go-logr/logr v1.2.3
@@ -76,7 +77,6 @@ require (
 	github.com/docker/go-metrics v0.0.1 // indirect
 	github.com/docker/go-units v0.4.0 // indirect
 	github.com/emicklei/go-restful/v3 v3.8.0 // indirect
-	github.com/evanphx/json-patch v5.6.0+incompatible // indirect
 	github.com/fsnotify/fsnotify v1.5.4 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.2 // indirect
 	github.com/ghodss/yaml v1.0.0 // indirect
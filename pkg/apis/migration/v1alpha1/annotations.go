@@ -30,4 +30,6 @@ const (
 	// Disables the internal image copy
 	DisableImageCopy         = "migration.openshift.io/disable-image-copy"
 	StateMigrationAnnotation = "migration.openshift.io/state-transfer"
+	// Comma-separated list of the transforms applied to a restored resource
+	TransformsAnnotation = "migration.openshift.io/transforms"
 )
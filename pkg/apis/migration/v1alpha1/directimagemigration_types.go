@@ -36,6 +36,9 @@ type DirectImageMigrationSpec struct {
 
 	// Holds names of all namespaces to run DIM to get all the imagestreams in these namespaces.
 	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Set true to hold the migration at the next phase boundary
+	Paused bool `json:"paused,omitempty"`
 }
 
 // DirectImageMigrationStatus defines the observed state of DirectImageMigration
@@ -47,6 +47,11 @@ type PVCToMigrate struct {
 	TargetName string `json:"targetName,omitempty"`
 	// Verify set true to verify integrity of the data post migration
 	Verify bool `json:"verify,omitempty"`
+	// RsyncOptions rsync options of the PVC, overriding the options of the DVM
+	RsyncOptions *RsyncOptions `json:"rsyncOptions,omitempty"`
+	// LastSyncedManifest manifest of the source volume at the last successful transfer
+	// The transfer is skipped when the manifest of the source volume is unchanged
+	LastSyncedManifest string `json:"lastSyncedManifest,omitempty"`
 }
 
 // DirectVolumeMigrationSpec defines the desired state of DirectVolumeMigration
@@ -65,6 +70,18 @@ type DirectVolumeMigrationSpec struct {
 
 	// Specifies if progress reporting CRs needs to be deleted or not
 	DeleteProgressReportingCRs bool `json:"deleteProgressReportingCRs,omitempty"`
+
+	// Set true to hold the migration at the next phase boundary
+	Paused bool `json:"paused,omitempty"`
+
+	// RsyncOptions rsync options overriding the controller-wide defaults
+	RsyncOptions *RsyncOptions `json:"rsyncOptions,omitempty"`
+
+	// RsyncTransferLimits maximum numbers of concurrent rsync transfers on the source cluster overriding the controller-wide limits
+	RsyncTransferLimits *RsyncTransferLimits `json:"rsyncTransferLimits,omitempty"`
+
+	// Set true to compute the mtime and size manifests of the source volumes and record the transfers in the plan
+	Incremental bool `json:"incremental,omitempty"`
 }
 
 // DirectVolumeMigrationStatus defines the observed state of DirectVolumeMigration
@@ -81,6 +98,10 @@ type DirectVolumeMigrationStatus struct {
 	RunningPods      []*PodProgress    `json:"runningPods,omitempty"`
 	PendingPods      []*PodProgress    `json:"pendingPods,omitempty"`
 	RsyncOperations  []*RsyncOperation `json:"rsyncOperations,omitempty"`
+	// ActiveBwLimit bandwidth limit in KiB/s of the DVM rsync options, 0 when not limited
+	ActiveBwLimit *int `json:"activeBwLimit,omitempty"`
+	// ActiveBandwidthWindow window of the bandwidth schedule applied, empty outside of the windows
+	ActiveBandwidthWindow string `json:"activeBandwidthWindow,omitempty"`
 }
 
 // GetRsyncOperationStatusForPVC returns RsyncOperation from status for matching PVC, creates new one if doesn't exist already
@@ -111,6 +132,12 @@ func (ds *DirectVolumeMigrationStatus) AddRsyncOperation(podStatus *RsyncOperati
 			existing.CurrentAttempt = podStatus.CurrentAttempt
 			existing.Failed = podStatus.Failed
 			existing.Succeeded = podStatus.Succeeded
+			existing.BwLimit = podStatus.BwLimit
+			existing.Queued = podStatus.Queued
+			existing.Verification = podStatus.Verification
+			existing.Manifest = podStatus.Manifest
+			existing.Skipped = podStatus.Skipped
+			existing.Stats = podStatus.Stats
 			return
 		}
 	}
@@ -161,6 +188,46 @@ type RsyncOperation struct {
 	Succeeded bool `json:"succeeded,omitempty"`
 	// Failed whether operation as a whole failed
 	Failed bool `json:"failed,omitempty"`
+	// BwLimit bandwidth limit in KiB/s of the current attempt, 0 when not limited
+	BwLimit *int `json:"bwLimit,omitempty"`
+	// Queued whether the operation waits for running operations to complete
+	Queued bool `json:"queued,omitempty"`
+	// Verification result of the data verification of the PVC
+	Verification *RsyncVerification `json:"verification,omitempty"`
+	// Manifest mtime and size manifest of the source volume computed before the transfer
+	Manifest string `json:"manifest,omitempty"`
+	// Skipped whether the transfer was skipped as the source volume did not change since the last transfer
+	Skipped bool `json:"skipped,omitempty"`
+	// Stats statistics of the last attempt of a successful transfer
+	Stats *RsyncTransferStats `json:"stats,omitempty"`
+}
+
+// RsyncTransferStats statistics of a successful Rsync transfer
+type RsyncTransferStats struct {
+	// StartTime start time of the transfer
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Duration duration of the transfer
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Files number of files of the source volume
+	Files int64 `json:"files,omitempty"`
+	// FilesTransferred number of regular files transferred
+	FilesTransferred int64 `json:"filesTransferred,omitempty"`
+	// Bytes total size in bytes of the files of the source volume
+	Bytes int64 `json:"bytes,omitempty"`
+	// BytesTransferred total size in bytes of the files transferred
+	BytesTransferred int64 `json:"bytesTransferred,omitempty"`
+}
+
+// RsyncVerification defines observed state of the data verification of an Rsync Operation
+type RsyncVerification struct {
+	// Completed whether the verification completed
+	Completed bool `json:"completed,omitempty"`
+	// MismatchedFiles number of files differing between the source and destination volumes
+	MismatchedFiles int `json:"mismatchedFiles,omitempty"`
+	// Files first files differing between the source and destination volumes
+	Files []string `json:"files,omitempty"`
+	// Error why the verification could not be completed
+	Error string `json:"error,omitempty"`
 }
 
 func (x *RsyncOperation) Equal(y *RsyncOperation) bool {
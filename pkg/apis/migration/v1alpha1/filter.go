@@ -0,0 +1,383 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceFilter selects namespaced resources included in or excluded from a migration.
+// A resource matches the filter when it matches all the criteria set.
+// The Velero Backups select the matching resources when the filters can be expressed
+// as namespaces, kinds and label selectors, the resources restored but not selected
+// by the filters are deleted from the destination cluster otherwise.
+type ResourceFilter struct {
+	// Excludes the matching resources. The matching resources are included when not set.
+	Exclude bool `json:"exclude,omitempty"`
+
+	// Source namespaces the filter applies to. All the namespaces of the plan when not set. The resources of these namespaces are restricted to those matching the include filters.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Resource kinds matching the filter. All the kinds when not set.
+	Kinds []metav1.GroupKind `json:"kinds,omitempty"`
+
+	// Regular expression matching the resource names.
+	NameRegex string `json:"nameRegex,omitempty"`
+
+	// Label selector matching the resources.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// Annotations of the resources. An empty value matches any value of the annotation.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Validate the filter.
+func (r *ResourceFilter) Validate() error {
+	if len(r.Namespaces) == 0 &&
+		len(r.Kinds) == 0 &&
+		r.NameRegex == "" &&
+		r.LabelSelector == nil &&
+		len(r.Annotations) == 0 {
+		return fmt.Errorf("one of namespaces, kinds, nameRegex, labelSelector and annotations must be set")
+	}
+	if r.NameRegex != "" {
+		_, err := regexp.Compile(r.NameRegex)
+		if err != nil {
+			return fmt.Errorf("nameRegex: %s", err.Error())
+		}
+	}
+	if r.LabelSelector != nil {
+		if len(r.LabelSelector.MatchLabels) == 0 && len(r.LabelSelector.MatchExpressions) == 0 {
+			return fmt.Errorf("labelSelector: must not be empty")
+		}
+		_, err := metav1.LabelSelectorAsSelector(r.LabelSelector)
+		if err != nil {
+			return fmt.Errorf("labelSelector: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// AppliesTo returns whether the filter applies to the resources of a namespace.
+func (r *ResourceFilter) AppliesTo(namespace string) bool {
+	if len(r.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range r.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesKind returns whether the filter matches the kind.
+func (r *ResourceFilter) MatchesKind(kind schema.GroupKind) bool {
+	if len(r.Kinds) == 0 {
+		return true
+	}
+	for _, k := range r.Kinds {
+		if k.Group == kind.Group && k.Kind == kind.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns whether the resource matches the filter.
+func (r *ResourceFilter) Matches(kind schema.GroupKind, object metav1.Object) (bool, error) {
+	if !r.AppliesTo(object.GetNamespace()) || !r.MatchesKind(kind) {
+		return false, nil
+	}
+	if r.NameRegex != "" {
+		matched, err := regexp.MatchString(r.NameRegex, object.GetName())
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	if r.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(r.LabelSelector)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(labels.Set(object.GetLabels())) {
+			return false, nil
+		}
+	}
+	annotations := object.GetAnnotations()
+	for key, value := range r.Annotations {
+		actual, found := annotations[key]
+		if !found || (value != "" && value != actual) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Get whether the filter matches the resources of its namespaces only.
+func (r *ResourceFilter) namespaceOnly() bool {
+	return len(r.Namespaces) > 0 &&
+		len(r.Kinds) == 0 &&
+		r.NameRegex == "" &&
+		r.LabelSelector == nil &&
+		len(r.Annotations) == 0
+}
+
+// Get whether the filter matches the resources by kind only, in the namespaces it applies to.
+func (r *ResourceFilter) kindOnly() bool {
+	return len(r.Kinds) > 0 &&
+		r.NameRegex == "" &&
+		r.LabelSelector == nil &&
+		len(r.Annotations) == 0
+}
+
+// Get whether the filter matches the resources by label only, in the namespaces it applies to.
+func (r *ResourceFilter) labelOnly() bool {
+	return r.LabelSelector != nil &&
+		len(r.Kinds) == 0 &&
+		r.NameRegex == "" &&
+		len(r.Annotations) == 0
+}
+
+// ResourceFilters selects the resources migrated.
+// A resource is excluded when it matches an exclude filter. Otherwise, it
+// is included unless include filters apply to its namespace, in which case
+// it must match one of them.
+type ResourceFilters []ResourceFilter
+
+// Selects returns whether the resource is migrated.
+func (r ResourceFilters) Selects(kind schema.GroupKind, object metav1.Object) (bool, error) {
+	for i := range r {
+		filter := &r[i]
+		if !filter.Exclude {
+			continue
+		}
+		matched, err := filter.Matches(kind, object)
+		if err != nil || matched {
+			return false, err
+		}
+	}
+	included := true
+	for i := range r {
+		filter := &r[i]
+		if filter.Exclude || !filter.AppliesTo(object.GetNamespace()) {
+			continue
+		}
+		matched, err := filter.Matches(kind, object)
+		if err != nil || matched {
+			return matched, err
+		}
+		included = false
+	}
+	return included, nil
+}
+
+// Get the include filters when they apply to each of the namespaces.
+// Returns nil when the resources of one of the namespaces are not restricted.
+func (r ResourceFilters) includes(namespaces []string) []*ResourceFilter {
+	includes := []*ResourceFilter{}
+	for i := range r {
+		if !r[i].Exclude {
+			includes = append(includes, &r[i])
+		}
+	}
+	if len(includes) == 0 {
+		return nil
+	}
+	for _, ns := range namespaces {
+		applies := false
+		for _, filter := range includes {
+			applies = applies || filter.AppliesTo(ns)
+		}
+		if !applies {
+			return nil
+		}
+	}
+	return includes
+}
+
+// ExcludedNamespaces returns the namespaces excluded by the filters.
+func (r ResourceFilters) ExcludedNamespaces() []string {
+	namespaces := []string{}
+	for i := range r {
+		filter := &r[i]
+		if filter.Exclude && filter.namespaceOnly() {
+			namespaces = append(namespaces, filter.Namespaces...)
+		}
+	}
+	return namespaces
+}
+
+// ExcludedKinds returns the kinds excluded in all the namespaces.
+func (r ResourceFilters) ExcludedKinds() []metav1.GroupKind {
+	kinds := []metav1.GroupKind{}
+	for i := range r {
+		filter := &r[i]
+		if filter.Exclude && filter.kindOnly() && len(filter.Namespaces) == 0 {
+			kinds = append(kinds, filter.Kinds...)
+		}
+	}
+	return kinds
+}
+
+// IncludedKinds returns the kinds included in the namespaces when the include
+// filters select the resources of each of the namespaces by kind only.
+// Returns empty when the kinds are not filtered.
+func (r ResourceFilters) IncludedKinds(namespaces []string) []metav1.GroupKind {
+	kinds := []metav1.GroupKind{}
+	includes := r.includes(namespaces)
+	for _, filter := range includes {
+		if !filter.kindOnly() {
+			return []metav1.GroupKind{}
+		}
+	}
+	for _, filter := range includes {
+		kinds = append(kinds, filter.Kinds...)
+	}
+	return kinds
+}
+
+// SelectedByVelero returns whether the Velero selectors built from the filters for
+// the namespaces select the resources selected by the filters and no other resource.
+// The resources not selected by the filters must be deleted after the restore otherwise.
+func (r ResourceFilters) SelectedByVelero(namespaces []string) bool {
+	includes := []*ResourceFilter{}
+	for i := range r {
+		filter := &r[i]
+		if filter.Exclude {
+			if filter.namespaceOnly() ||
+				(len(filter.Namespaces) == 0 && (filter.kindOnly() || filter.labelOnly())) {
+				continue
+			}
+			return false
+		}
+		if len(filter.Namespaces) > 0 {
+			return false
+		}
+		includes = append(includes, filter)
+	}
+	if len(includes) == 0 {
+		return true
+	}
+	return len(r.IncludedKinds(namespaces)) > 0 || r.labelIncludes(namespaces) != nil
+}
+
+// Get the include filters when they select the resources of each of the namespaces by label only.
+func (r ResourceFilters) labelIncludes(namespaces []string) []*ResourceFilter {
+	includes := r.includes(namespaces)
+	for _, filter := range includes {
+		if !filter.labelOnly() {
+			return nil
+		}
+	}
+	return includes
+}
+
+// LabelSelectors returns the label selectors matching the resources selected by the label
+// filters and by the base selector in the namespaces, a resource is selected when it matches
+// any of them. The include filters are applied when they select the resources of each of the
+// namespaces by label only. The requirements of an exclude filter applying to all the
+// namespaces are negated, a resource not matching one of the requirements is not excluded.
+// Returns nil when the resources are not filtered by label.
+func (r ResourceFilters) LabelSelectors(base *metav1.LabelSelector, namespaces []string) []*metav1.LabelSelector {
+	selectors := [][]metav1.LabelSelectorRequirement{}
+	baseRequirements := labelSelectorRequirements(base)
+	for _, filter := range r.labelIncludes(namespaces) {
+		requirements := append([]metav1.LabelSelectorRequirement{}, baseRequirements...)
+		selectors = append(selectors, append(requirements, labelSelectorRequirements(filter.LabelSelector)...))
+	}
+	filtered := len(selectors) > 0
+	if !filtered {
+		selectors = append(selectors, baseRequirements)
+	}
+	for i := range r {
+		filter := &r[i]
+		if !filter.Exclude || !filter.labelOnly() || len(filter.Namespaces) > 0 {
+			continue
+		}
+		filtered = true
+		negated := [][]metav1.LabelSelectorRequirement{}
+		for _, requirements := range selectors {
+			for _, requirement := range labelSelectorRequirements(filter.LabelSelector) {
+				next := append([]metav1.LabelSelectorRequirement{}, requirements...)
+				negated = append(negated, append(next, negateLabelSelectorRequirement(requirement)))
+			}
+		}
+		selectors = negated
+	}
+	if !filtered {
+		return nil
+	}
+	labelSelectors := []*metav1.LabelSelector{}
+	for _, requirements := range selectors {
+		labelSelectors = append(labelSelectors, &metav1.LabelSelector{MatchExpressions: requirements})
+	}
+	return labelSelectors
+}
+
+// Get the requirements of a label selector, the labels are converted to In requirements.
+func labelSelectorRequirements(selector *metav1.LabelSelector) []metav1.LabelSelectorRequirement {
+	requirements := []metav1.LabelSelectorRequirement{}
+	if selector == nil {
+		return requirements
+	}
+	keys := make([]string, 0, len(selector.MatchLabels))
+	for key := range selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		requirements = append(requirements, metav1.LabelSelectorRequirement{
+			Key:      key,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{selector.MatchLabels[key]},
+		})
+	}
+	for _, requirement := range selector.MatchExpressions {
+		requirements = append(requirements, *requirement.DeepCopy())
+	}
+	return requirements
+}
+
+// Get the requirement matching the labels not matching a requirement.
+func negateLabelSelectorRequirement(requirement metav1.LabelSelectorRequirement) metav1.LabelSelectorRequirement {
+	negated := *requirement.DeepCopy()
+	switch requirement.Operator {
+	case metav1.LabelSelectorOpIn:
+		negated.Operator = metav1.LabelSelectorOpNotIn
+	case metav1.LabelSelectorOpNotIn:
+		negated.Operator = metav1.LabelSelectorOpIn
+	case metav1.LabelSelectorOpExists:
+		negated.Operator = metav1.LabelSelectorOpDoesNotExist
+	case metav1.LabelSelectorOpDoesNotExist:
+		negated.Operator = metav1.LabelSelectorOpExists
+	}
+	return negated
+}
+
+// Validate the filters.
+// Returns: the problems found, each prefixed with the index of the filter.
+func (r ResourceFilters) Validate(namespaces []string) []string {
+	problems := []string{}
+	known := map[string]bool{}
+	for _, ns := range namespaces {
+		known[ns] = true
+	}
+	for i := range r {
+		filter := &r[i]
+		err := filter.Validate()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("[%d] %s", i, err.Error()))
+		}
+		for _, ns := range filter.Namespaces {
+			if !known[ns] {
+				problems = append(problems, fmt.Sprintf("[%d] namespaces: %s not migrated by the plan", i, ns))
+			}
+		}
+	}
+	return problems
+}
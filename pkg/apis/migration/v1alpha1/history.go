@@ -0,0 +1,152 @@
+package v1alpha1
+
+import (
+	"time"
+
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// VolumeTransferHistory history of the direct volume migration transfers of a plan.
+type VolumeTransferHistory struct {
+	// PVCs last successful transfer of the PVCs.
+	PVCs []PVCTransferRecord `json:"pvcs,omitempty"`
+
+	// Estimated duration of the volume transfers of the final migration.
+	EstimatedCutoverDuration *metav1.Duration `json:"estimatedCutoverDuration,omitempty"`
+}
+
+// PVCTransferRecord last successful transfer of a PVC.
+type PVCTransferRecord struct {
+	// Source PVC.
+	PVCReference *kapi.ObjectReference `json:"pvcRef,omitempty"`
+
+	// Namespace of the PVC in the target cluster.
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+
+	// Name of the PVC in the target cluster.
+	TargetName string `json:"targetName,omitempty"`
+
+	// UID of the PVC in the target cluster.
+	TargetUID types.UID `json:"targetUID,omitempty"`
+
+	// Name of the migration of the last transfer.
+	Migration string `json:"migration,omitempty"`
+
+	// Number of successful transfers.
+	Syncs int `json:"syncs,omitempty"`
+
+	// Manifest of the source volume computed before the last transfer.
+	Manifest string `json:"manifest,omitempty"`
+
+	// Statistics of the last transfer.
+	LastSync *RsyncTransferStats `json:"lastSync,omitempty"`
+}
+
+// FindPVC returns the record of a source PVC, nil when not found.
+func (r *VolumeTransferHistory) FindPVC(namespace, name string) *PVCTransferRecord {
+	for i := range r.PVCs {
+		record := &r.PVCs[i]
+		if record.PVCReference != nil &&
+			record.PVCReference.Namespace == namespace &&
+			record.PVCReference.Name == name {
+			return record
+		}
+	}
+	return nil
+}
+
+// Record the successful transfer of a PVC to the target PVC with the given UID by a migration.
+// Skipped transfers and transfers already recorded are ignored.
+// Returns whether the history changed.
+func (r *VolumeTransferHistory) Record(migration string, pvc PVCToMigrate, targetUID types.UID, operation *RsyncOperation) bool {
+	if pvc.ObjectReference == nil || targetUID == "" || operation == nil ||
+		!operation.Succeeded || operation.Skipped || operation.Stats == nil {
+		return false
+	}
+	record := r.FindPVC(pvc.Namespace, pvc.Name)
+	if record == nil {
+		r.PVCs = append(r.PVCs, PVCTransferRecord{
+			PVCReference: &kapi.ObjectReference{
+				Namespace: pvc.Namespace,
+				Name:      pvc.Name,
+			},
+		})
+		record = &r.PVCs[len(r.PVCs)-1]
+	}
+	if record.Migration == migration {
+		return false
+	}
+	record.TargetNamespace = pvc.TargetNamespace
+	record.TargetName = pvc.TargetName
+	record.TargetUID = targetUID
+	record.Migration = migration
+	record.Syncs++
+	record.Manifest = operation.Manifest
+	record.LastSync = operation.Stats.DeepCopy()
+	return true
+}
+
+// GetLastSyncedManifest returns the manifest of the last transfer of a PVC to the same target PVC.
+// Empty when the PVC was not transferred or was transferred to another target PVC,
+// including a target PVC deleted and created again since the last transfer.
+func (r *VolumeTransferHistory) GetLastSyncedManifest(pvc PVCToMigrate, targetUID types.UID) string {
+	if r == nil || pvc.ObjectReference == nil || targetUID == "" {
+		return ""
+	}
+	record := r.FindPVC(pvc.Namespace, pvc.Name)
+	if record == nil ||
+		record.TargetNamespace != pvc.TargetNamespace ||
+		record.TargetName != pvc.TargetName ||
+		record.TargetUID != targetUID {
+		return ""
+	}
+	return record.Manifest
+}
+
+// ResetTargetNamespaces deletes the records of the PVCs transferred to the target namespaces.
+// Returns whether the history changed.
+func (r *VolumeTransferHistory) ResetTargetNamespaces(namespaces []string) bool {
+	if r == nil {
+		return false
+	}
+	reset := map[string]bool{}
+	for _, ns := range namespaces {
+		reset[ns] = true
+	}
+	kept := []PVCTransferRecord{}
+	for _, record := range r.PVCs {
+		if !reset[record.TargetNamespace] {
+			kept = append(kept, record)
+		}
+	}
+	if len(kept) == len(r.PVCs) {
+		return false
+	}
+	r.PVCs = kept
+	return true
+}
+
+// EstimateCutoverDuration estimates the duration of the volume transfers of the final migration
+// from the last transfers of the PVCs. The last transfer of a PVC transferred more than once
+// only copied the delta since the previous transfer, the PVCs transferred once count their full
+// transfer. The transfers run concurrently, up to the maximum number of transfers when limited.
+func (r *VolumeTransferHistory) EstimateCutoverDuration(maxTransfers int) time.Duration {
+	longest := time.Duration(0)
+	total := time.Duration(0)
+	for _, record := range r.PVCs {
+		if record.LastSync == nil || record.LastSync.Duration == nil {
+			continue
+		}
+		duration := record.LastSync.Duration.Duration
+		total += duration
+		if duration > longest {
+			longest = duration
+		}
+	}
+	if maxTransfers > 0 && total/time.Duration(maxTransfers) > longest {
+		return total / time.Duration(maxTransfers)
+	}
+	return longest
+}
@@ -0,0 +1,138 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTransferredPVC(namespace, name string) PVCToMigrate {
+	return PVCToMigrate{
+		ObjectReference: &kapi.ObjectReference{
+			Namespace: namespace,
+			Name:      name,
+		},
+		TargetNamespace: namespace,
+		TargetName:      name,
+	}
+}
+
+func newTransferOperation(manifest string, duration time.Duration) *RsyncOperation {
+	return &RsyncOperation{
+		Succeeded: true,
+		Manifest:  manifest,
+		Stats: &RsyncTransferStats{
+			Duration: &metav1.Duration{Duration: duration},
+			Files:    10,
+			Bytes:    1000,
+		},
+	}
+}
+
+func TestVolumeTransferHistory_Record(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	history := &VolumeTransferHistory{}
+	pvc := newTransferredPVC("ns-1", "pvc-1")
+
+	// Skipped, failed and transfers without statistics are not recorded
+	skipped := newTransferOperation("a", time.Minute)
+	skipped.Skipped = true
+	g.Expect(history.Record("stage-1", pvc, "uid-1", skipped)).To(gomega.BeFalse())
+	failed := newTransferOperation("a", time.Minute)
+	failed.Succeeded = false
+	g.Expect(history.Record("stage-1", pvc, "uid-1", failed)).To(gomega.BeFalse())
+	g.Expect(history.Record("stage-1", pvc, "uid-1", &RsyncOperation{Succeeded: true})).To(gomega.BeFalse())
+	// Transfers to a target PVC not found are not recorded
+	g.Expect(history.Record("stage-1", pvc, "", newTransferOperation("a", time.Minute))).To(gomega.BeFalse())
+	g.Expect(history.PVCs).To(gomega.BeEmpty())
+
+	// First transfer
+	g.Expect(history.Record("stage-1", pvc, "uid-1", newTransferOperation("a", time.Minute))).To(gomega.BeTrue())
+	record := history.FindPVC("ns-1", "pvc-1")
+	g.Expect(record).NotTo(gomega.BeNil())
+	g.Expect(record.Syncs).To(gomega.Equal(1))
+	g.Expect(record.Manifest).To(gomega.Equal("a"))
+	g.Expect(record.Migration).To(gomega.Equal("stage-1"))
+
+	// Same migration recorded once
+	g.Expect(history.Record("stage-1", pvc, "uid-1", newTransferOperation("a", time.Minute))).To(gomega.BeFalse())
+	g.Expect(history.FindPVC("ns-1", "pvc-1").Syncs).To(gomega.Equal(1))
+
+	// Next transfer
+	g.Expect(history.Record("stage-2", pvc, "uid-1", newTransferOperation("b", time.Second))).To(gomega.BeTrue())
+	record = history.FindPVC("ns-1", "pvc-1")
+	g.Expect(history.PVCs).To(gomega.HaveLen(1))
+	g.Expect(record.Syncs).To(gomega.Equal(2))
+	g.Expect(record.Manifest).To(gomega.Equal("b"))
+	g.Expect(record.LastSync.Duration.Duration).To(gomega.Equal(time.Second))
+}
+
+func TestVolumeTransferHistory_GetLastSyncedManifest(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	pvc := newTransferredPVC("ns-1", "pvc-1")
+	targetUID := types.UID("uid-1")
+
+	// No history
+	var history *VolumeTransferHistory
+	g.Expect(history.GetLastSyncedManifest(pvc, targetUID)).To(gomega.BeEmpty())
+
+	// Not transferred
+	history = &VolumeTransferHistory{}
+	g.Expect(history.GetLastSyncedManifest(pvc, targetUID)).To(gomega.BeEmpty())
+
+	// Transferred to the same target PVC
+	history.Record("stage-1", pvc, targetUID, newTransferOperation("a", time.Minute))
+	g.Expect(history.GetLastSyncedManifest(pvc, targetUID)).To(gomega.Equal("a"))
+
+	// Target PVC deleted
+	g.Expect(history.GetLastSyncedManifest(pvc, "")).To(gomega.BeEmpty())
+
+	// Target PVC deleted and created again
+	g.Expect(history.GetLastSyncedManifest(pvc, "uid-2")).To(gomega.BeEmpty())
+
+	// Transferred to another target PVC
+	pvc.TargetName = "pvc-2"
+	g.Expect(history.GetLastSyncedManifest(pvc, targetUID)).To(gomega.BeEmpty())
+}
+
+func TestVolumeTransferHistory_ResetTargetNamespaces(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	var history *VolumeTransferHistory
+	g.Expect(history.ResetTargetNamespaces([]string{"ns-1"})).To(gomega.BeFalse())
+
+	history = &VolumeTransferHistory{}
+	pvc := newTransferredPVC("ns-1", "pvc-1")
+	history.Record("stage-1", pvc, "uid-1", newTransferOperation("a", time.Minute))
+	history.Record("stage-1", newTransferredPVC("ns-2", "pvc-1"), "uid-2", newTransferOperation("b", time.Minute))
+
+	g.Expect(history.ResetTargetNamespaces([]string{"ns-3"})).To(gomega.BeFalse())
+	g.Expect(history.PVCs).To(gomega.HaveLen(2))
+
+	g.Expect(history.ResetTargetNamespaces([]string{"ns-1"})).To(gomega.BeTrue())
+	g.Expect(history.PVCs).To(gomega.HaveLen(1))
+	g.Expect(history.FindPVC("ns-1", "pvc-1")).To(gomega.BeNil())
+	g.Expect(history.FindPVC("ns-2", "pvc-1")).NotTo(gomega.BeNil())
+	g.Expect(history.GetLastSyncedManifest(pvc, "uid-1")).To(gomega.BeEmpty())
+}
+
+func TestVolumeTransferHistory_EstimateCutoverDuration(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	history := &VolumeTransferHistory{}
+	g.Expect(history.EstimateCutoverDuration(0)).To(gomega.Equal(time.Duration(0)))
+
+	history.Record("stage-1", newTransferredPVC("ns-1", "pvc-1"), "uid-1", newTransferOperation("a", 3*time.Minute))
+	history.Record("stage-1", newTransferredPVC("ns-1", "pvc-2"), "uid-2", newTransferOperation("b", 2*time.Minute))
+	history.Record("stage-1", newTransferredPVC("ns-1", "pvc-3"), "uid-3", newTransferOperation("c", 2*time.Minute))
+
+	// Unlimited transfers run concurrently
+	g.Expect(history.EstimateCutoverDuration(0)).To(gomega.Equal(3 * time.Minute))
+	g.Expect(history.EstimateCutoverDuration(3)).To(gomega.Equal(3 * time.Minute))
+	// Limited transfers queue
+	g.Expect(history.EstimateCutoverDuration(1)).To(gomega.Equal(7 * time.Minute))
+	g.Expect(history.EstimateCutoverDuration(2)).To(gomega.Equal(3*time.Minute + 30*time.Second))
+}
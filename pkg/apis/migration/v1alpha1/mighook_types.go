@@ -18,7 +18,9 @@ package v1alpha1
 
 import (
 	"context"
+	"time"
 
+	authv1 "k8s.io/api/authorization/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,12 +28,36 @@ import (
 )
 
 const (
-	HookPhaseLabel       = "phase"
-	HookOwnerLabel       = "owner"
-	PreBackupHookPhase   = "PreBackup"
-	PostBackupHookPhase  = "PostBackup"
-	PreRestoreHookPhase  = "PreRestore"
-	PostRestoreHookPhase = "PostRestore"
+	HookPhaseLabel            = "phase"
+	HookOwnerLabel            = "owner"
+	HookIndexLabel            = "hook-index"
+	PreQuiesceHookPhase       = "PreQuiesce"
+	PreBackupHookPhase        = "PreBackup"
+	PostBackupHookPhase       = "PostBackup"
+	PostDirectVolumeHookPhase = "PostDirectVolume"
+	PreRestoreHookPhase       = "PreRestore"
+	PostRestoreHookPhase      = "PostRestore"
+	PreRollbackHookPhase      = "PreRollback"
+	PostRollbackHookPhase     = "PostRollback"
+)
+
+// Migration context set in the environment of the hook container.
+// MIGRATION_NAMESPACES - The source namespaces, comma separated.
+// MIGRATION_NAMESPACE_MAPPING - The source:destination namespaces, comma separated.
+// MIGRATION_PVCS - The namespace/name of the migrated PVCs, comma separated.
+const (
+	HookEnvPrefix             = "MIGRATION_"
+	HookEnvNamespaces         = "MIGRATION_NAMESPACES"
+	HookEnvPlanName           = "MIGRATION_PLAN_NAME"
+	HookEnvMigrationName      = "MIGRATION_NAME"
+	HookEnvMigrationUID       = "MIGRATION_UID"
+	HookEnvItinerary          = "MIGRATION_ITINERARY"
+	HookEnvType               = "MIGRATION_TYPE"
+	HookEnvPhase              = "MIGRATION_HOOK_PHASE"
+	HookEnvSourceCluster      = "MIGRATION_SOURCE_CLUSTER"
+	HookEnvDestinationCluster = "MIGRATION_DESTINATION_CLUSTER"
+	HookEnvNamespaceMapping   = "MIGRATION_NAMESPACE_MAPPING"
+	HookEnvPVCs               = "MIGRATION_PVCS"
 )
 
 // MigHookSpec defines the desired state of MigHook
@@ -39,8 +65,8 @@ type MigHookSpec struct {
 	// Specifies whether the hook is a custom Ansible playbook or a pre-built image. This is a required field.
 	Custom bool `json:"custom"`
 
-	// Specifies the image of the hook to be executed. This is a required field.
-	Image string `json:"image"`
+	// Specifies the image of the hook to be executed. This is a required field unless exec is specified.
+	Image string `json:"image,omitempty"`
 
 	// Specifies the contents of the custom Ansible playbook in base64 format, it is used in conjunction with the custom boolean flag.
 	Playbook string `json:"playbook,omitempty"`
@@ -50,12 +76,78 @@ type MigHookSpec struct {
 
 	// Specifies the highest amount of time for which the hook will run.
 	ActiveDeadlineSeconds int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Specifies a command executed inside the containers of running application pods instead of running the hook image in a Job.
+	Exec *MigHookExec `json:"exec,omitempty"`
+
+	// Specifies environment variables set in the hook container in addition to the migration context set by the controller. Values may reference ConfigMaps and Secrets in the execution namespace.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Specifies ConfigMaps and Secrets in the execution namespace exposed as environment variables in the hook container.
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+
+	// Specifies the compute resources of the hook container.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Specifies the node selector of the hook pod.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Specifies the tolerations of the hook pod.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Specifies an optional validation of the hook on a cluster, the hook is not ready until the validation succeeds.
+	Preflight *MigHookPreflight `json:"preflight,omitempty"`
+}
+
+// MigHookPreflight defines a validation of the hook on a cluster before it is
+// referenced by a plan: the service account must exist and be granted the
+// permissions, the image must be pullable and the playbook must pass the
+// ansible-playbook syntax check.
+type MigHookPreflight struct {
+	// Specifies the MigCluster on which the hook is validated. This is a required field.
+	ClusterRef *corev1.ObjectReference `json:"clusterRef"`
+
+	// Specifies the namespace in which the hook is executed. This is a required field.
+	Namespace string `json:"namespace"`
+
+	// Specifies the service account executing the hook. This is a required field.
+	ServiceAccount string `json:"serviceAccount"`
+
+	// Specifies the permissions needed by the service account.
+	Permissions []authv1.ResourceAttributes `json:"permissions,omitempty"`
+}
+
+// MigHookExec defines a command executed inside running application pods.
+// The pods are selected in the execution namespace of the plan hook.
+type MigHookExec struct {
+	// Selects the pods in which the command is executed. This is a required field.
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// Specifies the container in which the command is executed, defaults to the first container of the pod.
+	Container string `json:"container,omitempty"`
+
+	// Specifies the command (and args) to execute. This is a required field.
+	Command []string `json:"command"`
+
+	// Specifies the highest amount of time to wait for the command in each pod, defaults to 60.
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// Get the time to wait for the command in each pod.
+func (r *MigHookExec) GetTimeout() time.Duration {
+	if r.TimeoutSeconds > 0 {
+		return time.Duration(r.TimeoutSeconds) * time.Second
+	}
+	return 60 * time.Second
 }
 
 // MigHookStatus defines the observed state of MigHook
 type MigHookStatus struct {
 	Conditions         `json:","`
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The generation validated by the pre-flight validation.
+	PreflightGeneration int64 `json:"preflightGeneration,omitempty"`
 }
 
 // +genclient
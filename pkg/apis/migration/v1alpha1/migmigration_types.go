@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	kapi "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -40,6 +41,12 @@ type MigMigrationSpec struct {
 	// Specifies whether to quiesce the application Pods before migrating Persistent Volume data.
 	QuiescePods bool `json:"quiescePods,omitempty"`
 
+	// Specifies which application workloads are quiesced and in which order. Overrides the quiesce policy of the plan.
+	QuiescePolicy *QuiescePolicy `json:"quiescePolicy,omitempty"`
+
+	// Rules selecting the namespaced resources migrated. Overrides the resource filters of the plan.
+	ResourceFilters ResourceFilters `json:"resourceFilters,omitempty"`
+
 	// Specifies whether to retain the annotations set by the migration controller or not.
 	KeepAnnotations bool `json:"keepAnnotations,omitempty"`
 
@@ -49,9 +56,21 @@ type MigMigrationSpec struct {
 	// Invokes the cancel migration operation, when set to true the migration controller switches to cancel itinerary. This field can be used on-demand to cancel the running migration.
 	Canceled bool `json:"canceled,omitempty"`
 
+	// Pauses the running migration at the next phase boundary, when set back to false the migration resumes from the recorded phase. This field can be used on-demand on a running migration.
+	Paused bool `json:"paused,omitempty"`
+
 	// Invokes the rollback migration operation, when set to true the migration controller switches to rollback itinerary. This field needs to be set prior to creation of a MigMigration.
 	Rollback bool `json:"rollback,omitempty"`
 
+	// Limits the rollback to the selected namespaces and migrated resources. Everything the plan migrated is rolled back when not set. A scoped rollback keeps the Velero Backups and Restores and the migration annotations of the plan.
+	RollbackScope *RollbackScope `json:"rollbackScope,omitempty"`
+
+	// References a failed final migration of the same plan. When set, the migration runs the Prepare step, skips to the step that failed and reuses the Velero Backups and Stage Pods of the referenced migration when they are still valid. This field needs to be set prior to creation of a MigMigration.
+	RetryMigrationRef *kapi.ObjectReference `json:"retryMigrationRef,omitempty"`
+
+	// Walks the itinerary and reports what the migration would quiesce, annotate, back up, restore, swap or delete without changing the clusters. The report is written to a ConfigMap named in the DryRun condition, the migration completes with the DryRun condition instead of Succeeded. This field needs to be set prior to creation of a MigMigration.
+	DryRun bool `json:"dryRun,omitempty"`
+
 	// If set True, run rsync operations with escalated privileged, takes precedence over setting RunAsUser and RunAsGroup
 	RunAsRoot *bool `json:"runAsRoot,omitempty"`
 
@@ -62,16 +81,109 @@ type MigMigrationSpec struct {
 	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
 }
 
+// RollbackScope selects the part of a migration that is rolled back.
+type RollbackScope struct {
+	// Source namespaces rolled back. The source workloads are unquiesced in these namespaces only. All the namespaces of the plan when not set.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Only the migrated resources matching the selector are deleted on the destination cluster.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// Only the migrated resources of these kinds are deleted on the destination cluster. The source workloads of these kinds only are unquiesced.
+	IncludedResources []*metav1.GroupKind `json:"includedResources,omitempty"`
+}
+
+// Includes returns whether a migrated resource kind is rolled back.
+func (r *RollbackScope) Includes(kind schema.GroupKind) bool {
+	if r == nil || len(r.IncludedResources) == 0 {
+		return true
+	}
+	for _, included := range r.IncludedResources {
+		if included != nil && included.Group == kind.Group && included.Kind == kind.Kind {
+			return true
+		}
+	}
+	return false
+}
+
+// IncludesNamespace returns whether a source namespace is rolled back.
+func (r *RollbackScope) IncludesNamespace(namespace string) bool {
+	if r == nil || len(r.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range r.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 // MigMigrationStatus defines the observed state of MigMigration
 type MigMigrationStatus struct {
-	Conditions         `json:",inline"`
-	UnhealthyResources `json:",inline"`
-	ObservedDigest     string       `json:"observedDigest,omitempty"`
-	StartTimestamp     *metav1.Time `json:"startTimestamp,omitempty"`
-	Phase              string       `json:"phase,omitempty"`
-	Pipeline           []*Step      `json:"pipeline,omitempty"`
-	Itinerary          string       `json:"itinerary,omitempty"`
-	Errors             []string     `json:"errors,omitempty"`
+	Conditions          `json:",inline"`
+	UnhealthyResources  `json:",inline"`
+	ObservedDigest      string           `json:"observedDigest,omitempty"`
+	StartTimestamp      *metav1.Time     `json:"startTimestamp,omitempty"`
+	Phase               string           `json:"phase,omitempty"`
+	PhaseStartTimestamp *metav1.Time     `json:"phaseStartTimestamp,omitempty"`
+	Pipeline            []*Step          `json:"pipeline,omitempty"`
+	Itinerary           string           `json:"itinerary,omitempty"`
+	Errors              []string         `json:"errors,omitempty"`
+	HookExecResults     []HookExecResult `json:"hookExecResults,omitempty"`
+}
+
+// HookExecResult is the result of an exec hook command in a pod.
+type HookExecResult struct {
+	// The phase of the hook.
+	Phase string `json:"phase"`
+	// The position of the hook in its phase.
+	Index int `json:"index"`
+	// The namespace/name of the MigHook.
+	Hook string `json:"hook"`
+	// The namespace/name of the pod.
+	Pod string `json:"pod,omitempty"`
+	// The container in which the command was executed.
+	Container string `json:"container,omitempty"`
+	// The attempt, starting at 1.
+	Attempt int `json:"attempt"`
+	// The exit code of the command.
+	ExitCode int `json:"exitCode"`
+	// The (truncated) standard output of the command.
+	Stdout string `json:"stdout,omitempty"`
+	// The (truncated) standard error of the command.
+	Stderr string `json:"stderr,omitempty"`
+	// Why the command could not be executed or did not complete.
+	Error string `json:"error,omitempty"`
+	// The command is running.
+	Running bool `json:"running,omitempty"`
+}
+
+// Succeeded returns whether the command completed with a zero exit code.
+func (r *HookExecResult) Succeeded() bool {
+	return !r.Running && r.Error == "" && r.ExitCode == 0
+}
+
+// FindHookExecResults returns the results of the exec hook at the index of the phase.
+func (s *MigMigrationStatus) FindHookExecResults(phase string, index int) []HookExecResult {
+	results := []HookExecResult{}
+	for _, result := range s.HookExecResults {
+		if result.Phase == phase && result.Index == index {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// SetHookExecResults replaces the results of the exec hook at the index of the phase.
+func (s *MigMigrationStatus) SetHookExecResults(phase string, index int, results []HookExecResult) {
+	kept := []HookExecResult{}
+	for _, result := range s.HookExecResults {
+		if result.Phase != phase || result.Index != index {
+			kept = append(kept, result)
+		}
+	}
+	s.HookExecResults = append(kept, results...)
 }
 
 // FindStep find step by name
@@ -181,6 +293,17 @@ func (r *MigMigration) HasErrors() bool {
 	return len(r.Status.Errors) > 0
 }
 
+// Keys of the report in the migration report ConfigMap.
+const (
+	MigrationReportJSONKey     = "report.json"
+	MigrationReportMarkdownKey = "report.md"
+)
+
+// GetReportConfigMapName returns the name of the ConfigMap holding the report of the completed migration
+func (r *MigMigration) GetReportConfigMapName() string {
+	return r.Name + "-report"
+}
+
 // IsStateMigration checks whether state migration annotation is present
 func (r *MigMigration) IsStateMigration() bool {
 	if _, exists := r.Annotations[StateMigrationAnnotation]; exists {
@@ -26,6 +26,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	pvdr "github.com/konveyor/mig-controller/pkg/cloudprovider"
 	migref "github.com/konveyor/mig-controller/pkg/reference"
@@ -67,7 +68,7 @@ const (
 type MigPlanHook struct {
 	Reference *kapi.ObjectReference `json:"reference"`
 
-	// Indicates the phase when the hooks will be executed. Acceptable values are: PreBackup, PostBackup, PreRestore, and PostRestore.
+	// Indicates the phase when the hooks will be executed. Acceptable values are: PreQuiesce, PreBackup, PostBackup, PostDirectVolume, PreRestore, PostRestore, PreRollback, and PostRollback. The hooks of a phase run one at a time in the listed order.
 	Phase string `json:"phase"`
 
 	// Holds the name of the namespace where hooks should be implemented.
@@ -75,6 +76,40 @@ type MigPlanHook struct {
 
 	// Holds the name of the service account to be used for running hooks.
 	ServiceAccount string `json:"serviceAccount"`
+
+	// Specifies what happens when the hook fails. Acceptable values are: Fail (default) to fail the migration, Ignore to run the next hook and Retry to run the hook again up to `retries` times before failing the migration.
+	// +kubebuilder:validation:Enum=Fail;Ignore;Retry
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+
+	// Specifies how many times a failed hook is run again with the Retry failure policy.
+	// +kubebuilder:validation:Minimum=0
+	Retries int32 `json:"retries,omitempty"`
+}
+
+// Hook failure policies.
+const (
+	// Fail the migration.
+	HookFail = "Fail"
+	// Run the next hook of the phase.
+	HookIgnore = "Ignore"
+	// Run the hook again, then fail the migration.
+	HookRetry = "Retry"
+)
+
+// GetFailurePolicy returns the failure policy, defaults to Fail.
+func (r *MigPlanHook) GetFailurePolicy() string {
+	if r.FailurePolicy == "" {
+		return HookFail
+	}
+	return r.FailurePolicy
+}
+
+// GetRetries returns how many times a failed hook is run again.
+func (r *MigPlanHook) GetRetries() int {
+	if r.GetFailurePolicy() != HookRetry {
+		return 0
+	}
+	return int(r.Retries)
 }
 
 // MigPlanSpec defines the desired state of MigPlan
@@ -115,6 +150,64 @@ type MigPlanSpec struct {
 	// LabelSelector optional label selector on the included resources in Velero Backup
 	// +kubebuilder:validation:Optional
 	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// PhaseTimeouts optional deadlines of migration phases keyed by phase name
+	// Overrides the controller defaults, a zero duration disables the deadline of a phase
+	// +kubebuilder:validation:Optional
+	PhaseTimeouts map[string]metav1.Duration `json:"phaseTimeouts,omitempty"`
+
+	// QuiescePolicy optional selection and ordering of the workloads quiesced by migrations
+	// When not set, all the workloads are quiesced at once
+	// +kubebuilder:validation:Optional
+	QuiescePolicy *QuiescePolicy `json:"quiescePolicy,omitempty"`
+
+	// ResourceFilters optional rules selecting the namespaced resources migrated
+	// Applied to the Velero Backups and Restores and to the PVCs of direct volume migrations
+	// +kubebuilder:validation:Optional
+	ResourceFilters ResourceFilters `json:"resourceFilters,omitempty"`
+
+	// Transforms optional patches applied to the restored resources on the destination cluster
+	// +kubebuilder:validation:Optional
+	Transforms []RestoreTransform `json:"transforms,omitempty"`
+
+	// ExistingResourcePolicy optional handling of the resources already existing in the destination namespaces
+	// Acceptable values are: Skip (default) to keep the existing resources, Update to overwrite them with the
+	// migrated resources and Fail to fail final migrations before the source applications are quiesced
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Skip;Update;Fail
+	ExistingResourcePolicy string `json:"existingResourcePolicy,omitempty"`
+
+	// RsyncOptions optional rsync options of the direct volume migrations
+	// Override the controller-wide defaults, may be overridden per PV in the persistentVolumes selection
+	// +kubebuilder:validation:Optional
+	RsyncOptions *RsyncOptions `json:"rsyncOptions,omitempty"`
+
+	// RsyncTransferLimits optional maximum numbers of concurrent rsync transfers of the direct volume migrations on the source cluster
+	// +kubebuilder:validation:Optional
+	RsyncTransferLimits *RsyncTransferLimits `json:"rsyncTransferLimits,omitempty"`
+
+	// If set True, direct volume migrations record the transfer history of the PVCs
+	// and final migrations skip the PVCs not changed since their last transfer
+	// +kubebuilder:validation:Optional
+	IncrementalVolumeMigration bool `json:"incrementalVolumeMigration,omitempty"`
+}
+
+// Existing resource policies.
+const (
+	// Keep the resources existing on the destination cluster.
+	ExistingResourceSkip = "Skip"
+	// Overwrite the resources existing on the destination cluster.
+	ExistingResourceUpdate = "Update"
+	// Fail the migration when resources exist on the destination cluster.
+	ExistingResourceFail = "Fail"
+)
+
+// GetExistingResourcePolicy returns the existing resource policy, defaults to Skip.
+func (r *MigPlanSpec) GetExistingResourcePolicy() string {
+	if r.ExistingResourcePolicy == "" {
+		return ExistingResourceSkip
+	}
+	return r.ExistingResourcePolicy
 }
 
 // MigPlanStatus defines the observed state of MigPlan
@@ -126,6 +219,8 @@ type MigPlanStatus struct {
 	ExcludedResources  []string       `json:"excludedResources,omitempty"`
 	SrcStorageClasses  []StorageClass `json:"srcStorageClasses,omitempty"`
 	DestStorageClasses []StorageClass `json:"destStorageClasses,omitempty"`
+	// VolumeTransferHistory history of the direct volume migration transfers of incremental volume migrations
+	VolumeTransferHistory *VolumeTransferHistory `json:"volumeTransferHistory,omitempty"`
 }
 
 // +genclient
@@ -795,6 +890,15 @@ func (r *MigPlan) IsVolumeMigrationDisabled() bool {
 	return r.IsResourceExcluded(settings.PVResource)
 }
 
+// GetPhaseTimeout returns the deadline of a migration phase
+// The plan spec overrides the controller wide settings, returns 0 when the phase has no deadline
+func (r *MigPlan) GetPhaseTimeout(phase string) time.Duration {
+	if timeout, found := r.Spec.PhaseTimeouts[phase]; found {
+		return timeout.Duration
+	}
+	return Settings.GetPhaseTimeout(phase)
+}
+
 // GetIncludedResourcesList returns list of string representation of Resource Group/Kinds provided
 // through spec.IncludedResources of the plan, returns list of invalid resources and aggregated
 // errors if one or more resources are invalid in the provided list
@@ -896,12 +1000,14 @@ type Supported struct {
 // AccessMode   - The PV access mode to use in the destination cluster, if different from src PVC AccessMode
 // CopyMethod   - The PV copy method to use ('filesystem' for restic copy, or 'snapshot' for velero snapshot plugin)
 // Verify       - Whether or not to verify copied volume data if CopyMethod is 'filesystem'
+// RsyncOptions - The rsync options of the direct volume migration, overriding the plan options
 type Selection struct {
 	Action       string                          `json:"action,omitempty"`
 	StorageClass string                          `json:"storageClass,omitempty"`
 	AccessMode   kapi.PersistentVolumeAccessMode `json:"accessMode,omitempty" protobuf:"bytes,1,rep,name=accessMode,casttype=PersistentVolumeAccessMode"`
 	CopyMethod   string                          `json:"copyMethod,omitempty"`
 	Verify       bool                            `json:"verify,omitempty"`
+	RsyncOptions *RsyncOptions                   `json:"rsyncOptions,omitempty"`
 }
 
 // Update the PV with another.
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Concurrency policies.
+const (
+	// Skip the scheduled migration while another migration of the plan is running.
+	ForbidConcurrent = "Forbid"
+	// Create the scheduled migration and let it wait for the running migrations of the plan.
+	AllowConcurrent = "Allow"
+)
+
+// History limits used when not set on the schedule.
+const (
+	DefaultSucceededHistoryLimit = 3
+	DefaultFailedHistoryLimit    = 1
+)
+
+// MigScheduleSpec defines the desired state of MigSchedule
+type MigScheduleSpec struct {
+	MigPlanRef *kapi.ObjectReference `json:"migPlanRef"`
+
+	// Specifies when stage migrations are created, in standard five field cron format: <minute> <hour> <day of month> <month> <day of week>. This is a required field.
+	Schedule string `json:"schedule"`
+
+	// Specifies how to treat a scheduled run while another migration of the plan is running. Acceptable values are: Forbid (default) to skip the run and Allow to create the migration anyway.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Forbid;Allow
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+
+	// Suspends the creation of scheduled migrations, already created migrations are not affected.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Specifies whether to quiesce the application Pods of the scheduled migrations.
+	QuiescePods bool `json:"quiescePods,omitempty"`
+
+	// Specifies the number of successful scheduled migrations to keep. Defaults to 3.
+	// +kubebuilder:validation:Minimum=0
+	SucceededHistoryLimit *int32 `json:"succeededHistoryLimit,omitempty"`
+
+	// Specifies the number of failed scheduled migrations to keep. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	FailedHistoryLimit *int32 `json:"failedHistoryLimit,omitempty"`
+}
+
+// MigScheduleStatus defines the observed state of MigSchedule
+type MigScheduleStatus struct {
+	Conditions         `json:",inline"`
+	ObservedGeneration int64                 `json:"observedGeneration,omitempty"`
+	LastScheduleTime   *metav1.Time          `json:"lastScheduleTime,omitempty"`
+	NextScheduleTime   *metav1.Time          `json:"nextScheduleTime,omitempty"`
+	LastMigrationRef   *kapi.ObjectReference `json:"lastMigrationRef,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MigSchedule is the Schema for the migschedules API
+// +k8s:openapi-gen=true
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Plan",type=string,JSONPath=".spec.migPlanRef.name"
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="Suspend",type=string,JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="Last",type="date",JSONPath=".status.lastScheduleTime"
+// +kubebuilder:printcolumn:name="Next",type="date",JSONPath=".status.nextScheduleTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type MigSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MigScheduleSpec   `json:"spec,omitempty"`
+	Status MigScheduleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MigScheduleList contains a list of MigSchedule
+type MigScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MigSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MigSchedule{}, &MigScheduleList{})
+}
+
+// GetPlan - Get the migration plan.
+// Returns `nil` when the reference cannot be resolved.
+func (r *MigSchedule) GetPlan(client k8sclient.Client) (*MigPlan, error) {
+	return GetPlan(client, r.Spec.MigPlanRef)
+}
+
+// GetConcurrencyPolicy returns the concurrency policy, defaults to Forbid.
+func (r *MigSchedule) GetConcurrencyPolicy() string {
+	if r.Spec.ConcurrencyPolicy == "" {
+		return ForbidConcurrent
+	}
+	return r.Spec.ConcurrencyPolicy
+}
+
+// GetSucceededHistoryLimit returns the number of successful migrations to keep.
+func (r *MigSchedule) GetSucceededHistoryLimit() int {
+	if r.Spec.SucceededHistoryLimit == nil {
+		return DefaultSucceededHistoryLimit
+	}
+	return int(*r.Spec.SucceededHistoryLimit)
+}
+
+// GetFailedHistoryLimit returns the number of failed migrations to keep.
+func (r *MigSchedule) GetFailedHistoryLimit() int {
+	if r.Spec.FailedHistoryLimit == nil {
+		return DefaultFailedHistoryLimit
+	}
+	return int(*r.Spec.FailedHistoryLimit)
+}
+
+// ListMigrations lists the migrations created by the schedule.
+func (r *MigSchedule) ListMigrations(client k8sclient.Client) ([]MigMigration, error) {
+	list := MigMigrationList{}
+	err := client.List(
+		context.TODO(),
+		&list,
+		k8sclient.InNamespace(r.Namespace),
+		k8sclient.MatchingLabels(r.GetCorrelationLabels()))
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
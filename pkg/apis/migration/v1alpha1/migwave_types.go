@@ -0,0 +1,188 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Failure policies.
+const (
+	// Start no more plans once a plan failed.
+	StopWave = "StopWave"
+	// Keep migrating the plans that do not depend on a failed plan.
+	ContinueWave = "Continue"
+	// Roll back every plan of the wave once a plan failed.
+	RollbackWave = "RollbackAll"
+)
+
+// Phases of the wave and of the plans in the wave.
+const (
+	WavePending     = "Pending"
+	WaveRunning     = "Running"
+	WaveSucceeded   = "Succeeded"
+	WaveFailed      = "Failed"
+	WaveSkipped     = "Skipped"
+	WaveRollingBack = "RollingBack"
+	WaveRolledBack  = "RolledBack"
+)
+
+// MaxParallel used when not set on the wave.
+const DefaultWaveMaxParallel = 1
+
+// Finalizer keeping a deleted wave until none of its migrations is running.
+const MigWaveFinalizer = "migration.openshift.io/migwave"
+
+// WavePlan references a plan migrated by the wave.
+type WavePlan struct {
+	MigPlanRef *kapi.ObjectReference `json:"migPlanRef"`
+
+	// Names of the plans in the wave that must be migrated successfully before this plan is started.
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// MigWaveSpec defines the desired state of MigWave
+type MigWaveSpec struct {
+	// The plans migrated by the wave. This is a required field.
+	Plans []WavePlan `json:"plans"`
+
+	// Specifies the maximum number of plans migrated at the same time. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	MaxParallel int `json:"maxParallel,omitempty"`
+
+	// Specifies what to do when the migration of a plan fails. Acceptable values are: StopWave (default), Continue and RollbackAll.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=StopWave;Continue;RollbackAll
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+
+	// Specifies whether to quiesce the application Pods of the migrated plans.
+	QuiescePods bool `json:"quiescePods,omitempty"`
+}
+
+// WavePlanStatus is the observed state of a plan in the wave.
+type WavePlanStatus struct {
+	Name           string                `json:"name"`
+	Phase          string                `json:"phase,omitempty"`
+	MigrationRef   *kapi.ObjectReference `json:"migrationRef,omitempty"`
+	RollbackRef    *kapi.ObjectReference `json:"rollbackRef,omitempty"`
+	Step           string                `json:"step,omitempty"`
+	CompletedSteps int                   `json:"completedSteps,omitempty"`
+	TotalSteps     int                   `json:"totalSteps,omitempty"`
+	Message        string                `json:"message,omitempty"`
+}
+
+// MigWaveStatus defines the observed state of MigWave
+type MigWaveStatus struct {
+	Conditions          `json:",inline"`
+	ObservedGeneration  int64            `json:"observedGeneration,omitempty"`
+	Phase               string           `json:"phase,omitempty"`
+	Plans               []WavePlanStatus `json:"plans,omitempty"`
+	CompletedSteps      int              `json:"completedSteps,omitempty"`
+	TotalSteps          int              `json:"totalSteps,omitempty"`
+	StartTimestamp      *metav1.Time     `json:"startTimestamp,omitempty"`
+	CompletionTimestamp *metav1.Time     `json:"completionTimestamp,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MigWave is the Schema for the migwaves API
+// +k8s:openapi-gen=true
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Policy",type=string,JSONPath=".spec.failurePolicy"
+// +kubebuilder:printcolumn:name="Steps",type=string,JSONPath=".status.completedSteps"
+// +kubebuilder:printcolumn:name="Total",type=string,JSONPath=".status.totalSteps"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type MigWave struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MigWaveSpec   `json:"spec,omitempty"`
+	Status MigWaveStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MigWaveList contains a list of MigWave
+type MigWaveList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MigWave `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MigWave{}, &MigWaveList{})
+}
+
+// GetMaxParallel returns the maximum number of plans migrated at the same time.
+func (r *MigWave) GetMaxParallel() int {
+	if r.Spec.MaxParallel < 1 {
+		return DefaultWaveMaxParallel
+	}
+	return r.Spec.MaxParallel
+}
+
+// GetFailurePolicy returns the failure policy, defaults to StopWave.
+func (r *MigWave) GetFailurePolicy() string {
+	if r.Spec.FailurePolicy == "" {
+		return StopWave
+	}
+	return r.Spec.FailurePolicy
+}
+
+// ListMigrations lists the migrations created by the wave.
+// The migrations are labeled with the correlation labels of the wave.
+func (r *MigWave) ListMigrations(client k8sclient.Client) ([]MigMigration, error) {
+	list := MigMigrationList{}
+	err := client.List(
+		context.TODO(),
+		&list,
+		k8sclient.InNamespace(r.Namespace),
+		k8sclient.MatchingLabels(r.GetCorrelationLabels()))
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// FindPlan finds the status of a plan in the wave by name.
+// Returns `nil` when not found.
+func (r *MigWaveStatus) FindPlan(name string) *WavePlanStatus {
+	for i := range r.Plans {
+		if r.Plans[i].Name == name {
+			return &r.Plans[i]
+		}
+	}
+	return nil
+}
+
+// HasPlanPhase returns whether any plan in the wave has one of the phases.
+func (r *MigWaveStatus) HasPlanPhase(phases ...string) bool {
+	for _, plan := range r.Plans {
+		for _, phase := range phases {
+			if plan.Phase == phase {
+				return true
+			}
+		}
+	}
+	return false
+}
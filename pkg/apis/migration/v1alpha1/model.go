@@ -143,6 +143,31 @@ func GetStorage(client k8sclient.Client, ref *kapi.ObjectReference) (*MigStorage
 	return &object, err
 }
 
+// Get a referenced MigMigration.
+// Returns `nil` when the reference cannot be resolved.
+func GetMigration(client k8sclient.Client, ref *kapi.ObjectReference) (*MigMigration, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	object := MigMigration{}
+	err := client.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Namespace: ref.Namespace,
+			Name:      ref.Name,
+		},
+		&object)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+
+	return &object, err
+}
+
 // Get a referenced Migration for DVM.
 // Return nil if the reference cannot be resolved.
 func GetMigrationForDVM(client k8sclient.Client, owners []metav1.OwnerReference) (*MigMigration, error) {
@@ -0,0 +1,245 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// QuiescePolicy defines which application workloads are quiesced and in which order.
+// Workloads not selected by the policy are left running.
+type QuiescePolicy struct {
+	// Only the workloads matching the selector are quiesced. All workloads are quiesced when not set.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// The workloads matching the selector are left running.
+	ExcludeSelector *metav1.LabelSelector `json:"excludeSelector,omitempty"`
+
+	// Workloads left running, referenced by kind, name and optionally namespace.
+	Exclude []kapi.ObjectReference `json:"exclude,omitempty"`
+
+	// Ordered shutdown. The workloads matching the selector of a stage are quiesced, and their Pods terminated, before the next stage starts. Workloads not matching any stage are quiesced last.
+	Stages []QuiesceStage `json:"stages,omitempty"`
+
+	// Settings by workload kind.
+	Kinds []QuiesceKind `json:"kinds,omitempty"`
+
+	// Scale rules for custom resources of operators owning application Pods. The workloads owned by these custom resources are left to their operator.
+	CustomResources []QuiesceCustomResource `json:"customResources,omitempty"`
+}
+
+// QuiesceStage selects the workloads quiesced together.
+type QuiesceStage struct {
+	Name     string                `json:"name"`
+	Selector *metav1.LabelSelector `json:"selector"`
+}
+
+// QuiesceKind defines how the workloads of a kind are quiesced.
+type QuiesceKind struct {
+	// The workload kind.
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;ReplicaSet;DaemonSet;CronJob;Job
+	Kind string `json:"kind"`
+
+	// Leaves the workloads of the kind running.
+	Skip bool `json:"skip,omitempty"`
+
+	// Time given to the Pods of the kind to terminate gracefully, remaining Pods are then deleted forcefully. Pods are waited for indefinitely when not set.
+	GracefulTimeout *metav1.Duration `json:"gracefulTimeout,omitempty"`
+
+	// Scales down one replica at a time so Pods terminate in reverse ordinal order. Only supported for StatefulSets.
+	Ordered bool `json:"ordered,omitempty"`
+}
+
+// QuiesceCustomResource defines how the custom resources of a kind are quiesced.
+// Either the replica field is scaled to zero or the pause field is set to the
+// pause value. The original value is restored when unquiesced.
+type QuiesceCustomResource struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+
+	// JSONPath to the replica field, such as .spec.replicas. Only field names are supported.
+	ReplicasPath string `json:"replicasPath,omitempty"`
+
+	// JSONPath to the pause field, such as .spec.paused. Only field names are supported.
+	PausePath string `json:"pausePath,omitempty"`
+
+	// JSON value set in the pause field, such as true or "Stopped".
+	PauseValue string `json:"pauseValue,omitempty"`
+}
+
+// GroupVersionKind returns the GVK of the custom resource.
+func (r *QuiesceCustomResource) GroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{
+		Group:   r.Group,
+		Version: r.Version,
+		Kind:    r.Kind,
+	}
+}
+
+// GetPauseValue returns the decoded pause value.
+// A value that is not valid JSON is used as a string.
+func (r *QuiesceCustomResource) GetPauseValue() interface{} {
+	var value interface{}
+	err := json.Unmarshal([]byte(r.PauseValue), &value)
+	if err != nil {
+		return r.PauseValue
+	}
+	return value
+}
+
+// Validate returns the first invalid setting of the rule.
+func (r *QuiesceCustomResource) Validate() error {
+	if r.Group == "" || r.Version == "" || r.Kind == "" {
+		return fmt.Errorf("custom resource %s must specify group, version and kind", r.GroupVersionKind())
+	}
+	switch {
+	case r.ReplicasPath != "" && r.PausePath != "":
+		return fmt.Errorf("custom resource %s must specify either replicasPath or pausePath", r.Kind)
+	case r.ReplicasPath != "":
+		_, err := ParseFieldPath(r.ReplicasPath)
+		if err != nil {
+			return fmt.Errorf("replicasPath of custom resource %s is invalid: %s", r.Kind, err)
+		}
+	case r.PausePath != "":
+		_, err := ParseFieldPath(r.PausePath)
+		if err != nil {
+			return fmt.Errorf("pausePath of custom resource %s is invalid: %s", r.Kind, err)
+		}
+		if r.PauseValue == "" {
+			return fmt.Errorf("custom resource %s must specify pauseValue with pausePath", r.Kind)
+		}
+	default:
+		return fmt.Errorf("custom resource %s must specify replicasPath or pausePath", r.Kind)
+	}
+	return nil
+}
+
+// ParseFieldPath returns the field names of a JSONPath such as {.spec.replicas}.
+// Array indexes, wildcards and filters are not supported.
+func ParseFieldPath(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+	fields := strings.Split(path, ".")
+	for _, field := range fields {
+		if field == "" || strings.ContainsAny(field, "[]*@?()'\"{} ") {
+			return nil, fmt.Errorf("unsupported field %q", field)
+		}
+	}
+	return fields, nil
+}
+
+// FindCustomResource returns the scale rule for a custom resource group and kind.
+// Returns `nil` when not found.
+func (r *QuiescePolicy) FindCustomResource(group, kind string) *QuiesceCustomResource {
+	if r == nil {
+		return nil
+	}
+	for i := range r.CustomResources {
+		if r.CustomResources[i].Group == group && r.CustomResources[i].Kind == kind {
+			return &r.CustomResources[i]
+		}
+	}
+	return nil
+}
+
+// OwnedByCustomResource returns whether an object is owned by a custom resource
+// with a scale rule.
+func (r *QuiescePolicy) OwnedByCustomResource(object metav1.Object) bool {
+	if r == nil {
+		return false
+	}
+	for _, ref := range object.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			continue
+		}
+		if r.FindCustomResource(gv.Group, ref.Kind) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// FindKind returns the settings for a workload kind.
+// Returns `nil` when not found.
+func (r *QuiescePolicy) FindKind(kind string) *QuiesceKind {
+	if r == nil {
+		return nil
+	}
+	for i := range r.Kinds {
+		if r.Kinds[i].Kind == kind {
+			return &r.Kinds[i]
+		}
+	}
+	return nil
+}
+
+// Excluded returns whether a workload is referenced by the exclude list.
+func (r *QuiescePolicy) Excluded(kind string, object metav1.Object) bool {
+	if r == nil {
+		return false
+	}
+	for _, ref := range r.Exclude {
+		if ref.Kind != kind || ref.Name != object.GetName() {
+			continue
+		}
+		if ref.Namespace == "" || ref.Namespace == object.GetNamespace() {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns the first invalid setting of the policy.
+func (r *QuiescePolicy) Validate() error {
+	if r == nil {
+		return nil
+	}
+	names := []string{"selector", "excludeSelector"}
+	selectors := []*metav1.LabelSelector{r.Selector, r.ExcludeSelector}
+	for _, stage := range r.Stages {
+		if stage.Selector == nil {
+			return fmt.Errorf("stage %s has no selector", stage.Name)
+		}
+		names = append(names, "stage "+stage.Name)
+		selectors = append(selectors, stage.Selector)
+	}
+	for i, selector := range selectors {
+		if selector == nil {
+			continue
+		}
+		_, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return fmt.Errorf("%s is invalid: %s", names[i], err)
+		}
+	}
+	for _, kind := range r.Kinds {
+		if kind.Ordered && kind.Kind != "StatefulSet" {
+			return fmt.Errorf("ordered is not supported for kind %s", kind.Kind)
+		}
+		if kind.GracefulTimeout != nil && kind.GracefulTimeout.Duration < 0 {
+			return fmt.Errorf("gracefulTimeout of kind %s is negative", kind.Kind)
+		}
+	}
+	for i := range r.CustomResources {
+		rule := &r.CustomResources[i]
+		err := rule.Validate()
+		if err != nil {
+			return err
+		}
+		if r.FindCustomResource(rule.Group, rule.Kind) != rule {
+			return fmt.Errorf("custom resource %s is listed more than once", rule.GroupVersionKind())
+		}
+	}
+	return nil
+}
@@ -53,6 +53,11 @@ func (r *MigPlan) HasReconciled() bool {
 	return r.Status.ObservedDigest == digest(r.Spec)
 }
 
+// GetSpecDigest returns the digest of the plan spec.
+func (r *MigPlan) GetSpecDigest() string {
+	return digest(r.Spec)
+}
+
 // Storage
 func (r *MigStorage) GetCorrelationLabels() map[string]string {
 	key, value := r.GetCorrelationLabel()
@@ -145,6 +150,64 @@ func (r *MigHook) HasReconciled() bool {
 	return r.Status.ObservedGeneration == r.Generation
 }
 
+// Schedule
+func (r *MigSchedule) GetCorrelationLabels() map[string]string {
+	key, value := r.GetCorrelationLabel()
+	return map[string]string{
+		PartOfLabel: Application,
+		key:         value,
+	}
+}
+
+func (r *MigSchedule) GetCorrelationLabel() (string, string) {
+	return CorrelationLabel(r, r.UID)
+}
+
+func (r *MigSchedule) GetNamespace() string {
+	return r.Namespace
+}
+
+func (r *MigSchedule) GetName() string {
+	return r.Name
+}
+
+func (r *MigSchedule) MarkReconciled() {
+	r.Status.ObservedGeneration = r.Generation + 1
+}
+
+func (r *MigSchedule) HasReconciled() bool {
+	return r.Status.ObservedGeneration == r.Generation
+}
+
+// Wave
+func (r *MigWave) GetCorrelationLabels() map[string]string {
+	key, value := r.GetCorrelationLabel()
+	return map[string]string{
+		PartOfLabel: Application,
+		key:         value,
+	}
+}
+
+func (r *MigWave) GetCorrelationLabel() (string, string) {
+	return CorrelationLabel(r, r.UID)
+}
+
+func (r *MigWave) GetNamespace() string {
+	return r.Namespace
+}
+
+func (r *MigWave) GetName() string {
+	return r.Name
+}
+
+func (r *MigWave) MarkReconciled() {
+	r.Status.ObservedGeneration = r.Generation + 1
+}
+
+func (r *MigWave) HasReconciled() bool {
+	return r.Status.ObservedGeneration == r.Generation
+}
+
 // Direct
 func (r *DirectVolumeMigration) GetCorrelationLabels() map[string]string {
 	key, value := r.GetCorrelationLabel()
@@ -0,0 +1,323 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RsyncOptions rsync options of direct volume migrations.
+// The options set override the controller-wide defaults.
+type RsyncOptions struct {
+	// Bandwidth limit in KiB/s, equivalent to --bwlimit. Set 0 to remove the limit.
+	// +kubebuilder:validation:Minimum=0
+	BwLimit *int `json:"bwLimit,omitempty"`
+
+	// Whether to set the --archive option.
+	Archive *bool `json:"archive,omitempty"`
+
+	// Whether to set the --partial option.
+	Partial *bool `json:"partial,omitempty"`
+
+	// Whether to set the --delete option.
+	Delete *bool `json:"delete,omitempty"`
+
+	// Whether to set the --hard-links option.
+	HardLinks *bool `json:"hardLinks,omitempty"`
+
+	// Extra rsync options, added to the controller-wide extra options. Only the options changing how the
+	// files of the volume are compared, copied or reported are allowed, short options must not be grouped.
+	Extras []string `json:"extras,omitempty"`
+
+	// Number of retries of the rsync pods.
+	// +kubebuilder:validation:Minimum=0
+	BackOffLimit *int `json:"backOffLimit,omitempty"`
+
+	// Time-of-day bandwidth limits, preferred over bwLimit in their windows.
+	BandwidthSchedule *BandwidthSchedule `json:"bandwidthSchedule,omitempty"`
+}
+
+// BandwidthSchedule time-of-day bandwidth limits of the rsync transfers.
+// The rsync client pods are restarted when the limit changes.
+type BandwidthSchedule struct {
+	// IANA time zone of the windows. Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Windows of the schedule. The first window including the time of day applies.
+	Windows []BandwidthWindow `json:"windows,omitempty"`
+}
+
+// BandwidthWindow bandwidth limit applied during a time of day window.
+type BandwidthWindow struct {
+	// Start time of day, HH:MM.
+	Start string `json:"start"`
+
+	// End time of day, HH:MM. The window spans midnight when before the start.
+	End string `json:"end"`
+
+	// Bandwidth limit in KiB/s. Set 0 to remove the limit.
+	// +kubebuilder:validation:Minimum=0
+	BwLimit int `json:"bwLimit"`
+}
+
+// RsyncTransferLimits maximum numbers of concurrent rsync transfers.
+// The limits count the transfers of all the direct volume migrations on the source cluster.
+// The transfers over the limits are queued. The limits set override the controller-wide limits.
+type RsyncTransferLimits struct {
+	// Maximum number of concurrent transfers. Set 0 to remove the limit.
+	// +kubebuilder:validation:Minimum=0
+	MaxTransfers *int `json:"maxTransfers,omitempty"`
+
+	// Maximum number of concurrent transfers per source node. Set 0 to remove the limit.
+	// +kubebuilder:validation:Minimum=0
+	MaxTransfersPerNode *int `json:"maxTransfersPerNode,omitempty"`
+
+	// Maximum number of concurrent transfers per source storage class. Set 0 to remove the limit.
+	// +kubebuilder:validation:Minimum=0
+	MaxTransfersPerStorageClass *int `json:"maxTransfersPerStorageClass,omitempty"`
+}
+
+// Validate the limits.
+func (r *RsyncTransferLimits) Validate() error {
+	names := []string{"maxTransfers", "maxTransfersPerNode", "maxTransfersPerStorageClass"}
+	for i, limit := range []*int{r.MaxTransfers, r.MaxTransfersPerNode, r.MaxTransfersPerStorageClass} {
+		if limit != nil && *limit < 0 {
+			return fmt.Errorf("%s: must not be negative", names[i])
+		}
+	}
+	return nil
+}
+
+// Rsync extra options format.
+var rsyncExtraPattern = regexp.MustCompile(`^\-{1,2}([a-z0-9]+\-){0,}?[a-z0-9]+(=.*)?$`)
+
+// Rsync options users may set.
+// They only change how the files of the transferred volume are compared,
+// copied or reported. Options which run commands, read or write files
+// outside of the transferred volume or change the role of the rsync
+// process are not listed.
+var rsyncAllowedOptions = map[string]bool{
+	"-a":                 true,
+	"-c":                 true,
+	"-g":                 true,
+	"-h":                 true,
+	"-i":                 true,
+	"-l":                 true,
+	"-o":                 true,
+	"-p":                 true,
+	"-q":                 true,
+	"-r":                 true,
+	"-t":                 true,
+	"-u":                 true,
+	"-v":                 true,
+	"-x":                 true,
+	"-z":                 true,
+	"--acls":             true,
+	"--append":           true,
+	"--append-verify":    true,
+	"--archive":          true,
+	"--block-size":       true,
+	"--bwlimit":          true,
+	"--checksum":         true,
+	"--checksum-choice":  true,
+	"--chmod":            true,
+	"--compress":         true,
+	"--compress-choice":  true,
+	"--compress-level":   true,
+	"--contimeout":       true,
+	"--delete":           true,
+	"--delete-after":     true,
+	"--delete-before":    true,
+	"--delete-delay":     true,
+	"--delete-during":    true,
+	"--delete-excluded":  true,
+	"--devices":          true,
+	"--exclude":          true,
+	"--executability":    true,
+	"--existing":         true,
+	"--force":            true,
+	"--group":            true,
+	"--groupmap":         true,
+	"--hard-links":       true,
+	"--human-readable":   true,
+	"--ignore-errors":    true,
+	"--ignore-existing":  true,
+	"--ignore-times":     true,
+	"--include":          true,
+	"--info":             true,
+	"--inplace":          true,
+	"--itemize-changes":  true,
+	"--links":            true,
+	"--max-delete":       true,
+	"--max-size":         true,
+	"--min-size":         true,
+	"--modify-window":    true,
+	"--no-group":         true,
+	"--no-owner":         true,
+	"--no-perms":         true,
+	"--no-times":         true,
+	"--no-whole-file":    true,
+	"--numeric-ids":      true,
+	"--omit-dir-times":   true,
+	"--omit-link-times":  true,
+	"--one-file-system":  true,
+	"--owner":            true,
+	"--partial":          true,
+	"--perms":            true,
+	"--progress":         true,
+	"--prune-empty-dirs": true,
+	"--quiet":            true,
+	"--recursive":        true,
+	"--safe-links":       true,
+	"--size-only":        true,
+	"--sparse":           true,
+	"--specials":         true,
+	"--stats":            true,
+	"--timeout":          true,
+	"--times":            true,
+	"--update":           true,
+	"--usermap":          true,
+	"--verbose":          true,
+	"--whole-file":       true,
+	"--xattrs":           true,
+}
+
+// Validate the options.
+func (r *RsyncOptions) Validate() error {
+	if r.BwLimit != nil && *r.BwLimit < 0 {
+		return fmt.Errorf("bwLimit: must not be negative")
+	}
+	if r.BackOffLimit != nil && *r.BackOffLimit < 0 {
+		return fmt.Errorf("backOffLimit: must not be negative")
+	}
+	for _, opt := range r.Extras {
+		err := ValidateRsyncExtra(opt)
+		if err != nil {
+			return fmt.Errorf("extras: %s", err.Error())
+		}
+	}
+	if r.BandwidthSchedule != nil {
+		err := r.BandwidthSchedule.Validate()
+		if err != nil {
+			return fmt.Errorf("bandwidthSchedule: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// GetBandwidthSchedule returns the bandwidth schedule, nil when not set.
+func (r *RsyncOptions) GetBandwidthSchedule() *BandwidthSchedule {
+	if r == nil {
+		return nil
+	}
+	return r.BandwidthSchedule
+}
+
+// Validate the schedule.
+func (r *BandwidthSchedule) Validate() error {
+	_, err := r.location()
+	if err != nil {
+		return fmt.Errorf("timeZone: %s", err.Error())
+	}
+	for i, window := range r.Windows {
+		err := window.Validate()
+		if err != nil {
+			return fmt.Errorf("windows[%d]: %s", i, err.Error())
+		}
+	}
+	return nil
+}
+
+// ActiveWindow returns the window including the time of day, nil when none.
+func (r *BandwidthSchedule) ActiveWindow(now time.Time) *BandwidthWindow {
+	location, err := r.location()
+	if err != nil {
+		return nil
+	}
+	now = now.In(location)
+	minute := now.Hour()*60 + now.Minute()
+	for i := range r.Windows {
+		window := &r.Windows[i]
+		if window.Includes(minute) {
+			return window
+		}
+	}
+	return nil
+}
+
+// Location of the time zone.
+func (r *BandwidthSchedule) location() (*time.Location, error) {
+	if r.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(r.TimeZone)
+}
+
+// Validate the window.
+func (r *BandwidthWindow) Validate() error {
+	start, err := parseTimeOfDay(r.Start)
+	if err != nil {
+		return fmt.Errorf("start: %s", err.Error())
+	}
+	end, err := parseTimeOfDay(r.End)
+	if err != nil {
+		return fmt.Errorf("end: %s", err.Error())
+	}
+	if start == end {
+		return fmt.Errorf("start and end must differ")
+	}
+	if r.BwLimit < 0 {
+		return fmt.Errorf("bwLimit: must not be negative")
+	}
+	return nil
+}
+
+// Includes returns whether the window includes the minute of the day.
+func (r *BandwidthWindow) Includes(minute int) bool {
+	start, err := parseTimeOfDay(r.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseTimeOfDay(r.End)
+	if err != nil {
+		return false
+	}
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// String representation.
+func (r *BandwidthWindow) String() string {
+	return fmt.Sprintf("%s-%s", r.Start, r.End)
+}
+
+// Parse a HH:MM time of day into the minute of the day.
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' not a HH:MM time of day", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ValidateRsyncExtra validates an extra rsync option.
+// Only the allowed options are valid, short options must not be grouped.
+func ValidateRsyncExtra(opt string) error {
+	if !rsyncExtraPattern.MatchString(opt) {
+		return fmt.Errorf("option '%s' not valid", opt)
+	}
+	if strings.ContainsAny(opt, ";&|`$<>\\\"'\n") {
+		return fmt.Errorf("option '%s' contains shell characters", opt)
+	}
+	name := strings.SplitN(opt, "=", 2)[0]
+	if !strings.HasPrefix(name, "--") && len(name) != 2 {
+		return fmt.Errorf("option '%s' groups short options", name)
+	}
+	if !rsyncAllowedOptions[name] {
+		return fmt.Errorf("option '%s' not allowed", name)
+	}
+	return nil
+}
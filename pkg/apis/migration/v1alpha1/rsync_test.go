@@ -0,0 +1,31 @@
+package v1alpha1
+
+import "testing"
+
+func TestValidateRsyncExtra(t *testing.T) {
+	tests := []struct {
+		opt     string
+		wantErr bool
+	}{
+		{opt: "--checksum", wantErr: false},
+		{opt: "--info=progress2", wantErr: false},
+		{opt: "--exclude=*.tmp", wantErr: false},
+		{opt: "-z", wantErr: false},
+		{opt: "-az", wantErr: true},
+		{opt: "-aT", wantErr: true},
+		{opt: "-e", wantErr: true},
+		{opt: "--rsh=sh", wantErr: true},
+		{opt: "--remote-option=--log-file=/tmp/log", wantErr: true},
+		{opt: "--temp-dir=/tmp", wantErr: true},
+		{opt: "--unknown-option", wantErr: true},
+		{opt: "--exclude=a;b", wantErr: true},
+		{opt: "checksum", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.opt, func(t *testing.T) {
+			if err := ValidateRsyncExtra(tt.opt); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRsyncExtra() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RestoreTransform rewrites the restored resources of a kind on the destination cluster.
+// The patches are applied to the resources created by the final restore before the
+// destination workloads are unquiesced, the fields which cannot be updated such as
+// selectors cannot be transformed. When both patches are set, the merge patch is
+// applied first.
+type RestoreTransform struct {
+	// Name of the transform, recorded in the annotations of the transformed resources.
+	Name string `json:"name"`
+
+	// API group of the transformed resources. The core group when not set.
+	Group string `json:"group,omitempty"`
+
+	// Kind of the transformed resources.
+	Kind string `json:"kind"`
+
+	// Source namespaces of the transformed resources. All the namespaces of the plan when not set.
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Only the resources matching the selector are transformed.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// JSON patch (RFC 6902) in JSON or YAML.
+	JSONPatch string `json:"jsonPatch,omitempty"`
+
+	// Strategic merge patch in JSON or YAML. Applied as a JSON merge patch (RFC 7386) to the kinds not supporting strategic merge such as custom resources.
+	MergePatch string `json:"mergePatch,omitempty"`
+}
+
+// GroupKind returns the kind of the transformed resources.
+func (r *RestoreTransform) GroupKind() schema.GroupKind {
+	return schema.GroupKind{
+		Group: r.Group,
+		Kind:  r.Kind,
+	}
+}
+
+// AppliesTo returns whether the transform applies to the resources of a source namespace.
+func (r *RestoreTransform) AppliesTo(namespace string) bool {
+	if len(r.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range r.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	authorizationv1 "k8s.io/api/authorization/v1"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -51,6 +52,41 @@ func (in *BackupStorageConfig) DeepCopy() *BackupStorageConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthSchedule) DeepCopyInto(out *BandwidthSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]BandwidthWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthSchedule.
+func (in *BandwidthSchedule) DeepCopy() *BandwidthSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthWindow) DeepCopyInto(out *BandwidthWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthWindow.
+func (in *BandwidthWindow) DeepCopy() *BandwidthWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
@@ -565,6 +601,16 @@ func (in *DirectVolumeMigrationSpec) DeepCopyInto(out *DirectVolumeMigrationSpec
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RsyncOptions != nil {
+		in, out := &in.RsyncOptions, &out.RsyncOptions
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RsyncTransferLimits != nil {
+		in, out := &in.RsyncTransferLimits, &out.RsyncTransferLimits
+		*out = new(RsyncTransferLimits)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectVolumeMigrationSpec.
@@ -645,6 +691,11 @@ func (in *DirectVolumeMigrationStatus) DeepCopyInto(out *DirectVolumeMigrationSt
 			}
 		}
 	}
+	if in.ActiveBwLimit != nil {
+		in, out := &in.ActiveBwLimit, &out.ActiveBwLimit
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DirectVolumeMigrationStatus.
@@ -657,6 +708,21 @@ func (in *DirectVolumeMigrationStatus) DeepCopy() *DirectVolumeMigrationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookExecResult) DeepCopyInto(out *HookExecResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookExecResult.
+func (in *HookExecResult) DeepCopy() *HookExecResult {
+	if in == nil {
+		return nil
+	}
+	out := new(HookExecResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageStreamListItem) DeepCopyInto(out *ImageStreamListItem) {
 	*out = *in
@@ -1069,7 +1135,7 @@ func (in *MigHook) DeepCopyInto(out *MigHook) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -1091,6 +1157,31 @@ func (in *MigHook) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigHookExec) DeepCopyInto(out *MigHookExec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigHookExec.
+func (in *MigHookExec) DeepCopy() *MigHookExec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigHookExec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigHookList) DeepCopyInto(out *MigHookList) {
 	*out = *in
@@ -1123,9 +1214,77 @@ func (in *MigHookList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigHookPreflight) DeepCopyInto(out *MigHookPreflight) {
+	*out = *in
+	if in.ClusterRef != nil {
+		in, out := &in.ClusterRef, &out.ClusterRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]authorizationv1.ResourceAttributes, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigHookPreflight.
+func (in *MigHookPreflight) DeepCopy() *MigHookPreflight {
+	if in == nil {
+		return nil
+	}
+	out := new(MigHookPreflight)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigHookSpec) DeepCopyInto(out *MigHookSpec) {
 	*out = *in
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(MigHookExec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Preflight != nil {
+		in, out := &in.Preflight, &out.Preflight
+		*out = new(MigHookPreflight)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigHookSpec.
@@ -1221,6 +1380,28 @@ func (in *MigMigrationSpec) DeepCopyInto(out *MigMigrationSpec) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.QuiescePolicy != nil {
+		in, out := &in.QuiescePolicy, &out.QuiescePolicy
+		*out = new(QuiescePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceFilters != nil {
+		in, out := &in.ResourceFilters, &out.ResourceFilters
+		*out = make(ResourceFilters, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RollbackScope != nil {
+		in, out := &in.RollbackScope, &out.RollbackScope
+		*out = new(RollbackScope)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryMigrationRef != nil {
+		in, out := &in.RetryMigrationRef, &out.RetryMigrationRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
 	if in.RunAsRoot != nil {
 		in, out := &in.RunAsRoot, &out.RunAsRoot
 		*out = new(bool)
@@ -1257,6 +1438,10 @@ func (in *MigMigrationStatus) DeepCopyInto(out *MigMigrationStatus) {
 		in, out := &in.StartTimestamp, &out.StartTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.PhaseStartTimestamp != nil {
+		in, out := &in.PhaseStartTimestamp, &out.PhaseStartTimestamp
+		*out = (*in).DeepCopy()
+	}
 	if in.Pipeline != nil {
 		in, out := &in.Pipeline, &out.Pipeline
 		*out = make([]*Step, len(*in))
@@ -1273,6 +1458,11 @@ func (in *MigMigrationStatus) DeepCopyInto(out *MigMigrationStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.HookExecResults != nil {
+		in, out := &in.HookExecResults, &out.HookExecResults
+		*out = make([]HookExecResult, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigMigrationStatus.
@@ -1411,6 +1601,42 @@ func (in *MigPlanSpec) DeepCopyInto(out *MigPlanSpec) {
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PhaseTimeouts != nil {
+		in, out := &in.PhaseTimeouts, &out.PhaseTimeouts
+		*out = make(map[string]metav1.Duration, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.QuiescePolicy != nil {
+		in, out := &in.QuiescePolicy, &out.QuiescePolicy
+		*out = new(QuiescePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceFilters != nil {
+		in, out := &in.ResourceFilters, &out.ResourceFilters
+		*out = make(ResourceFilters, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Transforms != nil {
+		in, out := &in.Transforms, &out.Transforms
+		*out = make([]RestoreTransform, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RsyncOptions != nil {
+		in, out := &in.RsyncOptions, &out.RsyncOptions
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RsyncTransferLimits != nil {
+		in, out := &in.RsyncTransferLimits, &out.RsyncTransferLimits
+		*out = new(RsyncTransferLimits)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigPlanSpec.
@@ -1448,6 +1674,11 @@ func (in *MigPlanStatus) DeepCopyInto(out *MigPlanStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VolumeTransferHistory != nil {
+		in, out := &in.VolumeTransferHistory, &out.VolumeTransferHistory
+		*out = new(VolumeTransferHistory)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigPlanStatus.
@@ -1461,7 +1692,7 @@ func (in *MigPlanStatus) DeepCopy() *MigPlanStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigStorage) DeepCopyInto(out *MigStorage) {
+func (in *MigSchedule) DeepCopyInto(out *MigSchedule) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1469,18 +1700,18 @@ func (in *MigStorage) DeepCopyInto(out *MigStorage) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigStorage.
-func (in *MigStorage) DeepCopy() *MigStorage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigSchedule.
+func (in *MigSchedule) DeepCopy() *MigSchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(MigStorage)
+	out := new(MigSchedule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigStorage) DeepCopyObject() runtime.Object {
+func (in *MigSchedule) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1488,31 +1719,31 @@ func (in *MigStorage) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigStorageList) DeepCopyInto(out *MigStorageList) {
+func (in *MigScheduleList) DeepCopyInto(out *MigScheduleList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]MigStorage, len(*in))
+		*out = make([]MigSchedule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigStorageList.
-func (in *MigStorageList) DeepCopy() *MigStorageList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigScheduleList.
+func (in *MigScheduleList) DeepCopy() *MigScheduleList {
 	if in == nil {
 		return nil
 	}
-	out := new(MigStorageList)
+	out := new(MigScheduleList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MigStorageList) DeepCopyObject() runtime.Object {
+func (in *MigScheduleList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1520,112 +1751,372 @@ func (in *MigStorageList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigStorageSpec) DeepCopyInto(out *MigStorageSpec) {
+func (in *MigScheduleSpec) DeepCopyInto(out *MigScheduleSpec) {
 	*out = *in
-	in.BackupStorageConfig.DeepCopyInto(&out.BackupStorageConfig)
-	in.VolumeSnapshotConfig.DeepCopyInto(&out.VolumeSnapshotConfig)
+	if in.MigPlanRef != nil {
+		in, out := &in.MigPlanRef, &out.MigPlanRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.SucceededHistoryLimit != nil {
+		in, out := &in.SucceededHistoryLimit, &out.SucceededHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedHistoryLimit != nil {
+		in, out := &in.FailedHistoryLimit, &out.FailedHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigStorageSpec.
-func (in *MigStorageSpec) DeepCopy() *MigStorageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigScheduleSpec.
+func (in *MigScheduleSpec) DeepCopy() *MigScheduleSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MigStorageSpec)
+	out := new(MigScheduleSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MigStorageStatus) DeepCopyInto(out *MigStorageStatus) {
+func (in *MigScheduleStatus) DeepCopyInto(out *MigScheduleStatus) {
 	*out = *in
 	in.Conditions.DeepCopyInto(&out.Conditions)
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastMigrationRef != nil {
+		in, out := &in.LastMigrationRef, &out.LastMigrationRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigStorageStatus.
-func (in *MigStorageStatus) DeepCopy() *MigStorageStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigScheduleStatus.
+func (in *MigScheduleStatus) DeepCopy() *MigScheduleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MigStorageStatus)
+	out := new(MigScheduleStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PV) DeepCopyInto(out *PV) {
+func (in *MigStorage) DeepCopyInto(out *MigStorage) {
 	*out = *in
-	out.Capacity = in.Capacity.DeepCopy()
-	in.Supported.DeepCopyInto(&out.Supported)
-	out.Selection = in.Selection
-	in.PVC.DeepCopyInto(&out.PVC)
-	if in.NFS != nil {
-		in, out := &in.NFS, &out.NFS
-		*out = new(v1.NFSVolumeSource)
-		**out = **in
-	}
-	out.ProposedCapacity = in.ProposedCapacity.DeepCopy()
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PV.
-func (in *PV) DeepCopy() *PV {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigStorage.
+func (in *MigStorage) DeepCopy() *MigStorage {
 	if in == nil {
 		return nil
 	}
-	out := new(PV)
+	out := new(MigStorage)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigStorage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PVC) DeepCopyInto(out *PVC) {
+func (in *MigStorageList) DeepCopyInto(out *MigStorageList) {
 	*out = *in
-	if in.AccessModes != nil {
-		in, out := &in.AccessModes, &out.AccessModes
-		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigStorage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVC.
-func (in *PVC) DeepCopy() *PVC {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigStorageList.
+func (in *MigStorageList) DeepCopy() *MigStorageList {
 	if in == nil {
 		return nil
 	}
-	out := new(PVC)
+	out := new(MigStorageList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigStorageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PVCToMigrate) DeepCopyInto(out *PVCToMigrate) {
+func (in *MigStorageSpec) DeepCopyInto(out *MigStorageSpec) {
 	*out = *in
-	if in.ObjectReference != nil {
-		in, out := &in.ObjectReference, &out.ObjectReference
-		*out = new(v1.ObjectReference)
-		**out = **in
-	}
-	if in.TargetAccessModes != nil {
-		in, out := &in.TargetAccessModes, &out.TargetAccessModes
-		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
-		copy(*out, *in)
-	}
+	in.BackupStorageConfig.DeepCopyInto(&out.BackupStorageConfig)
+	in.VolumeSnapshotConfig.DeepCopyInto(&out.VolumeSnapshotConfig)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCToMigrate.
-func (in *PVCToMigrate) DeepCopy() *PVCToMigrate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigStorageSpec.
+func (in *MigStorageSpec) DeepCopy() *MigStorageSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PVCToMigrate)
+	out := new(MigStorageSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PersistentVolumes) DeepCopyInto(out *PersistentVolumes) {
+func (in *MigStorageStatus) DeepCopyInto(out *MigStorageStatus) {
 	*out = *in
-	if in.List != nil {
+	in.Conditions.DeepCopyInto(&out.Conditions)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigStorageStatus.
+func (in *MigStorageStatus) DeepCopy() *MigStorageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigStorageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigWave) DeepCopyInto(out *MigWave) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigWave.
+func (in *MigWave) DeepCopy() *MigWave {
+	if in == nil {
+		return nil
+	}
+	out := new(MigWave)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigWave) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigWaveList) DeepCopyInto(out *MigWaveList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MigWave, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigWaveList.
+func (in *MigWaveList) DeepCopy() *MigWaveList {
+	if in == nil {
+		return nil
+	}
+	out := new(MigWaveList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MigWaveList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigWaveSpec) DeepCopyInto(out *MigWaveSpec) {
+	*out = *in
+	if in.Plans != nil {
+		in, out := &in.Plans, &out.Plans
+		*out = make([]WavePlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigWaveSpec.
+func (in *MigWaveSpec) DeepCopy() *MigWaveSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigWaveSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigWaveStatus) DeepCopyInto(out *MigWaveStatus) {
+	*out = *in
+	in.Conditions.DeepCopyInto(&out.Conditions)
+	if in.Plans != nil {
+		in, out := &in.Plans, &out.Plans
+		*out = make([]WavePlanStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTimestamp != nil {
+		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigWaveStatus.
+func (in *MigWaveStatus) DeepCopy() *MigWaveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigWaveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PV) DeepCopyInto(out *PV) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+	in.Supported.DeepCopyInto(&out.Supported)
+	in.Selection.DeepCopyInto(&out.Selection)
+	in.PVC.DeepCopyInto(&out.PVC)
+	if in.NFS != nil {
+		in, out := &in.NFS, &out.NFS
+		*out = new(v1.NFSVolumeSource)
+		**out = **in
+	}
+	out.ProposedCapacity = in.ProposedCapacity.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PV.
+func (in *PV) DeepCopy() *PV {
+	if in == nil {
+		return nil
+	}
+	out := new(PV)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVC) DeepCopyInto(out *PVC) {
+	*out = *in
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVC.
+func (in *PVC) DeepCopy() *PVC {
+	if in == nil {
+		return nil
+	}
+	out := new(PVC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCTransferRecord) DeepCopyInto(out *PVCTransferRecord) {
+	*out = *in
+	if in.PVCReference != nil {
+		in, out := &in.PVCReference, &out.PVCReference
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.LastSync != nil {
+		in, out := &in.LastSync, &out.LastSync
+		*out = new(RsyncTransferStats)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCTransferRecord.
+func (in *PVCTransferRecord) DeepCopy() *PVCTransferRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCTransferRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCToMigrate) DeepCopyInto(out *PVCToMigrate) {
+	*out = *in
+	if in.ObjectReference != nil {
+		in, out := &in.ObjectReference, &out.ObjectReference
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.TargetAccessModes != nil {
+		in, out := &in.TargetAccessModes, &out.TargetAccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.RsyncOptions != nil {
+		in, out := &in.RsyncOptions, &out.RsyncOptions
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCToMigrate.
+func (in *PVCToMigrate) DeepCopy() *PVCToMigrate {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCToMigrate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentVolumes) DeepCopyInto(out *PersistentVolumes) {
+	*out = *in
+	if in.List != nil {
 		in, out := &in.List, &out.List
 		*out = make([]PV, len(*in))
 		for i := range *in {
@@ -1716,6 +2207,229 @@ func (in *PodProgress) DeepCopy() *PodProgress {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuiesceCustomResource) DeepCopyInto(out *QuiesceCustomResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuiesceCustomResource.
+func (in *QuiesceCustomResource) DeepCopy() *QuiesceCustomResource {
+	if in == nil {
+		return nil
+	}
+	out := new(QuiesceCustomResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuiesceKind) DeepCopyInto(out *QuiesceKind) {
+	*out = *in
+	if in.GracefulTimeout != nil {
+		in, out := &in.GracefulTimeout, &out.GracefulTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuiesceKind.
+func (in *QuiesceKind) DeepCopy() *QuiesceKind {
+	if in == nil {
+		return nil
+	}
+	out := new(QuiesceKind)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuiescePolicy) DeepCopyInto(out *QuiescePolicy) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludeSelector != nil {
+		in, out := &in.ExcludeSelector, &out.ExcludeSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]v1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Stages != nil {
+		in, out := &in.Stages, &out.Stages
+		*out = make([]QuiesceStage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]QuiesceKind, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CustomResources != nil {
+		in, out := &in.CustomResources, &out.CustomResources
+		*out = make([]QuiesceCustomResource, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuiescePolicy.
+func (in *QuiescePolicy) DeepCopy() *QuiescePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(QuiescePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuiesceStage) DeepCopyInto(out *QuiesceStage) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuiesceStage.
+func (in *QuiesceStage) DeepCopy() *QuiesceStage {
+	if in == nil {
+		return nil
+	}
+	out := new(QuiesceStage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceFilter) DeepCopyInto(out *ResourceFilter) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]metav1.GroupKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceFilter.
+func (in *ResourceFilter) DeepCopy() *ResourceFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ResourceFilters) DeepCopyInto(out *ResourceFilters) {
+	{
+		in := &in
+		*out = make(ResourceFilters, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceFilters.
+func (in ResourceFilters) DeepCopy() ResourceFilters {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceFilters)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTransform) DeepCopyInto(out *RestoreTransform) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTransform.
+func (in *RestoreTransform) DeepCopy() *RestoreTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackScope) DeepCopyInto(out *RollbackScope) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IncludedResources != nil {
+		in, out := &in.IncludedResources, &out.IncludedResources
+		*out = make([]*metav1.GroupKind, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(metav1.GroupKind)
+				**out = **in
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackScope.
+func (in *RollbackScope) DeepCopy() *RollbackScope {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackScope)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RsyncOperation) DeepCopyInto(out *RsyncOperation) {
 	*out = *in
@@ -1724,6 +2438,21 @@ func (in *RsyncOperation) DeepCopyInto(out *RsyncOperation) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.BwLimit != nil {
+		in, out := &in.BwLimit, &out.BwLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(RsyncVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Stats != nil {
+		in, out := &in.Stats, &out.Stats
+		*out = new(RsyncTransferStats)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncOperation.
@@ -1736,6 +2465,61 @@ func (in *RsyncOperation) DeepCopy() *RsyncOperation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncOptions) DeepCopyInto(out *RsyncOptions) {
+	*out = *in
+	if in.BwLimit != nil {
+		in, out := &in.BwLimit, &out.BwLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Partial != nil {
+		in, out := &in.Partial, &out.Partial
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Delete != nil {
+		in, out := &in.Delete, &out.Delete
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HardLinks != nil {
+		in, out := &in.HardLinks, &out.HardLinks
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Extras != nil {
+		in, out := &in.Extras, &out.Extras
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BackOffLimit != nil {
+		in, out := &in.BackOffLimit, &out.BackOffLimit
+		*out = new(int)
+		**out = **in
+	}
+	if in.BandwidthSchedule != nil {
+		in, out := &in.BandwidthSchedule, &out.BandwidthSchedule
+		*out = new(BandwidthSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncOptions.
+func (in *RsyncOptions) DeepCopy() *RsyncOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RsyncPodStatus) DeepCopyInto(out *RsyncPodStatus) {
 	*out = *in
@@ -1765,9 +2549,88 @@ func (in *RsyncPodStatus) DeepCopy() *RsyncPodStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncTransferLimits) DeepCopyInto(out *RsyncTransferLimits) {
+	*out = *in
+	if in.MaxTransfers != nil {
+		in, out := &in.MaxTransfers, &out.MaxTransfers
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxTransfersPerNode != nil {
+		in, out := &in.MaxTransfersPerNode, &out.MaxTransfersPerNode
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxTransfersPerStorageClass != nil {
+		in, out := &in.MaxTransfersPerStorageClass, &out.MaxTransfersPerStorageClass
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncTransferLimits.
+func (in *RsyncTransferLimits) DeepCopy() *RsyncTransferLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncTransferLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncTransferStats) DeepCopyInto(out *RsyncTransferStats) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncTransferStats.
+func (in *RsyncTransferStats) DeepCopy() *RsyncTransferStats {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncTransferStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RsyncVerification) DeepCopyInto(out *RsyncVerification) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RsyncVerification.
+func (in *RsyncVerification) DeepCopy() *RsyncVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(RsyncVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selection) DeepCopyInto(out *Selection) {
 	*out = *in
+	if in.RsyncOptions != nil {
+		in, out := &in.RsyncOptions, &out.RsyncOptions
+		*out = new(RsyncOptions)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Selection.
@@ -1913,6 +2776,33 @@ func (in *UnhealthyResources) DeepCopy() *UnhealthyResources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeTransferHistory) DeepCopyInto(out *VolumeTransferHistory) {
+	*out = *in
+	if in.PVCs != nil {
+		in, out := &in.PVCs, &out.PVCs
+		*out = make([]PVCTransferRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EstimatedCutoverDuration != nil {
+		in, out := &in.EstimatedCutoverDuration, &out.EstimatedCutoverDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeTransferHistory.
+func (in *VolumeTransferHistory) DeepCopy() *VolumeTransferHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeTransferHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSnapshotConfig) DeepCopyInto(out *VolumeSnapshotConfig) {
 	*out = *in
@@ -1933,6 +2823,56 @@ func (in *VolumeSnapshotConfig) DeepCopy() *VolumeSnapshotConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WavePlan) DeepCopyInto(out *WavePlan) {
+	*out = *in
+	if in.MigPlanRef != nil {
+		in, out := &in.MigPlanRef, &out.MigPlanRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WavePlan.
+func (in *WavePlan) DeepCopy() *WavePlan {
+	if in == nil {
+		return nil
+	}
+	out := new(WavePlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WavePlanStatus) DeepCopyInto(out *WavePlanStatus) {
+	*out = *in
+	if in.MigrationRef != nil {
+		in, out := &in.MigrationRef, &out.MigrationRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.RollbackRef != nil {
+		in, out := &in.RollbackRef, &out.RollbackRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WavePlanStatus.
+func (in *WavePlanStatus) DeepCopy() *WavePlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WavePlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Workload) DeepCopyInto(out *Workload) {
 	*out = *in
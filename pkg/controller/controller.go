@@ -27,7 +27,9 @@ import (
 	"github.com/konveyor/mig-controller/pkg/controller/mighook"
 	"github.com/konveyor/mig-controller/pkg/controller/migmigration"
 	"github.com/konveyor/mig-controller/pkg/controller/migplan"
+	"github.com/konveyor/mig-controller/pkg/controller/migschedule"
 	"github.com/konveyor/mig-controller/pkg/controller/migstorage"
+	"github.com/konveyor/mig-controller/pkg/controller/migwave"
 	"github.com/konveyor/mig-controller/pkg/settings"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -45,6 +47,8 @@ var CamControllers = []AddFunction{
 	mighook.Add,
 	migstorage.Add,
 	migplan.Add,
+	migschedule.Add,
+	migwave.Add,
 	miganalytic.Add,
 	directvolumemigration.Add,
 	directvolumemigrationprogress.Add,
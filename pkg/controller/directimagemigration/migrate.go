@@ -29,6 +29,19 @@ import (
 )
 
 func (r *ReconcileDirectImageMigration) migrate(ctx context.Context, imageMigration *migapi.DirectImageMigration) (time.Duration, error) {
+	// Paused
+	if imageMigration.Spec.Paused {
+		log.Info("DirectImageMigration is paused.", "phase", imageMigration.Status.Phase)
+		imageMigration.Status.SetCondition(migapi.Condition{
+			Type:     Paused,
+			Status:   migapi.True,
+			Reason:   imageMigration.Status.Phase,
+			Category: migapi.Advisory,
+			Message:  "The migration is paused.",
+		})
+		return NoReQ, nil
+	}
+
 	// Started
 	if imageMigration.Status.StartTimestamp == nil {
 		log.Info("Marking DirectImageMigration as started.")
@@ -41,6 +41,7 @@ const (
 	MissingDestinationClusterRegistryPath = "MissingDestinationClusterRegistryPath"
 	NsListEmpty                           = "NamespaceListEmpty"
 	NsNotFoundOnSourceCluster             = "NamespaceNotFoundOnSourceCluster"
+	Paused                                = "Paused"
 )
 
 // Validate the image migration resource
@@ -35,7 +35,9 @@ var phaseDescriptions = map[string]string{
 	EnsureRsyncRouteAdmitted:             "Waiting for Rsync route to be admitted.",
 	DeleteRsyncResources:                 "Deleting Rsync resources created by this migration",
 	WaitForRsyncResourcesTerminated:      "Waiting for Rsync resources to terminate",
+	ComputeVolumeManifests:               "Computing the mtime and size manifests of the source Persistent Volumes",
 	RunRsyncOperations:                   "Running Rsync Pods to migrate Persistent Volume data",
+	RunRsyncVerification:                 "Running Rsync Pods to verify the checksums of the migrated Persistent Volume data",
 	MigrationFailed:                      "The migration attempt failed, please see errors for more details",
 	Completed:                            "Complete",
 }
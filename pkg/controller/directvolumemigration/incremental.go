@@ -0,0 +1,338 @@
+package directvolumemigration
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	rsynctransfer "github.com/konveyor/crane-lib/state_transfer/transfer/rsync"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// VolumeManifestLabel identifies the Pod computing the manifest of the source volume of a PVC
+	VolumeManifestLabel = "migration.openshift.io/volume-manifest"
+	// VolumeManifestContainer name of the container computing the manifest
+	VolumeManifestContainer = "manifest"
+	// Mount path of the source volume in the manifest Pod
+	volumeManifestMountPath = "/mnt/volume"
+	// Number of log lines of the Rsync Pods including the transfer statistics
+	rsyncStatsLogLines = 20
+)
+
+// Lists the type, size, mtime and path of the files of the volume,
+// the manifest is the SHA-256 digest of the sorted list.
+var volumeManifestCommand = fmt.Sprintf(
+	"set -o pipefail; cd %s && find . -path ./lost+found -prune -o -printf '%%y %%s %%T@ %%p\\n' | LC_ALL=C sort | sha256sum",
+	volumeManifestMountPath)
+
+// Volume manifest format.
+var volumeManifestPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Rsync statistics.
+var (
+	rsyncStatsFilesPattern            = regexp.MustCompile(`^Number of files: ([0-9.,]+[KMGTP]?)`)
+	rsyncStatsFilesTransferredPattern = regexp.MustCompile(`^Number of regular files transferred: ([0-9.,]+[KMGTP]?)`)
+	rsyncStatsBytesPattern            = regexp.MustCompile(`^Total file size: ([0-9.,]+[KMGTP]?) bytes`)
+	rsyncStatsBytesTransferredPattern = regexp.MustCompile(`^Total transferred file size: ([0-9.,]+[KMGTP]?) bytes`)
+)
+
+// runVolumeManifests computes the manifests of the source volumes of the PVCs not transferred yet.
+// The transfers of the PVCs whose manifest matches the manifest of their last transfer are skipped.
+// The PVCs whose manifest cannot be computed are transferred.
+// Returns whether all manifests completed.
+func (t *Task) runVolumeManifests() (bool, error) {
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, err
+	}
+	nsMap, err := t.getNamespacedPVCPairs()
+	if err != nil {
+		return false, err
+	}
+	err = t.buildSourceLimitRangeMap(nsMap, srcClient)
+	if err != nil {
+		return false, err
+	}
+	pvcNodeMap, err := t.getPVCNodeNameMap(srcClient)
+	if err != nil {
+		return false, err
+	}
+	migration, err := t.Owner.GetMigrationForDVM(t.Client)
+	if err != nil {
+		return false, err
+	}
+	completed := true
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		operation := t.Owner.Status.GetRsyncOperationStatusForPVC(
+			&corev1.ObjectReference{
+				Name:      pvc.Name,
+				Namespace: pvc.Namespace,
+			},
+		)
+		if operation.Manifest != "" || operation.IsComplete() {
+			continue
+		}
+		pod, err := t.getVolumeManifestPod(srcClient, pvc.Namespace, pvc.Name)
+		if err != nil {
+			return false, err
+		}
+		if pod == nil {
+			completed = false
+			pod, err = t.buildVolumeManifestPod(srcClient, migration, pvc, pvcNodeMap[pvc.Namespace+"/"+pvc.Name])
+			if err != nil {
+				return false, err
+			}
+			err = srcClient.Create(context.TODO(), pod)
+			if err != nil {
+				return false, err
+			}
+			t.Log.Info("Created volume manifest pod for pvc", "pvc", operation)
+			continue
+		}
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			manifest, err := t.getVolumeManifest(srcClient, pod)
+			if err != nil {
+				return false, err
+			}
+			if setVolumeManifest(operation, pvc, manifest) {
+				t.Log.Info("Source volume not changed since the last transfer, skipping the transfer of pvc",
+					"pvc", operation, "manifest", manifest)
+			}
+		case corev1.PodFailed:
+			t.Log.Info("Volume manifest pod failed, transferring pvc",
+				"pvc", operation, "pod", path.Join(pod.Namespace, pod.Name))
+		default:
+			completed = false
+		}
+	}
+	return completed, nil
+}
+
+// setVolumeManifest sets the manifest of the source volume of a PVC on its Rsync operation.
+// The operation is skipped when the manifest matches the manifest of the last transfer of the PVC.
+// Returns whether the operation is skipped.
+func setVolumeManifest(operation *migapi.RsyncOperation, pvc migapi.PVCToMigrate, manifest string) bool {
+	operation.Manifest = manifest
+	if manifest == "" || manifest != pvc.LastSyncedManifest {
+		return false
+	}
+	operation.Skipped = true
+	operation.Succeeded = true
+	return true
+}
+
+// getVolumeManifestPod returns the Pod computing the manifest of the source volume of a PVC, nil when not found.
+func (t *Task) getVolumeManifestPod(client compat.Client, namespace, name string) (*corev1.Pod, error) {
+	labels := t.Owner.GetCorrelationLabels()
+	labels[VolumeManifestLabel] = getMD5Hash(name)
+	podList := corev1.PodList{}
+	err := client.List(context.TODO(),
+		&podList,
+		k8sclient.InNamespace(namespace),
+		k8sclient.MatchingLabels(labels),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(podList.Items) < 1 {
+		return nil, nil
+	}
+	return &podList.Items[0], nil
+}
+
+// buildVolumeManifestPod builds the Pod computing the manifest of the source volume of a PVC.
+// The Pod runs the Rsync transfer image on the node of the application with the security context of the Rsync Pods.
+func (t *Task) buildVolumeManifestPod(
+	client compat.Client,
+	migration *migapi.MigMigration,
+	pvc migapi.PVCToMigrate,
+	nodeName string) (*corev1.Pod, error) {
+	srcCluster, err := t.Owner.GetSourceCluster(t.Client)
+	if err != nil {
+		return nil, err
+	}
+	if srcCluster == nil {
+		return nil, fmt.Errorf("source cluster not found")
+	}
+	image, err := srcCluster.GetRsyncTransferImage(t.Client)
+	if err != nil {
+		return nil, err
+	}
+	securityContext, err := t.getSecurityContext(client, pvc.Namespace, migration)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := t.getRsyncClientResourceRequirements(pvc.Namespace, client)
+	if err != nil {
+		return nil, err
+	}
+	labels := t.buildDVMLabels()
+	labels[VolumeManifestLabel] = getMD5Hash(pvc.Name)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "volume-manifest-",
+			Namespace:    pvc.Namespace,
+			Labels:       labels,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:            VolumeManifestContainer,
+					Image:           image,
+					Command:         []string{"/bin/bash", "-c", volumeManifestCommand},
+					SecurityContext: securityContext,
+					Resources:       resources,
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "mnt",
+							MountPath: volumeManifestMountPath,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "mnt",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvc.Name,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+		},
+	}
+	if len(settings.Settings.DvmOpts.SourceSupplementalGroups) > 0 {
+		pod.Spec.SecurityContext = &corev1.PodSecurityContext{
+			SupplementalGroups: settings.Settings.DvmOpts.SourceSupplementalGroups,
+		}
+	}
+	return pod, nil
+}
+
+// getVolumeManifest returns the manifest logged by a completed manifest Pod, empty when not found.
+func (t *Task) getVolumeManifest(client compat.Client, pod *corev1.Pod) (string, error) {
+	readCloser, err := getPodLogStream(client, pod, VolumeManifestContainer, nil)
+	if err != nil {
+		return "", err
+	}
+	defer readCloser.Close()
+	return parseVolumeManifestLog(readCloser)
+}
+
+// parseVolumeManifestLog returns the digest printed by sha256sum, empty when not found.
+func parseVolumeManifestLog(r io.Reader) (string, error) {
+	manifest := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && volumeManifestPattern.MatchString(fields[0]) {
+			manifest = fields[0]
+		}
+	}
+	return manifest, scanner.Err()
+}
+
+// getRsyncTransferStats returns the statistics of the transfer of a succeeded Rsync Pod.
+// Returns nil when the statistics are not available.
+func (t *Task) getRsyncTransferStats(client compat.Client, pod *corev1.Pod) *migapi.RsyncTransferStats {
+	tailLines := int64(rsyncStatsLogLines)
+	readCloser, err := getPodLogStream(client, pod, rsynctransfer.RsyncContainer, &tailLines)
+	if err != nil {
+		t.Log.Info("Failed to get logs from Rsync Pod on source cluster",
+			"pod", path.Join(pod.Namespace, pod.Name), "error", err.Error())
+		return nil
+	}
+	defer readCloser.Close()
+	stats, err := parseRsyncStatsLog(readCloser)
+	if err != nil {
+		t.Log.Info("Failed to read logs from Rsync Pod on source cluster",
+			"pod", path.Join(pod.Namespace, pod.Name), "error", err.Error())
+		return nil
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != rsynctransfer.RsyncContainer || status.State.Terminated == nil {
+			continue
+		}
+		terminated := status.State.Terminated
+		stats.StartTime = terminated.StartedAt.DeepCopy()
+		stats.Duration = &metav1.Duration{
+			Duration: terminated.FinishedAt.Sub(terminated.StartedAt.Time),
+		}
+	}
+	return stats
+}
+
+// parseRsyncStatsLog returns the statistics printed by rsync --info=STATS2.
+func parseRsyncStatsLog(r io.Reader) (*migapi.RsyncTransferStats, error) {
+	stats := &migapi.RsyncTransferStats{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// keep the last progress update of the line
+		updates := strings.Split(scanner.Text(), "\r")
+		line := strings.TrimSpace(updates[len(updates)-1])
+		for pattern, value := range map[*regexp.Regexp]*int64{
+			rsyncStatsFilesPattern:            &stats.Files,
+			rsyncStatsFilesTransferredPattern: &stats.FilesTransferred,
+			rsyncStatsBytesPattern:            &stats.Bytes,
+			rsyncStatsBytesTransferredPattern: &stats.BytesTransferred,
+		} {
+			matched := pattern.FindStringSubmatch(line)
+			if len(matched) == 2 {
+				*value = parseRsyncNumber(matched[1])
+			}
+		}
+	}
+	return stats, scanner.Err()
+}
+
+// parseRsyncNumber parses a number printed by rsync --human-readable,
+// either digits grouped by commas or a decimal in units of 1000.
+func parseRsyncNumber(s string) int64 {
+	s = strings.ReplaceAll(s, ",", "")
+	multiplier := float64(1)
+	if s != "" {
+		if i := strings.IndexByte("KMGTP", s[len(s)-1]); i >= 0 {
+			for n := 0; n <= i; n++ {
+				multiplier *= 1000
+			}
+			s = s[:len(s)-1]
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return int64(math.Round(value * multiplier))
+}
+
+// getPodLogStream returns the logs of a container of a Pod.
+func getPodLogStream(client compat.Client, pod *corev1.Pod, container string, tailLines *int64) (io.ReadCloser, error) {
+	clientset, err := kubernetes.NewForConfig(client.RestConfig())
+	if err != nil {
+		return nil, err
+	}
+	req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: tailLines,
+	})
+	return req.Stream(context.TODO())
+}
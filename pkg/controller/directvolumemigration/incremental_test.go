@@ -0,0 +1,187 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_parseVolumeManifestLog(t *testing.T) {
+	digest := strings.Repeat("0123456789abcdef", 4)
+	tests := []struct {
+		name string
+		log  string
+		want string
+	}{
+		{
+			name: "given no output, no manifest is returned",
+			log:  "",
+			want: "",
+		},
+		{
+			name: "given sha256sum output, the digest is returned",
+			log:  digest + "  -\n",
+			want: digest,
+		},
+		{
+			name: "given an error output, no manifest is returned",
+			log:  "find: './data': Permission denied\n",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVolumeManifestLog(strings.NewReader(tt.log))
+			if err != nil {
+				t.Errorf("parseVolumeManifestLog() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseVolumeManifestLog() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setVolumeManifest(t *testing.T) {
+	pvcRef := &corev1.ObjectReference{Namespace: "ns-1", Name: "pvc-1"}
+	tests := []struct {
+		name         string
+		lastSynced   string
+		manifest     string
+		wantSkipped  bool
+		wantManifest string
+	}{
+		{
+			name:         "given a PVC never transferred, the transfer is not skipped",
+			lastSynced:   "",
+			manifest:     "a",
+			wantSkipped:  false,
+			wantManifest: "a",
+		},
+		{
+			name:         "given a changed source volume, the transfer is not skipped",
+			lastSynced:   "a",
+			manifest:     "b",
+			wantSkipped:  false,
+			wantManifest: "b",
+		},
+		{
+			name:         "given an unchanged source volume, the transfer is skipped",
+			lastSynced:   "a",
+			manifest:     "a",
+			wantSkipped:  true,
+			wantManifest: "a",
+		},
+		{
+			name:         "given no manifest, the transfer is not skipped",
+			lastSynced:   "",
+			manifest:     "",
+			wantSkipped:  false,
+			wantManifest: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := &migapi.RsyncOperation{PVCReference: pvcRef}
+			pvc := migapi.PVCToMigrate{ObjectReference: pvcRef, LastSyncedManifest: tt.lastSynced}
+			if got := setVolumeManifest(operation, pvc, tt.manifest); got != tt.wantSkipped {
+				t.Errorf("setVolumeManifest() = %v, want %v", got, tt.wantSkipped)
+			}
+			if operation.Manifest != tt.wantManifest {
+				t.Errorf("setVolumeManifest() manifest = %v, want %v", operation.Manifest, tt.wantManifest)
+			}
+			if operation.Skipped != tt.wantSkipped || operation.Succeeded != tt.wantSkipped {
+				t.Errorf("setVolumeManifest() skipped = %v, succeeded = %v, want %v",
+					operation.Skipped, operation.Succeeded, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func Test_parseRsyncStatsLog(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want *migapi.RsyncTransferStats
+	}{
+		{
+			name: "given no statistics, empty statistics are returned",
+			log:  "sending incremental file list\n",
+			want: &migapi.RsyncTransferStats{},
+		},
+		{
+			name: "given rsync statistics, the statistics are returned",
+			log: strings.Join([]string{
+				"      1,024 100%    0.00kB/s    0:00:00 (xfr#1, to-chk=0/3)\r      2,048 100%    1.95MB/s    0:00:00 (xfr#2, to-chk=0/3)",
+				"",
+				"Number of files: 3 (reg: 2, dir: 1)",
+				"Number of created files: 2 (reg: 2)",
+				"Number of deleted files: 0",
+				"Number of regular files transferred: 2",
+				"Total file size: 3,072 bytes",
+				"Total transferred file size: 3,072 bytes",
+				"Literal data: 3,072 bytes",
+			}, "\n"),
+			want: &migapi.RsyncTransferStats{
+				Files:            3,
+				FilesTransferred: 2,
+				Bytes:            3072,
+				BytesTransferred: 3072,
+			},
+		},
+		{
+			name: "given human readable rsync statistics, the statistics are returned",
+			log: strings.Join([]string{
+				"Number of files: 1.20K (reg: 1.10K, dir: 100)",
+				"Number of regular files transferred: 12",
+				"Total file size: 1.50G bytes",
+				"Total transferred file size: 2.30M bytes",
+			}, "\n"),
+			want: &migapi.RsyncTransferStats{
+				Files:            1200,
+				FilesTransferred: 12,
+				Bytes:            1500000000,
+				BytesTransferred: 2300000,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRsyncStatsLog(strings.NewReader(tt.log))
+			if err != nil {
+				t.Errorf("parseRsyncStatsLog() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRsyncStatsLog() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseRsyncNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int64
+	}{
+		{name: "digits", s: "512", want: 512},
+		{name: "digits grouped by commas", s: "1,234,567", want: 1234567},
+		{name: "kilo", s: "2K", want: 2000},
+		{name: "decimal mega", s: "1.5M", want: 1500000},
+		{name: "tera", s: "3T", want: 3000000000000},
+		{name: "invalid", s: "n/a", want: 0},
+		{name: "empty", s: "", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRsyncNumber(tt.s); got != tt.want {
+				t.Errorf("parseRsyncNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
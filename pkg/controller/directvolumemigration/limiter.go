@@ -0,0 +1,165 @@
+package directvolumemigration
+
+import (
+	"context"
+	"sort"
+
+	transfer "github.com/konveyor/crane-lib/state_transfer/transfer"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rsyncTransferLimiter admits the Rsync transfers within the concurrency limits.
+// A limit of 0 means not limited.
+type rsyncTransferLimiter struct {
+	maxTransfers                int
+	maxTransfersPerNode         int
+	maxTransfersPerStorageClass int
+	// transfers admitted
+	transfers int
+	// transfers admitted per source node
+	nodes map[string]int
+	// transfers admitted per source storage class
+	storageClasses map[string]int
+}
+
+// getRsyncTransferLimiter returns a limiter of the DVM limits merged over the controller-wide limits.
+func (t *Task) getRsyncTransferLimiter() *rsyncTransferLimiter {
+	o := settings.Settings.DvmOpts.RsyncTransferLimitOpts
+	l := &rsyncTransferLimiter{
+		maxTransfers:                o.MaxTransfers,
+		maxTransfersPerNode:         o.MaxTransfersPerNode,
+		maxTransfersPerStorageClass: o.MaxTransfersPerStorageClass,
+		nodes:                       map[string]int{},
+		storageClasses:              map[string]int{},
+	}
+	limits := t.Owner.Spec.RsyncTransferLimits
+	if limits != nil {
+		if limits.MaxTransfers != nil {
+			l.maxTransfers = *limits.MaxTransfers
+		}
+		if limits.MaxTransfersPerNode != nil {
+			l.maxTransfersPerNode = *limits.MaxTransfersPerNode
+		}
+		if limits.MaxTransfersPerStorageClass != nil {
+			l.maxTransfersPerStorageClass = *limits.MaxTransfersPerStorageClass
+		}
+	}
+	return l
+}
+
+// Enabled returns whether any limit is set.
+func (l *rsyncTransferLimiter) Enabled() bool {
+	return l.maxTransfers > 0 || l.maxTransfersPerNode > 0 || l.maxTransfersPerStorageClass > 0
+}
+
+// Admits returns whether a transfer of a PVC mounted on the node fits in the limits.
+// The node is empty when the PVC is not mounted.
+func (l *rsyncTransferLimiter) Admits(node string, storageClass string) bool {
+	if l.maxTransfers > 0 && l.transfers >= l.maxTransfers {
+		return false
+	}
+	if l.maxTransfersPerNode > 0 && node != "" && l.nodes[node] >= l.maxTransfersPerNode {
+		return false
+	}
+	if l.maxTransfersPerStorageClass > 0 && l.storageClasses[storageClass] >= l.maxTransfersPerStorageClass {
+		return false
+	}
+	return true
+}
+
+// Add a transfer of a PVC mounted on the node.
+func (l *rsyncTransferLimiter) Add(node string, storageClass string) {
+	l.transfers++
+	if node != "" {
+		l.nodes[node]++
+	}
+	l.storageClasses[storageClass]++
+}
+
+// getStorageClass returns the storage class name of a PVC.
+func getStorageClass(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName != nil {
+		return *pvc.Spec.StorageClassName
+	}
+	return ""
+}
+
+// addRunningTransfers adds the transfers of the Rsync client Pods running or pending on the
+// source cluster to the limiter. The limits are shared by the DVMs, the Pods of all the DVMs
+// are selected by the DVM correlation label.
+func (t *Task) addRunningTransfers(srcClient compat.Client, limiter *rsyncTransferLimiter) error {
+	dvmKey, _ := t.Owner.GetCorrelationLabel()
+	selector := labels.SelectorFromSet(labels.Set{
+		"app":              DirectVolumeMigrationRsyncTransfer,
+		"owner":            DirectVolumeMigration,
+		migapi.PartOfLabel: migapi.Application,
+	})
+	for _, key := range []string{dvmKey, migapi.RsyncPodIdentityLabel, RsyncAttemptLabel} {
+		requirement, err := labels.NewRequirement(key, selection.Exists, nil)
+		if err != nil {
+			return err
+		}
+		selector = selector.Add(*requirement)
+	}
+	podList := corev1.PodList{}
+	err := srcClient.List(context.TODO(), &podList, &k8sclient.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+	// storage classes of the source PVCs
+	storageClasses := map[string]string{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		switch pod.Status.Phase {
+		case corev1.PodRunning, corev1.PodPending, corev1.PodUnknown:
+		default:
+			continue
+		}
+		storageClass := ""
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			key := pod.Namespace + "/" + volume.PersistentVolumeClaim.ClaimName
+			class, found := storageClasses[key]
+			if !found {
+				pvc := &corev1.PersistentVolumeClaim{}
+				err := srcClient.Get(
+					context.TODO(),
+					types.NamespacedName{
+						Namespace: pod.Namespace,
+						Name:      volume.PersistentVolumeClaim.ClaimName,
+					},
+					pvc)
+				if err != nil && !k8serror.IsNotFound(err) {
+					return err
+				}
+				class = getStorageClass(pvc)
+				storageClasses[key] = class
+			}
+			storageClass = class
+			break
+		}
+		limiter.Add(pod.Spec.NodeName, storageClass)
+	}
+	return nil
+}
+
+// getSortedNamespaces returns the namespace pairs of the PVC map in order,
+// the queued transfers are created in this order.
+func getSortedNamespaces(nsMap map[string][]transfer.PVCPair) []string {
+	namespaces := []string{}
+	for bothNs := range nsMap {
+		namespaces = append(namespaces, bothNs)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
@@ -0,0 +1,144 @@
+package directvolumemigration
+
+import (
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_rsyncTransferLimiter(t *testing.T) {
+	one, two := 1, 2
+	tests := []struct {
+		name   string
+		limits *migapi.RsyncTransferLimits
+		added  [][2]string
+		node   string
+		sc     string
+		want   bool
+	}{
+		{
+			name:  "given no limits, transfers are admitted",
+			added: [][2]string{{"node-1", "gp2"}, {"node-1", "gp2"}},
+			node:  "node-1",
+			sc:    "gp2",
+			want:  true,
+		},
+		{
+			name:   "given a per node limit reached, transfers on the node are not admitted",
+			limits: &migapi.RsyncTransferLimits{MaxTransfersPerNode: &one},
+			added:  [][2]string{{"node-1", "gp2"}},
+			node:   "node-1",
+			sc:     "gp2",
+			want:   false,
+		},
+		{
+			name:   "given a per node limit reached, transfers on other nodes are admitted",
+			limits: &migapi.RsyncTransferLimits{MaxTransfersPerNode: &one},
+			added:  [][2]string{{"node-1", "gp2"}},
+			node:   "node-2",
+			sc:     "gp2",
+			want:   true,
+		},
+		{
+			name:   "given a per storage class limit reached, transfers of the class are not admitted",
+			limits: &migapi.RsyncTransferLimits{MaxTransfersPerStorageClass: &two},
+			added:  [][2]string{{"node-1", "gp2"}, {"node-2", "gp2"}, {"node-2", "nfs"}},
+			node:   "node-3",
+			sc:     "gp2",
+			want:   false,
+		},
+		{
+			name:   "given a global limit reached, transfers are not admitted",
+			limits: &migapi.RsyncTransferLimits{MaxTransfers: &two},
+			added:  [][2]string{{"node-1", "gp2"}, {"node-2", "nfs"}},
+			node:   "node-3",
+			sc:     "ceph",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{RsyncTransferLimits: tt.limits},
+				},
+			}
+			limiter := tr.getRsyncTransferLimiter()
+			for _, added := range tt.added {
+				limiter.Add(added[0], added[1])
+			}
+			if got := limiter.Admits(tt.node, tt.sc); got != tt.want {
+				t.Errorf("rsyncTransferLimiter.Admits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func getTestRsyncClientPod(dvmUID types.UID, name string, node string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns-1",
+			Labels: map[string]string{
+				"app":                        DirectVolumeMigrationRsyncTransfer,
+				"owner":                      DirectVolumeMigration,
+				migapi.PartOfLabel:           migapi.Application,
+				"directvolumemigration":      string(dvmUID),
+				migapi.RsyncPodIdentityLabel: getMD5Hash(name),
+				RsyncAttemptLabel:            "1",
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestTask_addRunningTransfers(t *testing.T) {
+	gp2 := "gp2"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1", Namespace: "ns-1"},
+		Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &gp2},
+	}
+	// a server Pod of another DVM on the same cluster is not a transfer
+	serverPod := getTestRsyncClientPod("dvm-2", "rsync-server", "node-1", corev1.PodRunning)
+	delete(serverPod.Labels, migapi.RsyncPodIdentityLabel)
+	delete(serverPod.Labels, RsyncAttemptLabel)
+	client := getFakeCompatClient(
+		pvc,
+		serverPod,
+		getTestRsyncClientPod("dvm-1", "pvc-1", "node-1", corev1.PodRunning),
+		getTestRsyncClientPod("dvm-2", "pvc-2", "node-1", corev1.PodPending),
+		getTestRsyncClientPod("dvm-2", "pvc-3", "node-2", corev1.PodSucceeded),
+		getTestRsyncClientPod("dvm-3", "pvc-4", "node-2", corev1.PodFailed),
+	)
+	tr := &Task{
+		Owner: &migapi.DirectVolumeMigration{
+			ObjectMeta: metav1.ObjectMeta{UID: "dvm-1"},
+		},
+	}
+	limiter := tr.getRsyncTransferLimiter()
+	err := tr.addRunningTransfers(client, limiter)
+	if err != nil {
+		t.Fatalf("addRunningTransfers() error = %v", err)
+	}
+	if limiter.transfers != 2 {
+		t.Errorf("addRunningTransfers() transfers = %v, want 2", limiter.transfers)
+	}
+	if limiter.nodes["node-1"] != 2 || limiter.nodes["node-2"] != 0 {
+		t.Errorf("addRunningTransfers() nodes = %v, want node-1: 2", limiter.nodes)
+	}
+	if limiter.storageClasses[gp2] != 1 || limiter.storageClasses[""] != 1 {
+		t.Errorf("addRunningTransfers() storage classes = %v, want gp2: 1, none: 1", limiter.storageClasses)
+	}
+}
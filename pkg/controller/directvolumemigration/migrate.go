@@ -14,6 +14,18 @@ import (
 )
 
 func (r *ReconcileDirectVolumeMigration) migrate(ctx context.Context, direct *migapi.DirectVolumeMigration) (time.Duration, error) {
+	// Paused
+	if direct.Spec.Paused {
+		log.Info("DirectVolumeMigration is paused.", "phase", direct.Status.Phase)
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     Paused,
+			Status:   True,
+			Reason:   direct.Status.Phase,
+			Category: Advisory,
+			Message:  PausedMessage,
+		})
+		return NoReQ, nil
+	}
 
 	planResources, err := r.getDVMPlanResources(direct)
 	if err != nil {
@@ -55,6 +55,8 @@ const (
 const (
 	// RsyncAttemptLabel is used to associate an Rsync Pod with the attempts
 	RsyncAttemptLabel = "migration.openshift.io/rsync-attempt"
+	// RsyncBwLimitLabel is used to track the bandwidth limit an Rsync Pod was created with
+	RsyncBwLimitLabel = "migration.openshift.io/rsync-bwlimit"
 )
 
 // ensureRsyncEndpoint ensures that a new Endpoint is created for Rsync Transfer
@@ -180,9 +182,101 @@ func (t *Task) getRsyncTransferOptions() ([]rsynctransfer.TransferOption, error)
 		transferOptions = append(transferOptions,
 			RsyncBwLimit(o.BwLimit))
 	}
+	// the options of the DVM override the controller-wide options
+	transferOptions = append(transferOptions,
+		getRsyncOptionOverrides(t.Owner.Spec.RsyncOptions)...)
 	return transferOptions, nil
 }
 
+// getRsyncOptionOverrides returns the transfer options of the rsync options set.
+// Extra options not valid are ignored.
+func getRsyncOptionOverrides(options *migapi.RsyncOptions) []rsynctransfer.TransferOption {
+	transferOptions := []rsynctransfer.TransferOption{}
+	if options == nil {
+		return transferOptions
+	}
+	if options.BwLimit != nil {
+		transferOptions = append(transferOptions, RsyncBwLimit(*options.BwLimit))
+	}
+	if options.Archive != nil {
+		transferOptions = append(transferOptions, rsynctransfer.ArchiveFiles(*options.Archive))
+	}
+	if options.Partial != nil {
+		transferOptions = append(transferOptions, Partial(*options.Partial))
+	}
+	if options.Delete != nil {
+		transferOptions = append(transferOptions, rsynctransfer.DeleteDestination(*options.Delete))
+	}
+	if options.HardLinks != nil {
+		transferOptions = append(transferOptions, HardLinks(*options.HardLinks))
+	}
+	extras := ExtraOpts{}
+	for _, opt := range options.Extras {
+		err := migapi.ValidateRsyncExtra(opt)
+		if err != nil {
+			log.Info("Invalid Rsync extra option passed", "option", opt, "reason", err.Error())
+			continue
+		}
+		extras = append(extras, opt)
+	}
+	if len(extras) > 0 {
+		transferOptions = append(transferOptions, extras)
+	}
+	return transferOptions
+}
+
+// getRsyncBwLimit returns the bandwidth limit in KiB/s of the rsync client of a PVC, 0 when not limited.
+// The limit of the active bandwidth schedule window is preferred over the static limits.
+// The schedule of the PVC is preferred over the schedule of the DVM.
+func (t *Task) getRsyncBwLimit(pvcOptions *migapi.RsyncOptions, now time.Time) (int, *migapi.BandwidthWindow) {
+	limit := settings.Settings.DvmOpts.RsyncOpts.BwLimit
+	for _, options := range []*migapi.RsyncOptions{t.Owner.Spec.RsyncOptions, pvcOptions} {
+		if options != nil && options.BwLimit != nil {
+			limit = *options.BwLimit
+		}
+	}
+	schedule := pvcOptions.GetBandwidthSchedule()
+	if schedule == nil {
+		schedule = t.Owner.Spec.RsyncOptions.GetBandwidthSchedule()
+	}
+	var window *migapi.BandwidthWindow
+	if schedule != nil {
+		window = schedule.ActiveWindow(now)
+		if window != nil {
+			limit = window.BwLimit
+		}
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	return limit, window
+}
+
+// isRsyncBwLimitChanged returns whether a running Rsync Pod was created with another bandwidth limit.
+// Pods created without the bandwidth limit label are not considered changed.
+func isRsyncBwLimitChanged(pod *corev1.Pod, bwLimit int) bool {
+	val, exists := pod.Labels[RsyncBwLimitLabel]
+	if !exists {
+		return false
+	}
+	switch pod.Status.Phase {
+	case corev1.PodRunning, corev1.PodPending:
+	default:
+		return false
+	}
+	return val != strconv.Itoa(bwLimit)
+}
+
+// getPVCRsyncOptions returns the rsync options of a PVC, nil when not set.
+func (t *Task) getPVCRsyncOptions(name, namespace string) *migapi.RsyncOptions {
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		if pvc.Name == name && pvc.Namespace == namespace {
+			return pvc.RsyncOptions
+		}
+	}
+	return nil
+}
+
 // getRsyncClientMutations get Rsync container mutations for source Rsync Pod
 func (t *Task) getRsyncClientMutations(srcClient compat.Client, destClient compat.Client, namespace string) ([]rsynctransfer.TransferOption, error) {
 	transferOptions := []rsynctransfer.TransferOption{}
@@ -448,7 +542,26 @@ func (t *Task) createRsyncTransferClients(srcClient compat.Client,
 
 	checkLabels := isPSAEnforced(destClient)
 
-	for bothNs, pvcPairs := range nsMap {
+	// report the bandwidth limit of the DVM
+	now := time.Now()
+	bwLimit, window := t.getRsyncBwLimit(nil, now)
+	t.Owner.Status.ActiveBwLimit = &bwLimit
+	t.Owner.Status.ActiveBandwidthWindow = ""
+	if window != nil {
+		t.Owner.Status.ActiveBandwidthWindow = window.String()
+	}
+
+	// the transfers over the concurrency limits are queued
+	limiter := t.getRsyncTransferLimiter()
+	if limiter.Enabled() {
+		err = t.addRunningTransfers(srcClient, limiter)
+		if err != nil {
+			return statusList, err
+		}
+	}
+
+	for _, bothNs := range getSortedNamespaces(nsMap) {
+		pvcPairs := nsMap[bothNs]
 		srcNs := getSourceNs(bothNs)
 		destNs := getDestNs(bothNs)
 		mutations, err := t.getRsyncClientMutations(srcClient, destClient, srcNs)
@@ -529,6 +642,7 @@ func (t *Task) createRsyncTransferClients(srcClient compat.Client,
 			}
 			// Force schedule Rsync Pod on the application node
 			nodeName := pvcNodeMap[fmt.Sprintf("%s/%s", srcNs, pvc.Source().Claim().Name)]
+			storageClass := getStorageClass(pvc.Source().Claim())
 			clientPodMutation := rsynctransfer.SourcePodSpecMutation{
 				Spec: &corev1.PodSpec{
 					NodeName: nodeName,
@@ -560,13 +674,33 @@ func (t *Task) createRsyncTransferClients(srcClient compat.Client,
 				optionsForPvc = append(optionsForPvc, ExtraOpts{"--omit-dir-times"})
 			}
 
+			// the options of the PVC override the options of the DVM
+			pvcOptions := t.getPVCRsyncOptions(pvc.Source().Claim().Name, pvc.Source().Claim().Namespace)
+			optionsForPvc = append(optionsForPvc, getRsyncOptionOverrides(pvcOptions)...)
+
+			// the bandwidth limit of the schedule overrides the static limits
+			pvcBwLimit, _ := t.getRsyncBwLimit(pvcOptions, now)
+			optionsForPvc = append(optionsForPvc, RsyncBwLimit(pvcBwLimit))
+			labels[RsyncBwLimitLabel] = strconv.Itoa(pvcBwLimit)
+
 			// Add identification label for Rsync Pod that keep them associated with a pvc
 			labels[migapi.RsyncPodIdentityLabel] = pvc.Source().LabelSafeName()
 
 			if pod != nil {
 				newOperation.CurrentAttempt, _ = strconv.Atoi(pod.Labels[RsyncAttemptLabel])
 				updateOperationStatus(&currentStatus, pod)
-				if currentStatus.failed && currentStatus.operation.CurrentAttempt < GetRsyncPodBackOffLimit(*t.Owner) {
+				backOffLimit := GetRsyncPodBackOffLimit(*t.Owner, &corev1.ObjectReference{
+					Name:      pvc.Source().Claim().Name,
+					Namespace: pvc.Source().Claim().Namespace,
+				})
+				if currentStatus.failed && currentStatus.operation.CurrentAttempt < backOffLimit &&
+					!limiter.Admits(nodeName, storageClass) {
+					// queue the retry until running transfers complete
+					currentStatus.failed = false
+					currentStatus.pending = true
+					newOperation.Queued = true
+					t.Log.Info("Rsync transfer limits reached, queued retry of rsync pod for pvc", "pvc", newOperation)
+				} else if currentStatus.failed && currentStatus.operation.CurrentAttempt < backOffLimit {
 					// since we have not yet attempted all retries,
 					// reset the failed status and set the pending status
 					currentStatus.failed = false
@@ -595,6 +729,9 @@ func (t *Task) createRsyncTransferClients(srcClient compat.Client,
 						continue
 					}
 					t.Log.Info("previous attempt of Rsync failed for pvc, created a new pod", "pvc", newOperation)
+					limiter.Add(nodeName, storageClass)
+					newOperation.BwLimit = &pvcBwLimit
+					newOperation.Queued = false
 					err = srcClient.Delete(context.TODO(), pod)
 					if err != nil {
 						t.Log.Error(err, "failed deleting rsync pod of previous attempt for pvc", "pvc", newOperation)
@@ -602,10 +739,53 @@ func (t *Task) createRsyncTransferClients(srcClient compat.Client,
 						statusList.Add(currentStatus)
 						continue
 					}
+				} else if isRsyncBwLimitChanged(pod, pvcBwLimit) {
+					// rsync cannot change the limit of a running transfer,
+					// restart the transfer of the current attempt with the new limit
+					currentStatus.running = false
+					currentStatus.pending = true
+					t.Log.Info("bandwidth limit changed, restarting rsync pod for pvc",
+						"pvc", newOperation, "pod", path.Join(pod.Namespace, pod.Name),
+						"bwLimit", pvcBwLimit)
+					err = srcClient.Delete(context.TODO(), pod)
+					if err != nil && !k8serror.IsNotFound(err) {
+						t.Log.Error(err, "failed deleting rsync pod of previous bandwidth limit for pvc", "pvc", newOperation)
+						currentStatus.AddError(err)
+						statusList.Add(currentStatus)
+						continue
+					}
+					labels[RsyncAttemptLabel] = fmt.Sprintf("%d", currentStatus.operation.CurrentAttempt)
+					// keep the partially transferred files
+					optionsForPvc = append(optionsForPvc, Partial(true), rsynctransfer.WithSourcePodLabels(labels))
+					transfer, err := rsynctransfer.NewTransfer(
+						stunnelTransport, endpoint, srcClient.RestConfig(), destClient.RestConfig(), pvcList, append(rsyncOptions, optionsForPvc...)...)
+					if err != nil {
+						t.Log.Error(err, "failed creating new rsync transfer", "pvc", newOperation)
+						currentStatus.AddError(err)
+						statusList.Add(currentStatus)
+						continue
+					}
+					if transfer == nil {
+						currentStatus.AddError(
+							fmt.Errorf("transfer %s/%s not found", nnPair.Source().Namespace, nnPair.Source().Name))
+						statusList.Add(currentStatus)
+						continue
+					}
+					err = transfer.CreateClient(srcClient)
+					if err != nil {
+						t.Log.Error(err, "failed creating rsync pod for pvc", "pvc", newOperation)
+						currentStatus.AddError(err)
+						statusList.Add(currentStatus)
+						continue
+					}
+					newOperation.BwLimit = &pvcBwLimit
 				} else {
 					t.Log.Info("previous attempt of Rsync did not fail", "pvc", newOperation)
 					newOperation.Failed = currentStatus.failed
 					newOperation.Succeeded = currentStatus.succeeded
+					if newOperation.Succeeded {
+						newOperation.Stats = t.getRsyncTransferStats(srcClient, pod)
+					}
 					if newOperation.IsComplete() {
 						t.Log.Info(
 							fmt.Sprintf("Rsync operation completed after %d attempts", newOperation.CurrentAttempt),
@@ -617,6 +797,11 @@ func (t *Task) createRsyncTransferClients(srcClient compat.Client,
 						)
 					}
 				}
+			} else if !limiter.Admits(nodeName, storageClass) {
+				// queue the transfer until running transfers complete
+				currentStatus.pending = true
+				newOperation.Queued = true
+				t.Log.Info("Rsync transfer limits reached, queued rsync pod for pvc", "pvc", newOperation)
 			} else {
 				newOperation.CurrentAttempt = 0
 				labels[RsyncAttemptLabel] = fmt.Sprintf("%d", currentStatus.operation.CurrentAttempt+1)
@@ -642,6 +827,9 @@ func (t *Task) createRsyncTransferClients(srcClient compat.Client,
 					statusList.Add(currentStatus)
 					continue
 				}
+				limiter.Add(nodeName, storageClass)
+				newOperation.BwLimit = &pvcBwLimit
+				newOperation.Queued = false
 			}
 			statusList.Add(currentStatus)
 			t.Log.Info("adding status of pvc", "pvc", currentStatus.operation, "errors", currentStatus.errors)
@@ -1237,6 +1425,10 @@ func (t *Task) hasAllProgressReportingCompleted() (bool, error) {
 						pendingSinceTimeLimitPods = append(pendingSinceTimeLimitPods, fmt.Sprintf("%s/%s", podProgress.Namespace, podProgress.Name))
 					}
 				}
+			case dvmp.Status.PodPhase == "" && operation.Skipped:
+				t.Owner.Status.SuccessfulPods = append(t.Owner.Status.SuccessfulPods, podProgress)
+			case dvmp.Status.PodPhase == "" && operation.Queued:
+				t.Owner.Status.PendingPods = append(t.Owner.Status.PendingPods, podProgress)
 			case dvmp.Status.PodPhase == "":
 				unknownPods = append(unknownPods, podProgress)
 			case !operation.Failed:
@@ -1676,7 +1868,21 @@ func isPrivilegedLabelPresent(client compat.Client, namespace string) (bool, err
 	return false, nil
 }
 
-func GetRsyncPodBackOffLimit(dvm migapi.DirectVolumeMigration) int {
+// GetRsyncPodBackOffLimit returns the retry limit of the Rsync pods of a PVC.
+// The limit of the PVC rsync options is preferred over the one of the DVM
+// rsync options, both are preferred over the controller-wide limit.
+func GetRsyncPodBackOffLimit(dvm migapi.DirectVolumeMigration, pvcRef *corev1.ObjectReference) int {
+	if pvcRef != nil {
+		for _, pvc := range dvm.Spec.PersistentVolumeClaims {
+			if pvc.Name == pvcRef.Name && pvc.Namespace == pvcRef.Namespace &&
+				pvc.RsyncOptions != nil && pvc.RsyncOptions.BackOffLimit != nil {
+				return *pvc.RsyncOptions.BackOffLimit
+			}
+		}
+	}
+	if dvm.Spec.RsyncOptions != nil && dvm.Spec.RsyncOptions.BackOffLimit != nil {
+		return *dvm.Spec.RsyncOptions.BackOffLimit
+	}
 	overriddenBackOffLimit := settings.Settings.DvmOpts.RsyncOpts.BackOffLimit
 	// when both the spec and the overridden backoff limits are not set, use default
 	if dvm.Spec.BackOffLimit == 0 && overriddenBackOffLimit == 0 {
@@ -2015,10 +2221,15 @@ func Union(m1 map[string]string, m2 map[string]string) map[string]string {
 
 type RsyncBwLimit int
 
+// ApplyTo sets the bandwidth limit, a limit of 0 removes the limit.
+// A 0 limit set by the DVM or PVC options must remove a limit set by
+// the controller-wide options while the transfer library rejects
+// --bwlimit=0, the option is not passed instead.
 func (r RsyncBwLimit) ApplyTo(opts *rsynctransfer.TransferOptions) error {
 	val := int(r)
-	if val < 0 {
+	if val <= 0 {
 		opts.BwLimit = nil
+		return nil
 	}
 	opts.BwLimit = &val
 	return nil
@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-logr/logr"
 	transfer "github.com/konveyor/crane-lib/state_transfer/transfer"
+	rsynctransfer "github.com/konveyor/crane-lib/state_transfer/transfer/rsync"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/compat"
 	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
@@ -818,6 +819,7 @@ func TestTask_createRsyncTransferClients(t *testing.T) {
 		Owner      *migapi.DirectVolumeMigration
 		PVCPairMap map[string][]transfer.PVCPair
 	}
+	maxTransfers := 1
 	migration := &migapi.MigMigration{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "migmigration",
@@ -917,6 +919,38 @@ func TestTask_createRsyncTransferClients(t *testing.T) {
 			wantReturn:   rsyncClientOperationStatusList{},
 			wantCRStatus: []*migapi.RsyncOperation{},
 		},
+		{
+			name: "when there are 0 existing Rsync Pods in source and 3 new PVCs are provided as input with a limit of 1 transfer, 1 Rsync Pod must be created and 2 transfers must be queued",
+			fields: fields{
+				SrcClient:  getFakeCompatClient(getDependencies("test-ns", "test-dvm")...),
+				DestClient: getFakeCompatClient(append(getDependencies("test-ns", "test-dvm"), migration)...),
+				Owner: &migapi.DirectVolumeMigration{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-dvm", Namespace: migapi.OpenshiftMigrationNamespace, OwnerReferences: []metav1.OwnerReference{{Name: "migmigration"}}},
+					Spec: migapi.DirectVolumeMigrationSpec{
+						BackOffLimit:        2,
+						RsyncTransferLimits: &migapi.RsyncTransferLimits{MaxTransfers: &maxTransfers},
+					},
+				},
+				PVCPairMap: map[string][]transfer.PVCPair{
+					"test-ns": {
+						getPVCPair("pvc-0", "ns-00"),
+						getPVCPair("pvc-0", "ns-01"),
+						getPVCPair("pvc-0", "ns-02"),
+					},
+				},
+			},
+			wantPods: []*corev1.Pod{
+				getTestRsyncPodForPVC("pod-0", "pvc-0", "ns-00", "1", metav1.Now().Time),
+			},
+			wantErr: false,
+			wantReturn: rsyncClientOperationStatusList{
+				ops: []rsyncClientOperationStatus{{pending: true}, {pending: true}},
+			},
+			wantCRStatus: []*migapi.RsyncOperation{
+				{PVCReference: &corev1.ObjectReference{Name: "pvc-0", Namespace: "ns-01"}, Queued: true},
+				{PVCReference: &corev1.ObjectReference{Name: "pvc-0", Namespace: "ns-02"}, Queued: true},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1135,3 +1169,250 @@ func Test_getSecurityContext(t *testing.T) {
 		})
 	}
 }
+
+func Test_getRsyncOptionOverrides(t *testing.T) {
+	bwLimit := 512
+	noLimit := 0
+	falseBool := false
+	tests := []struct {
+		name          string
+		options       []*migapi.RsyncOptions
+		wantBwLimit   *int
+		wantDelete    bool
+		wantHardLinks bool
+		wantExtras    []string
+	}{
+		{
+			name:          "given no options, the defaults are kept",
+			options:       []*migapi.RsyncOptions{nil},
+			wantDelete:    true,
+			wantHardLinks: true,
+			wantExtras:    []string{"--info=COPY2"},
+		},
+		{
+			name: "given DVM options, they override the defaults",
+			options: []*migapi.RsyncOptions{
+				{BwLimit: &bwLimit, Delete: &falseBool, Extras: []string{"--sparse"}},
+			},
+			wantBwLimit:   &bwLimit,
+			wantHardLinks: true,
+			wantExtras:    []string{"--info=COPY2", "--sparse"},
+		},
+		{
+			name: "given PVC options, they override the DVM options",
+			options: []*migapi.RsyncOptions{
+				{BwLimit: &bwLimit, Delete: &falseBool},
+				{BwLimit: &noLimit, HardLinks: &falseBool},
+			},
+			wantExtras: []string{"--info=COPY2"},
+		},
+		{
+			name: "given unsafe extras, they are ignored",
+			options: []*migapi.RsyncOptions{
+				{Extras: []string{"--rsh=sh", "-ze", "-at", "--remote-option=--log-file=/tmp/log", "--log-file=/tmp/log", "--checksum", "-z"}},
+			},
+			wantDelete:    true,
+			wantHardLinks: true,
+			wantExtras:    []string{"--info=COPY2", "--checksum", "-z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := rsynctransfer.TransferOptions{}
+			transferOptions := []rsynctransfer.TransferOption{
+				rsynctransfer.DeleteDestination(true),
+				HardLinks(true),
+				ExtraOpts{"--info=COPY2"},
+			}
+			for _, options := range tt.options {
+				transferOptions = append(transferOptions, getRsyncOptionOverrides(options)...)
+			}
+			err := opts.Apply(transferOptions...)
+			if err != nil {
+				t.Fatalf("getRsyncOptionOverrides() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(opts.BwLimit, tt.wantBwLimit) {
+				t.Errorf("getRsyncOptionOverrides() bwLimit = %v, want %v", opts.BwLimit, tt.wantBwLimit)
+			}
+			if opts.Delete != tt.wantDelete {
+				t.Errorf("getRsyncOptionOverrides() delete = %v, want %v", opts.Delete, tt.wantDelete)
+			}
+			if opts.HardLinks != tt.wantHardLinks {
+				t.Errorf("getRsyncOptionOverrides() hardLinks = %v, want %v", opts.HardLinks, tt.wantHardLinks)
+			}
+			if !reflect.DeepEqual(opts.Extras, tt.wantExtras) {
+				t.Errorf("getRsyncOptionOverrides() extras = %v, want %v", opts.Extras, tt.wantExtras)
+			}
+		})
+	}
+}
+
+func TestGetRsyncPodBackOffLimit(t *testing.T) {
+	dvmLimit := 5
+	pvcLimit := 0
+	dvm := migapi.DirectVolumeMigration{
+		Spec: migapi.DirectVolumeMigrationSpec{
+			BackOffLimit: 2,
+			PersistentVolumeClaims: []migapi.PVCToMigrate{
+				{
+					ObjectReference: &corev1.ObjectReference{Name: "pvc-1", Namespace: "ns"},
+					RsyncOptions:    &migapi.RsyncOptions{BackOffLimit: &pvcLimit},
+				},
+				{
+					ObjectReference: &corev1.ObjectReference{Name: "pvc-2", Namespace: "ns"},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		options *migapi.RsyncOptions
+		pvc     *corev1.ObjectReference
+		want    int
+	}{
+		{
+			name: "given no rsync options, the spec limit is used",
+			pvc:  &corev1.ObjectReference{Name: "pvc-2", Namespace: "ns"},
+			want: 2,
+		},
+		{
+			name:    "given DVM rsync options, the DVM options limit is used",
+			options: &migapi.RsyncOptions{BackOffLimit: &dvmLimit},
+			pvc:     &corev1.ObjectReference{Name: "pvc-2", Namespace: "ns"},
+			want:    5,
+		},
+		{
+			name:    "given PVC rsync options, the PVC options limit is used",
+			options: &migapi.RsyncOptions{BackOffLimit: &dvmLimit},
+			pvc:     &corev1.ObjectReference{Name: "pvc-1", Namespace: "ns"},
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dvm.Spec.RsyncOptions = tt.options
+			if got := GetRsyncPodBackOffLimit(dvm, tt.pvc); got != tt.want {
+				t.Errorf("GetRsyncPodBackOffLimit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getRsyncBwLimit(t *testing.T) {
+	dvmLimit := 1024
+	schedule := &migapi.BandwidthSchedule{
+		Windows: []migapi.BandwidthWindow{
+			{Start: "08:00", End: "18:00", BwLimit: 20480},
+			{Start: "22:00", End: "02:00", BwLimit: 0},
+		},
+	}
+	day := func(hour, minute int) time.Time {
+		return time.Date(2022, 1, 3, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name       string
+		options    *migapi.RsyncOptions
+		pvcOptions *migapi.RsyncOptions
+		now        time.Time
+		want       int
+		wantWindow string
+	}{
+		{
+			name:    "given no schedule, the static limit is used",
+			options: &migapi.RsyncOptions{BwLimit: &dvmLimit},
+			now:     day(9, 0),
+			want:    1024,
+		},
+		{
+			name:       "given a schedule, the limit of the active window is used",
+			options:    &migapi.RsyncOptions{BwLimit: &dvmLimit, BandwidthSchedule: schedule},
+			now:        day(17, 59),
+			want:       20480,
+			wantWindow: "08:00-18:00",
+		},
+		{
+			name:    "given a schedule, the static limit is used outside of the windows",
+			options: &migapi.RsyncOptions{BwLimit: &dvmLimit, BandwidthSchedule: schedule},
+			now:     day(18, 0),
+			want:    1024,
+		},
+		{
+			name:       "given a window spanning midnight, the limit of the window is used",
+			options:    &migapi.RsyncOptions{BwLimit: &dvmLimit, BandwidthSchedule: schedule},
+			now:        day(1, 30),
+			want:       0,
+			wantWindow: "22:00-02:00",
+		},
+		{
+			name:    "given a PVC schedule, it is preferred over the DVM schedule",
+			options: &migapi.RsyncOptions{BandwidthSchedule: schedule},
+			pvcOptions: &migapi.RsyncOptions{
+				BandwidthSchedule: &migapi.BandwidthSchedule{
+					Windows: []migapi.BandwidthWindow{{Start: "00:00", End: "12:00", BwLimit: 512}},
+				},
+			},
+			now:        day(9, 0),
+			want:       512,
+			wantWindow: "00:00-12:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &Task{
+				Owner: &migapi.DirectVolumeMigration{
+					Spec: migapi.DirectVolumeMigrationSpec{RsyncOptions: tt.options},
+				},
+			}
+			got, window := tr.getRsyncBwLimit(tt.pvcOptions, tt.now)
+			if got != tt.want {
+				t.Errorf("Task.getRsyncBwLimit() = %v, want %v", got, tt.want)
+			}
+			gotWindow := ""
+			if window != nil {
+				gotWindow = window.String()
+			}
+			if gotWindow != tt.wantWindow {
+				t.Errorf("Task.getRsyncBwLimit() window = %v, want %v", gotWindow, tt.wantWindow)
+			}
+		})
+	}
+}
+
+func Test_isRsyncBwLimitChanged(t *testing.T) {
+	pod := func(phase corev1.PodPhase, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{
+			name: "given a running pod with the same limit, it is not changed",
+			pod:  pod(corev1.PodRunning, map[string]string{RsyncBwLimitLabel: "512"}),
+		},
+		{
+			name: "given a running pod with another limit, it is changed",
+			pod:  pod(corev1.PodRunning, map[string]string{RsyncBwLimitLabel: "0"}),
+			want: true,
+		},
+		{
+			name: "given a completed pod with another limit, it is not changed",
+			pod:  pod(corev1.PodSucceeded, map[string]string{RsyncBwLimitLabel: "0"}),
+		},
+		{
+			name: "given a running pod without limit label, it is not changed",
+			pod:  pod(corev1.PodRunning, map[string]string{}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRsyncBwLimitChanged(tt.pod, 512); got != tt.want {
+				t.Errorf("isRsyncBwLimitChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
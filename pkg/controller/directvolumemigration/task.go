@@ -37,7 +37,9 @@ const (
 	CreateRsyncTransferPods              = "CreateRsyncTransferPods"
 	WaitForRsyncTransferPodsRunning      = "WaitForRsyncTransferPodsRunning"
 	CreatePVProgressCRs                  = "CreatePVProgressCRs"
+	ComputeVolumeManifests               = "ComputeVolumeManifests"
 	RunRsyncOperations                   = "RunRsyncOperations"
+	RunRsyncVerification                 = "RunRsyncVerification"
 	DeleteRsyncResources                 = "DeleteRsyncResources"
 	WaitForRsyncResourcesTerminated      = "WaitForRsyncResourcesTerminated"
 	WaitForStaleRsyncResourcesTerminated = "WaitForStaleRsyncResourcesTerminated"
@@ -118,7 +120,9 @@ var VolumeMigration = Itinerary{
 		{phase: CreatePVProgressCRs},
 		{phase: CreateRsyncTransferPods},
 		{phase: WaitForRsyncTransferPodsRunning},
+		{phase: ComputeVolumeManifests},
 		{phase: RunRsyncOperations},
+		{phase: RunRsyncVerification},
 		{phase: DeleteRsyncResources},
 		{phase: WaitForRsyncResourcesTerminated},
 		{phase: Completed},
@@ -403,6 +407,42 @@ func (t *Task) Run(ctx context.Context) error {
 				return err
 			}
 		}
+	case ComputeVolumeManifests:
+		completed := true
+		if t.Owner.Spec.Incremental {
+			completed, err = t.runVolumeManifests()
+			if err != nil {
+				return err
+			}
+		}
+		t.Requeue = PollReQ
+		if completed {
+			t.Requeue = NoReQ
+			if err = t.next(); err != nil {
+				return err
+			}
+		}
+	case RunRsyncVerification:
+		completed, reasons, err := t.runRsyncVerification()
+		if err != nil {
+			return err
+		}
+		t.Requeue = PollReQ
+		if completed {
+			t.Requeue = NoReQ
+			if len(reasons) > 0 {
+				failed, err := t.setRsyncVerificationFailed(reasons)
+				if err != nil {
+					return err
+				}
+				if failed {
+					return nil
+				}
+			}
+			if err = t.next(); err != nil {
+				return err
+			}
+		}
 	case CreatePVProgressCRs:
 		err := t.createPVProgressCR()
 		if err != nil {
@@ -2,6 +2,7 @@ package directvolumemigration
 
 import (
 	"context"
+	"fmt"
 
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	migref "github.com/konveyor/mig-controller/pkg/reference"
@@ -32,6 +33,9 @@ const (
 	FailedCreatingRsyncPods         = "FailedCreatingRsyncPods"
 	FailedDeletingRsyncPods         = "FailedDeletingRsyncPods"
 	RsyncServerPodsRunningAsNonRoot = "RsyncServerPodsRunningAsNonRoot"
+	Paused                          = "Paused"
+	InvalidRsyncOptions             = "InvalidRsyncOptions"
+	RsyncVerificationFailed         = "RsyncVerificationFailed"
 )
 
 // Reasons
@@ -42,6 +46,7 @@ const (
 	NotReady           = "NotReady"
 	RsyncTimeout       = "RsyncTimedOut"
 	RsyncNoRouteToHost = "RsyncNoRouteToHost"
+	NotValid           = "NotValid"
 )
 
 // Messages
@@ -58,6 +63,9 @@ const (
 	PVCsNotFoundOnSourceClusterMessage        = "The set of pvcs were not found on source cluster"
 	SucceededMessage                          = "The migration has succeeded"
 	FailedMessage                             = "The migration has failed.  See: Errors."
+	PausedMessage                             = "The migration is paused."
+	InvalidRsyncOptionsMessage                = "The rsync options are not valid: []."
+	RsyncVerificationFailedMessage            = "The data of the migrated volumes differs from the source volumes: []."
 )
 
 // Categories
@@ -92,6 +100,7 @@ func (r ReconcileDirectVolumeMigration) validate(ctx context.Context, direct *mi
 	if err != nil {
 		return err
 	}
+	r.validateRsyncOptions(direct)
 	return nil
 }
 
@@ -264,3 +273,40 @@ func (r ReconcileDirectVolumeMigration) validatePVCs(ctx context.Context, direct
 	}
 	return nil
 }
+
+// Validate the rsync options and transfer limits of the DVM and the rsync options of the PVCs.
+func (r ReconcileDirectVolumeMigration) validateRsyncOptions(direct *migapi.DirectVolumeMigration) {
+	problems := []string{}
+	if direct.Spec.RsyncOptions != nil {
+		err := direct.Spec.RsyncOptions.Validate()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("rsyncOptions: %s", err.Error()))
+		}
+	}
+	if direct.Spec.RsyncTransferLimits != nil {
+		err := direct.Spec.RsyncTransferLimits.Validate()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("rsyncTransferLimits: %s", err.Error()))
+		}
+	}
+	for _, pvc := range direct.Spec.PersistentVolumeClaims {
+		if pvc.RsyncOptions == nil {
+			continue
+		}
+		err := pvc.RsyncOptions.Validate()
+		if err != nil {
+			problems = append(problems,
+				fmt.Sprintf("%s/%s: %s", pvc.Namespace, pvc.Name, err.Error()))
+		}
+	}
+	if len(problems) > 0 {
+		direct.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRsyncOptions,
+			Status:   True,
+			Reason:   NotValid,
+			Category: Critical,
+			Message:  InvalidRsyncOptionsMessage,
+			Items:    problems,
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package directvolumemigration
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	cranemeta "github.com/konveyor/crane-lib/state_transfer/meta"
+	transfer "github.com/konveyor/crane-lib/state_transfer/transfer"
+	rsynctransfer "github.com/konveyor/crane-lib/state_transfer/transfer/rsync"
+	stunneltransport "github.com/konveyor/crane-lib/state_transfer/transport/stunnel"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/konveyor/mig-controller/pkg/settings"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RsyncVerifyLabel identifies the Rsync Pod verifying the data of a PVC
+	RsyncVerifyLabel = "migration.openshift.io/rsync-verify"
+	// RsyncVerifyMaxFiles maximum number of mismatched files reported per PVC
+	RsyncVerifyMaxFiles = 10
+)
+
+// rsyncVerifyOpts turns the rsync transfer into a checksum comparison
+// listing the files differing between the source and destination volumes.
+// Must be applied after the options of the transfer, the comparison deletes
+// and excludes the files as the transfer did. The other options of the
+// transfer are not used by the comparison.
+type rsyncVerifyOpts struct{}
+
+func (rsyncVerifyOpts) ApplyTo(opts *rsynctransfer.TransferOptions) error {
+	deleted := opts.Delete
+	excluded := append([]string{}, opts.ExcludeFiles...)
+	extras := []string{"--dry-run", "--checksum", "--itemize-changes"}
+	for _, opt := range opts.Extras {
+		name := strings.SplitN(opt, "=", 2)[0]
+		switch {
+		case name == "--exclude":
+			extras = append(extras, opt)
+		case strings.HasPrefix(name, "--delete"):
+			deleted = true
+		}
+	}
+	// the itemized changes are the only output
+	opts.CommandOptions = rsynctransfer.CommandOptions{
+		Recursive:    true,
+		SymLinks:     true,
+		Permissions:  true,
+		ModTimes:     true,
+		DeviceFiles:  true,
+		SpecialFiles: true,
+		Groups:       true,
+		Owners:       true,
+		HardLinks:    true,
+		Delete:       deleted,
+		ExcludeFiles: excluded,
+		Extras:       extras,
+	}
+	return nil
+}
+
+// runRsyncVerification runs a checksum dry run of the succeeded Rsync operations of the PVCs to verify.
+// Returns whether all verifications completed and the reasons of the PVCs not verified.
+func (t *Task) runRsyncVerification() (bool, []string, error) {
+	reasons := []string{}
+	srcClient, err := t.getSourceClient()
+	if err != nil {
+		return false, reasons, err
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return false, reasons, err
+	}
+	nsMap, err := t.getNamespacedPVCPairs()
+	if err != nil {
+		return false, reasons, err
+	}
+	pvcNodeMap, err := t.getPVCNodeNameMap(srcClient)
+	if err != nil {
+		return false, reasons, err
+	}
+	rsyncOptions, err := t.getRsyncTransferOptions()
+	if err != nil {
+		return false, reasons, err
+	}
+	transportOptions, err := t.getStunnelOptions()
+	if err != nil {
+		return false, reasons, err
+	}
+	verify := map[string]bool{}
+	for _, pvc := range t.Owner.Spec.PersistentVolumeClaims {
+		verify[pvc.Namespace+"/"+pvc.Name] = pvc.Verify
+	}
+
+	completed := true
+	for _, bothNs := range getSortedNamespaces(nsMap) {
+		srcNs := getSourceNs(bothNs)
+		destNs := getDestNs(bothNs)
+		mutations, err := t.getRsyncClientMutations(srcClient, destClient, srcNs)
+		if err != nil {
+			return false, reasons, err
+		}
+		nsOptions := append(append([]rsynctransfer.TransferOption{}, rsyncOptions...), mutations...)
+		nnPair := cranemeta.NewNamespacedPair(
+			types.NamespacedName{Name: DirectVolumeMigrationRsyncClient, Namespace: srcNs},
+			types.NamespacedName{Name: DirectVolumeMigrationRsyncClient, Namespace: destNs},
+		)
+		endpoint, err := t.getEndpoint(destClient, destNs)
+		if err != nil {
+			return false, reasons, err
+		}
+		stunnelTransport, err := stunneltransport.GetTransportFromKubeObjects(
+			srcClient, destClient, nnPair, endpoint, transportOptions)
+		if err != nil {
+			return false, reasons, err
+		}
+		for _, pvc := range nsMap[bothNs] {
+			claim := pvc.Source().Claim()
+			if !verify[claim.Namespace+"/"+claim.Name] {
+				continue
+			}
+			operation := t.Owner.Status.GetRsyncOperationStatusForPVC(
+				&corev1.ObjectReference{
+					Name:      claim.Name,
+					Namespace: claim.Namespace,
+				},
+			)
+			if !operation.Succeeded {
+				continue
+			}
+			if operation.Verification != nil && operation.Verification.Completed {
+				reasons = append(reasons, getRsyncVerificationReasons(operation)...)
+				continue
+			}
+			pod, err := t.getRsyncVerifyPod(srcClient, pvc)
+			if err != nil {
+				return false, reasons, err
+			}
+			if pod == nil {
+				completed = false
+				labels := t.buildDVMLabels()
+				labels[RsyncVerifyLabel] = pvc.Source().LabelSafeName()
+				clientPodMutation := rsynctransfer.SourcePodSpecMutation{
+					Spec: &corev1.PodSpec{
+						NodeName: pvcNodeMap[claim.Namespace+"/"+claim.Name],
+					},
+				}
+				if len(settings.Settings.DvmOpts.SourceSupplementalGroups) > 0 {
+					clientPodMutation.Spec.SecurityContext = &corev1.PodSecurityContext{
+						SupplementalGroups: settings.Settings.DvmOpts.SourceSupplementalGroups,
+					}
+				}
+				optionsForPvc := []rsynctransfer.TransferOption{&clientPodMutation}
+				optionsForPvc = append(optionsForPvc,
+					getRsyncOptionOverrides(t.getPVCRsyncOptions(claim.Name, claim.Namespace))...)
+				optionsForPvc = append(optionsForPvc,
+					rsyncVerifyOpts{}, rsynctransfer.WithSourcePodLabels(labels))
+				pvcList, err := transfer.NewPVCPairList(pvc)
+				if err != nil {
+					return false, reasons, err
+				}
+				verifyTransfer, err := rsynctransfer.NewTransfer(
+					stunnelTransport, endpoint, srcClient.RestConfig(), destClient.RestConfig(), pvcList, append(nsOptions, optionsForPvc...)...)
+				if err != nil {
+					return false, reasons, err
+				}
+				err = verifyTransfer.CreateClient(srcClient)
+				if err != nil {
+					return false, reasons, err
+				}
+				t.Log.Info("Created rsync verification pod for pvc", "pvc", operation)
+				continue
+			}
+			switch pod.Status.Phase {
+			case corev1.PodSucceeded:
+				count, files, err := t.getRsyncVerifyResult(srcClient, pod)
+				if err != nil {
+					return false, reasons, err
+				}
+				operation.Verification = &migapi.RsyncVerification{
+					Completed:       true,
+					MismatchedFiles: count,
+					Files:           files,
+				}
+			case corev1.PodFailed:
+				operation.Verification = &migapi.RsyncVerification{
+					Completed: true,
+					Error: fmt.Sprintf(
+						"rsync verification pod %s failed", path.Join(pod.Namespace, pod.Name)),
+				}
+			default:
+				completed = false
+				continue
+			}
+			t.Log.Info("Rsync verification completed for pvc",
+				"pvc", operation,
+				"mismatchedFiles", operation.Verification.MismatchedFiles,
+				"error", operation.Verification.Error)
+			reasons = append(reasons, getRsyncVerificationReasons(operation)...)
+		}
+	}
+	return completed, reasons, nil
+}
+
+// getRsyncVerificationReasons returns why the data of the PVC of a verified operation is not verified.
+func getRsyncVerificationReasons(operation *migapi.RsyncOperation) []string {
+	reasons := []string{}
+	if operation.Verification == nil {
+		return reasons
+	}
+	namespace, name := operation.GetPVDetails()
+	if operation.Verification.Error != "" {
+		reasons = append(reasons,
+			fmt.Sprintf("%s/%s: %s", namespace, name, operation.Verification.Error))
+	}
+	if operation.Verification.MismatchedFiles > 0 {
+		reasons = append(reasons,
+			fmt.Sprintf("%s/%s: %d mismatched files", namespace, name, operation.Verification.MismatchedFiles))
+	}
+	return reasons
+}
+
+// getRsyncVerifyPod returns the Rsync Pod verifying the data of a PVC, nil when not found.
+func (t *Task) getRsyncVerifyPod(client compat.Client, pvc transfer.PVCPair) (*corev1.Pod, error) {
+	labels := t.Owner.GetCorrelationLabels()
+	labels[RsyncVerifyLabel] = pvc.Source().LabelSafeName()
+	podList := corev1.PodList{}
+	err := client.List(context.TODO(),
+		&podList,
+		k8sclient.InNamespace(pvc.Source().Claim().Namespace),
+		k8sclient.MatchingLabels(labels),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(podList.Items) < 1 {
+		return nil, nil
+	}
+	return &podList.Items[0], nil
+}
+
+// getRsyncVerifyResult returns the number of mismatched files reported
+// by a completed Rsync verification Pod and the first mismatched files.
+func (t *Task) getRsyncVerifyResult(client compat.Client, pod *corev1.Pod) (int, []string, error) {
+	readCloser, err := getPodLogStream(client, pod, rsynctransfer.RsyncContainer, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer readCloser.Close()
+	return parseRsyncVerifyLog(readCloser, RsyncVerifyMaxFiles)
+}
+
+// parseRsyncVerifyLog counts the files listed by rsync --itemize-changes as
+// transferred or deleted, returns the count and up to limit file names.
+// The items only changing attributes or hard links are not mismatches.
+func parseRsyncVerifyLog(r io.Reader, limit int) (int, []string, error) {
+	count := 0
+	files := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// itemized lines are the change flags followed by the file name
+		fields := strings.SplitN(strings.TrimSpace(scanner.Text()), " ", 2)
+		if len(fields) < 2 || len(fields[0]) < 9 {
+			continue
+		}
+		if fields[0] != "*deleting" && !strings.ContainsRune("<>c", rune(fields[0][0])) {
+			continue
+		}
+		name := strings.TrimSpace(fields[1])
+		count++
+		if len(files) < limit {
+			files = append(files, name)
+		}
+	}
+	return count, files, scanner.Err()
+}
+
+// setRsyncVerificationFailed reports the PVCs not verified in a warning condition.
+// The final migrations of quiesced applications fail as the data must not change.
+// Returns whether the migration failed.
+func (t *Task) setRsyncVerificationFailed(reasons []string) (bool, error) {
+	migration, err := t.Owner.GetMigrationForDVM(t.Client)
+	if err != nil {
+		return false, err
+	}
+	category := Warn
+	failed := migration != nil && !migration.Spec.Stage && migration.Spec.QuiescePods
+	if failed {
+		category = Critical
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     RsyncVerificationFailed,
+		Status:   True,
+		Reason:   Failed,
+		Category: category,
+		Message:  RsyncVerificationFailedMessage,
+		Items:    reasons,
+		Durable:  true,
+	})
+	if failed {
+		t.fail(MigrationFailed, reasons)
+	}
+	return failed, nil
+}
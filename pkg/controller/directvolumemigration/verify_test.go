@@ -0,0 +1,272 @@
+package directvolumemigration
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	rsynctransfer "github.com/konveyor/crane-lib/state_transfer/transfer/rsync"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_parseRsyncVerifyLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		log       string
+		limit     int
+		wantCount int
+		wantFiles []string
+	}{
+		{
+			name:      "given no itemized changes, no mismatches are counted",
+			log:       "",
+			limit:     10,
+			wantCount: 0,
+			wantFiles: []string{},
+		},
+		{
+			name: "given changed, created and deleted files, mismatches are counted",
+			log: strings.Join([]string{
+				">fc........ data/file-1",
+				">f+++++++++ data/file 2",
+				"cd+++++++++ logs/",
+				"*deleting   data/stale",
+			}, "\n"),
+			limit:     10,
+			wantCount: 4,
+			wantFiles: []string{"data/file-1", "data/file 2", "logs/", "data/stale"},
+		},
+		{
+			name: "given attribute only changes and hard links, no mismatches are counted",
+			log: strings.Join([]string{
+				".d..t...... data/",
+				".f...p..... data/file-1",
+				"hf+++++++++ data/link => data/file-1",
+				"rsync: some warning",
+			}, "\n"),
+			limit:     10,
+			wantCount: 0,
+			wantFiles: []string{},
+		},
+		{
+			name: "given more mismatches than the limit, the first files are listed",
+			log: strings.Join([]string{
+				">fc........ file-1",
+				">fc........ file-2",
+				">fc........ file-3",
+			}, "\n"),
+			limit:     2,
+			wantCount: 3,
+			wantFiles: []string{"file-1", "file-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCount, gotFiles, err := parseRsyncVerifyLog(strings.NewReader(tt.log), tt.limit)
+			if err != nil {
+				t.Errorf("parseRsyncVerifyLog() unexpected error = %v", err)
+			}
+			if gotCount != tt.wantCount {
+				t.Errorf("parseRsyncVerifyLog() gotCount = %v, want %v", gotCount, tt.wantCount)
+			}
+			if !reflect.DeepEqual(gotFiles, tt.wantFiles) {
+				t.Errorf("parseRsyncVerifyLog() gotFiles = %v, want %v", gotFiles, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func Test_getRsyncVerificationReasons(t *testing.T) {
+	pvcRef := &corev1.ObjectReference{Namespace: "ns", Name: "pvc-0"}
+	tests := []struct {
+		name      string
+		operation *migapi.RsyncOperation
+		want      []string
+	}{
+		{
+			name:      "given no verification, no reasons are returned",
+			operation: &migapi.RsyncOperation{PVCReference: pvcRef},
+			want:      []string{},
+		},
+		{
+			name: "given a verification without mismatches, no reasons are returned",
+			operation: &migapi.RsyncOperation{
+				PVCReference: pvcRef,
+				Verification: &migapi.RsyncVerification{Completed: true},
+			},
+			want: []string{},
+		},
+		{
+			name: "given a verification with mismatches, the count is returned",
+			operation: &migapi.RsyncOperation{
+				PVCReference: pvcRef,
+				Verification: &migapi.RsyncVerification{Completed: true, MismatchedFiles: 3},
+			},
+			want: []string{"ns/pvc-0: 3 mismatched files"},
+		},
+		{
+			name: "given a failed verification, the error is returned",
+			operation: &migapi.RsyncOperation{
+				PVCReference: pvcRef,
+				Verification: &migapi.RsyncVerification{Completed: true, Error: "rsync verification pod ns/rsync-1 failed"},
+			},
+			want: []string{"ns/pvc-0: rsync verification pod ns/rsync-1 failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRsyncVerificationReasons(tt.operation); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRsyncVerificationReasons() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rsyncVerifyOpts(t *testing.T) {
+	bwLimit := 512
+	falseBool := false
+	tests := []struct {
+		name    string
+		options []rsynctransfer.TransferOption
+		want    []string
+	}{
+		{
+			name:    "given no transfer options, the verification options are used",
+			options: []rsynctransfer.TransferOption{},
+			want: []string{
+				"--recursive", "--links", "--perms", "--devices", "--specials", "--times", "--owner", "--group",
+				"--hard-links", "--dry-run", "--checksum", "--itemize-changes",
+			},
+		},
+		{
+			name: "given the default transfer options, the destination files are deleted and lost+found is excluded",
+			options: []rsynctransfer.TransferOption{
+				rsynctransfer.ArchiveFiles(true),
+				rsynctransfer.StandardProgress(true),
+				rsynctransfer.DeleteDestination(true),
+				rsynctransfer.ExcludeFiles{"lost+found"},
+				ExtraOpts{"--info=COPY2"},
+			},
+			want: []string{
+				"--recursive", "--links", "--perms", "--devices", "--specials", "--times", "--owner", "--group",
+				"--hard-links", "--delete", "--dry-run", "--checksum", "--itemize-changes", "--exclude=lost+found",
+			},
+		},
+		{
+			name: "given user options not deleting and excluding files, the comparison does not delete and excludes the files",
+			options: append([]rsynctransfer.TransferOption{
+				rsynctransfer.ArchiveFiles(true),
+				rsynctransfer.DeleteDestination(true),
+				rsynctransfer.ExcludeFiles{"lost+found"},
+			}, getRsyncOptionOverrides(&migapi.RsyncOptions{
+				BwLimit: &bwLimit,
+				Delete:  &falseBool,
+				Extras:  []string{"--exclude=*.tmp", "--size-only"},
+			})...),
+			want: []string{
+				"--recursive", "--links", "--perms", "--devices", "--specials", "--times", "--owner", "--group",
+				"--hard-links", "--dry-run", "--checksum", "--itemize-changes", "--exclude=*.tmp", "--exclude=lost+found",
+			},
+		},
+		{
+			name: "given a delete extra option, the destination files are deleted",
+			options: []rsynctransfer.TransferOption{
+				rsynctransfer.DeleteDestination(false),
+				ExtraOpts{"--delete-after"},
+			},
+			want: []string{
+				"--recursive", "--links", "--perms", "--devices", "--specials", "--times", "--owner", "--group",
+				"--hard-links", "--delete", "--dry-run", "--checksum", "--itemize-changes",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := rsynctransfer.TransferOptions{}
+			err := opts.Apply(append(tt.options, rsyncVerifyOpts{})...)
+			if err != nil {
+				t.Fatalf("rsyncVerifyOpts() unexpected error: %v", err)
+			}
+			got, err := opts.AsRsyncCommandOptions()
+			if err != nil {
+				t.Fatalf("rsyncVerifyOpts() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rsyncVerifyOpts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_setRsyncVerificationFailed(t *testing.T) {
+	migration := func(stage, quiesce bool) *migapi.MigMigration {
+		return &migapi.MigMigration{
+			ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: migapi.OpenshiftMigrationNamespace},
+			Spec:       migapi.MigMigrationSpec{Stage: stage, QuiescePods: quiesce},
+		}
+	}
+	tests := []struct {
+		name         string
+		migration    *migapi.MigMigration
+		wantFailed   bool
+		wantCategory string
+	}{
+		{
+			name:         "given no migration, a warning is reported",
+			wantCategory: Warn,
+		},
+		{
+			name:         "given a stage migration, a warning is reported",
+			migration:    migration(true, false),
+			wantCategory: Warn,
+		},
+		{
+			name:         "given a final migration not quiescing the applications, a warning is reported",
+			migration:    migration(false, false),
+			wantCategory: Warn,
+		},
+		{
+			name:         "given a final migration quiescing the applications, the dvm fails",
+			migration:    migration(false, true),
+			wantFailed:   true,
+			wantCategory: Critical,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []k8sclient.Object{}
+			if tt.migration != nil {
+				objects = append(objects, tt.migration)
+			}
+			task := &Task{
+				Client: getFakeCompatClient(objects...),
+				Owner: &migapi.DirectVolumeMigration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "dvm",
+						Namespace: migapi.OpenshiftMigrationNamespace,
+						OwnerReferences: []metav1.OwnerReference{
+							{APIVersion: "migration.openshift.io/v1alpha1", Kind: "MigMigration", Name: "migration"},
+						},
+					},
+				},
+			}
+			failed, err := task.setRsyncVerificationFailed([]string{"ns/pvc: 1 mismatched files"})
+			if err != nil {
+				t.Fatalf("setRsyncVerificationFailed() unexpected error: %v", err)
+			}
+			if failed != tt.wantFailed {
+				t.Errorf("setRsyncVerificationFailed() = %v, want %v", failed, tt.wantFailed)
+			}
+			condition := task.Owner.Status.FindCondition(RsyncVerificationFailed)
+			if condition == nil || condition.Category != tt.wantCategory {
+				t.Errorf("setRsyncVerificationFailed() condition = %v, want category %v", condition, tt.wantCategory)
+			}
+			if task.Owner.HasErrors() != tt.wantFailed {
+				t.Errorf("setRsyncVerificationFailed() errors = %v, want failed %v", task.Owner.Status.Errors, tt.wantFailed)
+			}
+		})
+	}
+}
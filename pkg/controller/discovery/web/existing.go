@@ -0,0 +1,87 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/konveyor/mig-controller/pkg/existing"
+	"github.com/konveyor/mig-controller/pkg/gvk"
+)
+
+// Report the resources of the plan namespaces already existing
+// in the destination namespaces.
+func (h PlanHandler) Existing(ctx *gin.Context) {
+	status := h.Prepare(ctx)
+	if status != http.StatusOK {
+		ctx.Status(status)
+		return
+	}
+	content := PlanExisting{}
+	status = content.With(&h)
+	if status != http.StatusOK {
+		ctx.Status(status)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, content)
+}
+
+// Plan existing resources REST resource.
+type PlanExisting struct {
+	// The plan k8s namespace.
+	Namespace string `json:"namespace"`
+	// The plan k8s name.
+	Name string `json:"name"`
+	// The plan existing resource policy.
+	Policy string `json:"policy"`
+	// The number of existing resources.
+	Count int64 `json:"count"`
+	// The number of existing resources not restored by the plan.
+	Conflicts int64 `json:"conflicts"`
+	// Existing resources.
+	Items []existing.Resource `json:"resources"`
+}
+
+// Build the resource.
+func (r *PlanExisting) With(h *PlanHandler) int {
+	r.Namespace = h.plan.Namespace
+	r.Name = h.plan.Name
+	r.Items = []existing.Resource{}
+	plan := h.plan.DecodeObject()
+	r.Policy = plan.Spec.GetExistingResourcePolicy()
+	srcCfg, status := h.clusterRestCfg(plan.Spec.SrcMigClusterRef)
+	if status != http.StatusOK {
+		return status
+	}
+	destCfg, status := h.clusterRestCfg(plan.Spec.DestMigClusterRef)
+	if status != http.StatusOK {
+		return status
+	}
+	srcClient, GVRs, err := gvk.GetNamespacedGVRsForConfig(srcCfg)
+	if err != nil {
+		Log.Error(err, "")
+		return http.StatusInternalServerError
+	}
+	destClient, _, err := gvk.GetNamespacedGVRsForConfig(destCfg)
+	if err != nil {
+		Log.Error(err, "")
+		return http.StatusInternalServerError
+	}
+	finder := existing.Finder{
+		Source:      srcClient,
+		Destination: destClient,
+		GVRs:        GVRs,
+		Plan:        plan,
+		Filters:     plan.Spec.ResourceFilters,
+	}
+	r.Items, err = finder.Find()
+	if err != nil {
+		Log.Error(err, "")
+		return http.StatusInternalServerError
+	}
+	r.Count = int64(len(r.Items))
+	r.Conflicts = int64(len(existing.Conflicts(r.Items)))
+	h.page.Slice(&r.Items)
+
+	return http.StatusOK
+}
@@ -1,18 +1,23 @@
 package web
 
 import (
+	"bufio"
 	"database/sql"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/konveyor/mig-controller/pkg/controller/discovery/model"
 	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 )
 
 const (
 	JobParam = "job"
 	JobsRoot = NamespaceRoot + "/jobs"
 	JobRoot  = JobsRoot + "/:" + JobParam
+	// Logs of the pods created for a job.
+	JobLogRoot = JobRoot + "/log"
 )
 
 // Job (route) handler.
@@ -140,3 +145,147 @@ type JobList struct {
 	// List of resources.
 	Items []Job `json:"resources"`
 }
+
+// Job-log (route) handler.
+type JobLogHandler struct {
+	// Base
+	LogHandler
+}
+
+// Add routes.
+func (h JobLogHandler) AddRoutes(r *gin.Engine) {
+	r.GET(JobLogRoot, h.List)
+}
+
+// Not supported.
+func (h JobLogHandler) Get(ctx *gin.Context) {
+	ctx.Status(http.StatusMethodNotAllowed)
+}
+
+// List the logs of the pods created for a job.
+// Supports the same parameters as the pod log.
+func (h JobLogHandler) List(ctx *gin.Context) {
+	status := h.Prepare(ctx)
+	if status != http.StatusOK {
+		ctx.Status(status)
+		return
+	}
+	db := h.container.Db
+	job := model.Job{
+		Base: model.Base{
+			Cluster:   h.cluster.PK,
+			Namespace: ctx.Param(Ns2Param),
+			Name:      ctx.Param(JobParam),
+		},
+	}
+	err := job.Get(db)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			Log.Error(err, "")
+			ctx.Status(http.StatusInternalServerError)
+			return
+		} else {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+	}
+	collection := model.Pod{
+		Base: model.Base{
+			Cluster:   h.cluster.PK,
+			Namespace: job.Namespace,
+		},
+	}
+	list, err := collection.List(db, model.ListOptions{
+		Labels: model.Labels{
+			"job-name": job.Name,
+		},
+	})
+	if err != nil {
+		Log.Error(err, "")
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	options, status := h.buildOptions(ctx)
+	if status != http.StatusOK {
+		ctx.Status(status)
+		return
+	}
+	content := []JobPodLog{}
+	for _, pod := range list {
+		podClient, status := h.buildClient(pod)
+		if status != http.StatusOK {
+			ctx.Status(status)
+			return
+		}
+		r := JobPodLog{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Container: options.Container,
+			SelfLink:  LogHandler{}.Link(&h.cluster, pod),
+		}
+		stream, err := podClient.GetLogs(pod.Name, options).Stream(ctx)
+		if err != nil {
+			stErr, cast := err.(*errors.StatusError)
+			if !cast {
+				Log.Error(err, "")
+				ctx.Status(http.StatusInternalServerError)
+				return
+			}
+			// The pod may not have started or may have been deleted.
+			r.Error = stErr.ErrStatus.Message
+			content = append(content, r)
+			continue
+		}
+		r.Log = h.readLines(stream)
+		stream.Close()
+		content = append(content, r)
+	}
+
+	ctx.JSON(http.StatusOK, content)
+}
+
+// Build self link.
+func (h JobLogHandler) Link(c *model.Cluster, m *model.Job) string {
+	return h.BaseHandler.Link(
+		JobLogRoot,
+		Params{
+			NsParam:      c.Namespace,
+			ClusterParam: c.Name,
+			Ns2Param:     m.Namespace,
+			JobParam:     m.Name,
+		})
+}
+
+// Read the log entries within the page.
+func (h *JobLogHandler) readLines(stream io.Reader) []string {
+	lines := []string{}
+	ln := 0
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		if len(lines) >= h.page.Limit {
+			break
+		}
+		if ln >= h.page.Offset {
+			lines = append(lines, scanner.Text())
+		}
+		ln++
+	}
+
+	return lines
+}
+
+// Job pod log REST resource.
+type JobPodLog struct {
+	// The pod namespace.
+	Namespace string `json:"namespace"`
+	// The pod name.
+	Name string `json:"name"`
+	// The (optional) container.
+	Container string `json:"container,omitempty"`
+	// The pod log URI.
+	SelfLink string `json:"selfLink"`
+	// The log entries.
+	Log []string `json:"log"`
+	// Why the log could not be read.
+	Error string `json:"error,omitempty"`
+}
@@ -12,9 +12,10 @@ import (
 
 // Migration route root.
 const (
-	MigrationParam = "migration"
-	MigrationsRoot = Root + "/migrations"
-	MigrationRoot  = MigrationsRoot + "/:" + MigrationParam
+	MigrationParam      = "migration"
+	MigrationsRoot      = Root + "/migrations"
+	MigrationRoot       = MigrationsRoot + "/:" + MigrationParam
+	MigrationReportRoot = MigrationRoot + "/report"
 )
 
 // Migration (route) handler.
@@ -30,6 +31,7 @@ func (h MigrationHandler) AddRoutes(r *gin.Engine) {
 	r.GET(MigrationsRoot, h.List)
 	r.GET(MigrationsRoot+"/", h.List)
 	r.GET(MigrationRoot, h.Get)
+	r.GET(MigrationReportRoot, h.Report)
 }
 
 // Prepare to fulfil the request.
@@ -13,6 +13,7 @@ import (
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	auth "k8s.io/api/authorization/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
 )
 
 // Plan route root.
@@ -24,6 +25,8 @@ const (
 	TreeMigrationParam    = "migration"
 	PlanTreeRoot          = PlanRoot + "/tree"
 	PlanTreeMigrationRoot = PlanTreeRoot + "/:" + TreeMigrationParam
+	PlanTransformsRoot    = PlanRoot + "/transforms"
+	PlanExistingRoot      = PlanRoot + "/existing"
 )
 
 // Plan (route) handler.
@@ -44,6 +47,8 @@ func (h PlanHandler) AddRoutes(r *gin.Engine) {
 	r.GET(PlanRoot+"/pods", h.Pods)
 	r.GET(PlanTreeRoot, h.Tree)
 	r.GET(PlanTreeMigrationRoot, h.Tree)
+	r.GET(PlanTransformsRoot, h.Transforms)
+	r.GET(PlanExistingRoot, h.Existing)
 }
 
 // Prepare to fulfil the request.
@@ -101,6 +106,32 @@ func (h *PlanHandler) Prepare(ctx *gin.Context) int {
 	return http.StatusOK
 }
 
+// Get the REST configuration of a cluster referenced by the plan.
+func (h *PlanHandler) clusterRestCfg(ref *v1.ObjectReference) (*rest.Config, int) {
+	if ref == nil {
+		return nil, http.StatusNotFound
+	}
+	cluster := model.Cluster{
+		CR: model.CR{
+			Namespace: ref.Namespace,
+			Name:      ref.Name,
+		},
+	}
+	err := cluster.Get(h.container.Db)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			Log.Error(err, "")
+			return nil, http.StatusInternalServerError
+		}
+		return nil, http.StatusNotFound
+	}
+	ds, found := h.container.GetDs(&cluster)
+	if !found {
+		return nil, http.StatusNotFound
+	}
+	return ds.RestCfg, http.StatusOK
+}
+
 // Build the appropriate SAR object.
 // The subject is the MigPlan.
 func (h *PlanHandler) getSAR() auth.SelfSubjectAccessReview {
@@ -244,6 +275,7 @@ type TreeNode struct {
 	ClusterType string     `json:"clusterType"`
 	Children    []TreeNode `json:"children,omitempty"`
 	ObjectLink  string     `json:"objectLink"`
+	LogLink     string     `json:"logLink,omitempty"`
 }
 
 // Plan Tree.
@@ -756,6 +788,7 @@ func (t *PlanTree) addHookJobsForCluster(hook *model.Hook, migration *model.Migr
 		node := TreeNode{
 			Kind:        migref.ToKind(m),
 			ObjectLink:  JobHandler{}.Link(&cluster, m),
+			LogLink:     JobLogHandler{}.Link(&cluster, m),
 			Namespace:   m.Namespace,
 			Name:        m.Name,
 			ClusterType: t.clusterType(&cluster),
@@ -791,6 +824,7 @@ func (t *PlanTree) addJobPodsForCluster(cluster model.Cluster, job *model.Job, p
 		node := TreeNode{
 			Kind:        migref.ToKind(m),
 			ObjectLink:  PodHandler{}.Link(&cluster, m),
+			LogLink:     LogHandler{}.Link(&cluster, m),
 			Namespace:   m.Namespace,
 			Name:        m.Name,
 			ClusterType: t.clusterType(&cluster),
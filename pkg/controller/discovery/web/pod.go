@@ -136,6 +136,18 @@ func (h LogHandler) AddRoutes(r *gin.Engine) {
 	r.GET(LogRoot, h.List)
 }
 
+// Build self link.
+func (h LogHandler) Link(c *model.Cluster, m *model.Pod) string {
+	return h.BaseHandler.Link(
+		LogRoot,
+		Params{
+			NsParam:      c.Namespace,
+			ClusterParam: c.Name,
+			Ns2Param:     m.Namespace,
+			PodParam:     m.Name,
+		})
+}
+
 // Not supported.
 func (h LogHandler) Get(ctx *gin.Context) {
 	ctx.Status(http.StatusMethodNotAllowed)
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Migration report formats.
+const (
+	ReportJSON     = "json"
+	ReportMarkdown = "markdown"
+)
+
+// Download the report of a completed migration.
+// The format is selected by the `format` query parameter: json (default) or markdown.
+func (h MigrationHandler) Report(ctx *gin.Context) {
+	status := h.Prepare(ctx)
+	if status != http.StatusOK {
+		ctx.Status(status)
+		return
+	}
+	var key, contentType string
+	switch ctx.Request.URL.Query().Get("format") {
+	case "", ReportJSON:
+		key = migapi.MigrationReportJSONKey
+		contentType = "application/json; charset=utf-8"
+	case ReportMarkdown:
+		key = migapi.MigrationReportMarkdownKey
+		contentType = "text/markdown; charset=utf-8"
+	default:
+		ctx.Status(http.StatusBadRequest)
+		return
+	}
+	migration := h.migration.DecodeObject()
+	cm := &v1.ConfigMap{}
+	err := h.container.Client.Get(
+		context.TODO(),
+		client.ObjectKey{
+			Namespace: migration.Namespace,
+			Name:      migration.GetReportConfigMapName(),
+		},
+		cm)
+	if err != nil {
+		if k8serror.IsNotFound(err) {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+		Log.Error(err, "")
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	content, found := cm.Data[key]
+	if !found {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename="+migration.Name+"-"+key)
+	ctx.Data(http.StatusOK, contentType, []byte(content))
+}
@@ -0,0 +1,162 @@
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/transform"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Preview the restore transforms of the plan.
+// The transforms are applied to the resources on the source cluster,
+// the resources on the source cluster are unchanged.
+func (h PlanHandler) Transforms(ctx *gin.Context) {
+	status := h.Prepare(ctx)
+	if status != http.StatusOK {
+		ctx.Status(status)
+		return
+	}
+	content := PlanTransforms{}
+	status = content.With(&h)
+	if status != http.StatusOK {
+		ctx.Status(status)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, content)
+}
+
+// Plan transforms REST resource.
+type PlanTransforms struct {
+	// The plan k8s namespace.
+	Namespace string `json:"namespace"`
+	// The plan k8s name.
+	Name string `json:"name"`
+	// The number of transformed resources.
+	Count int64 `json:"count"`
+	// Transformed resources.
+	Items []TransformedResource `json:"resources"`
+}
+
+// Transformed resource.
+type TransformedResource struct {
+	// The transform name.
+	Transform string `json:"transform"`
+	// The resource kind.
+	Kind string `json:"kind"`
+	// The source k8s namespace.
+	Namespace string `json:"namespace"`
+	// The destination k8s namespace.
+	DestinationNamespace string `json:"destinationNamespace"`
+	// The k8s name.
+	Name string `json:"name"`
+	// The transformed manifest.
+	Object *unstructured.Unstructured `json:"object,omitempty"`
+	// Why the transform failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Build the resource.
+// Lists the resources selected by the transforms on the source cluster
+// and applies the transforms in order.
+func (r *PlanTransforms) With(h *PlanHandler) int {
+	r.Namespace = h.plan.Namespace
+	r.Name = h.plan.Name
+	r.Items = []TransformedResource{}
+	plan := h.plan.DecodeObject()
+	if plan.Spec.SrcMigClusterRef == nil || len(plan.Spec.Transforms) == 0 {
+		return http.StatusOK
+	}
+	restCfg, status := h.clusterRestCfg(plan.Spec.SrcMigClusterRef)
+	if status != http.StatusOK {
+		return status
+	}
+	client, err := dynamic.NewForConfig(restCfg)
+	if err != nil {
+		Log.Error(err, "")
+		return http.StatusInternalServerError
+	}
+	mapper, err := apiutil.NewDynamicRESTMapper(restCfg)
+	if err != nil {
+		Log.Error(err, "")
+		return http.StatusInternalServerError
+	}
+	transformed := map[string]*TransformedResource{}
+	order := []string{}
+	mapping := plan.GetNamespaceMapping()
+	for i := range plan.Spec.Transforms {
+		rule := &plan.Spec.Transforms[i]
+		restMapping, err := mapper.RESTMapping(rule.GroupKind())
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			Log.Error(err, "")
+			return http.StatusInternalServerError
+		}
+		for _, ns := range plan.GetSourceNamespaces() {
+			if !rule.AppliesTo(ns) {
+				continue
+			}
+			list, err := client.Resource(restMapping.Resource).Namespace(ns).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				Log.Error(err, "")
+				return http.StatusInternalServerError
+			}
+			for j := range list.Items {
+				object := &list.Items[j]
+				selected, err := transform.Selects(rule, ns, object)
+				if err != nil || !selected {
+					continue
+				}
+				key := restMapping.Resource.String() + "/" + ns + "/" + object.GetName()
+				resource, found := transformed[key]
+				if !found {
+					resource = &TransformedResource{
+						Kind:                 rule.Kind,
+						Namespace:            ns,
+						DestinationNamespace: mapping[ns],
+						Name:                 object.GetName(),
+						Object:               object,
+					}
+					transformed[key] = resource
+					order = append(order, key)
+				}
+				r.apply(resource, rule)
+			}
+		}
+	}
+	for _, key := range order {
+		r.Items = append(r.Items, *transformed[key])
+	}
+	r.Count = int64(len(r.Items))
+	h.page.Slice(&r.Items)
+
+	return http.StatusOK
+}
+
+// Apply a transform to the resource.
+// A failed transform is reported and the next transforms are not applied.
+func (r *PlanTransforms) apply(resource *TransformedResource, rule *migapi.RestoreTransform) {
+	if resource.Error != "" {
+		return
+	}
+	if resource.Transform != "" {
+		resource.Transform += ","
+	}
+	resource.Transform += rule.Name
+	object, err := transform.Apply(rule, resource.Object)
+	if err != nil {
+		resource.Error = err.Error()
+		resource.Object = nil
+		return
+	}
+	resource.Object = object
+}
@@ -143,6 +143,15 @@ func (w *WebServer) addRoutes(r *gin.Engine) {
 				},
 			},
 		},
+		JobLogHandler{
+			LogHandler: LogHandler{
+				ClusterScoped: ClusterScoped{
+					BaseHandler: BaseHandler{
+						container: w.Container,
+					},
+				},
+			},
+		},
 		EventHandler{
 			BaseHandler: BaseHandler{
 				container: w.Container,
@@ -128,9 +128,16 @@ func (r *ReconcileMigHook) Reconcile(ctx context.Context, request reconcile.Requ
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	// Pre-flight validation on the cluster.
+	preflightRunning, err := r.validatePreflight(ctx, hook)
+	if err != nil {
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
 	// Ready
 	hook.Status.SetReady(
-		!hook.Status.HasBlockerCondition(),
+		!hook.Status.HasBlockerCondition() && !preflightRunning,
 		"The hook is ready.")
 
 	// End staging conditions.
@@ -144,6 +151,11 @@ func (r *ReconcileMigHook) Reconcile(ctx context.Context, request reconcile.Requ
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	// Wait for the pre-flight validation.
+	if preflightRunning {
+		return reconcile.Result{RequeueAfter: PreflightPollInterval}, nil
+	}
+
 	// Done
 	return reconcile.Result{Requeue: false}, nil
 }
@@ -0,0 +1,488 @@
+package mighook
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"github.com/opentracing/opentracing-go"
+	authv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Types
+const (
+	InvalidPreflight                = "InvalidPreflight"
+	PreflightRunning                = "PreflightRunning"
+	PreflightSucceeded              = "PreflightSucceeded"
+	PreflightServiceAccountNotFound = "PreflightServiceAccountNotFound"
+	PreflightPermissionDenied       = "PreflightPermissionDenied"
+	PreflightImageNotPullable       = "PreflightImageNotPullable"
+	PreflightPlaybookInvalid        = "PreflightPlaybookInvalid"
+	PreflightFailed                 = "PreflightFailed"
+)
+
+// Labels on the resources created on the cluster by the pre-flight validation.
+const (
+	PreflightLabel           = "mighook-preflight"
+	PreflightGenerationLabel = "mighook-preflight-generation"
+)
+
+// Pre-flight validation timing.
+const (
+	PreflightPollInterval    = 10 * time.Second
+	PreflightDeadlineSeconds = int64(300)
+	PreflightLogTailLines    = int64(10)
+)
+
+// Reasons reported for a container whose image cannot be pulled.
+var imagePullErrors = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// Reasons reported for a waiting container whose image has been pulled
+// but whose command cannot be run.
+var containerStartErrors = map[string]bool{
+	"CreateContainerError": true,
+	"RunContainerError":    true,
+}
+
+// Validate the hook on the cluster referenced by the pre-flight spec.
+// The validation is performed once for each generation of the hook and
+// the outcome is recorded in durable conditions.
+// Returns: `true` when the validation is still running.
+func (r ReconcileMigHook) validatePreflight(ctx context.Context, hook *migapi.MigHook) (bool, error) {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validatePreflight")
+		defer span.Finish()
+	}
+	preflight := hook.Spec.Preflight
+	if preflight == nil {
+		deletePreflightConditions(hook)
+		hook.Status.PreflightGeneration = 0
+		return false, nil
+	}
+	if hook.Status.PreflightGeneration == hook.Generation {
+		return false, nil
+	}
+	deletePreflightConditions(hook)
+	if hook.Status.HasBlockerCondition() {
+		return false, nil
+	}
+	if preflight.ClusterRef == nil || preflight.Namespace == "" || preflight.ServiceAccount == "" {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidPreflight,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "The clusterRef, namespace and serviceAccount must be specified in spec.preflight.",
+		})
+		return false, nil
+	}
+	cluster, err := migapi.GetCluster(r, preflight.ClusterRef)
+	if err != nil {
+		return false, err
+	}
+	if cluster == nil {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidPreflight,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message:  "The cluster referenced in spec.preflight.clusterRef is not found.",
+		})
+		return false, nil
+	}
+	if !cluster.Status.IsReady() {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     PreflightRunning,
+			Status:   True,
+			Reason:   migapi.NotReady,
+			Category: Advisory,
+			Message:  "The pre-flight validation is waiting for the cluster to be ready.",
+		})
+		return true, nil
+	}
+	client, err := cluster.GetClient(r)
+	if err != nil {
+		return false, err
+	}
+
+	// ServiceAccount.
+	sa := corev1.ServiceAccount{}
+	err = client.Get(
+		context.TODO(),
+		k8sclient.ObjectKey{
+			Namespace: preflight.Namespace,
+			Name:      preflight.ServiceAccount,
+		},
+		&sa)
+	if err != nil {
+		if !k8serror.IsNotFound(err) {
+			return false, err
+		}
+		return false, r.endPreflight(client, hook, migapi.Condition{
+			Type:     PreflightServiceAccountNotFound,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message: fmt.Sprintf("The service account %s is not found on cluster %s.",
+				path.Join(preflight.Namespace, preflight.ServiceAccount), cluster.Name),
+		})
+	}
+
+	// Permissions.
+	denied, err := deniedPermissions(client, preflight)
+	if err != nil {
+		return false, err
+	}
+	if len(denied) > 0 {
+		return false, r.endPreflight(client, hook, migapi.Condition{
+			Type:     PreflightPermissionDenied,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: fmt.Sprintf("The service account %s is not granted the permissions: []",
+				path.Join(preflight.Namespace, preflight.ServiceAccount)),
+			Items: denied,
+		})
+	}
+
+	// Exec hooks have no image.
+	if hook.Spec.Exec != nil {
+		return false, r.endPreflight(client, hook, preflightSucceeded())
+	}
+
+	// Image and playbook.
+	job, err := r.ensurePreflightJob(client, hook)
+	if err != nil {
+		return false, err
+	}
+	podList := corev1.PodList{}
+	err = client.List(
+		context.TODO(),
+		&podList,
+		k8sclient.InNamespace(job.Namespace),
+		k8sclient.MatchingLabels{"job-name": job.Name})
+	if err != nil {
+		return false, err
+	}
+	pulled := false
+	for _, pod := range podList.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			imagePulled, reason, message := imagePullState(status.State)
+			if reason != "" {
+				return false, r.endPreflight(client, hook, migapi.Condition{
+					Type:     PreflightImageNotPullable,
+					Status:   True,
+					Reason:   reason,
+					Category: Critical,
+					Message: fmt.Sprintf("The image %s cannot be pulled on cluster %s: %s",
+						hook.Spec.Image, cluster.Name, message),
+				})
+			}
+			pulled = pulled || imagePulled
+		}
+	}
+	switch {
+	case job.Status.Succeeded > 0:
+		return false, r.endPreflight(client, hook, preflightSucceeded())
+	case hook.Spec.Custom && pulled:
+		// Custom images are only pulled, the command may not exist in the image.
+		return false, r.endPreflight(client, hook, preflightSucceeded())
+	case job.Status.Failed > 0 && !hook.Spec.Custom:
+		log, err := preflightLogTail(cluster, r, podList.Items)
+		if err != nil {
+			return false, err
+		}
+		return false, r.endPreflight(client, hook, migapi.Condition{
+			Type:     PreflightPlaybookInvalid,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  "The playbook failed the ansible-playbook syntax check: " + log,
+		})
+	case job.Status.Failed > 0 || jobFailed(job):
+		return false, r.endPreflight(client, hook, migapi.Condition{
+			Type:     PreflightFailed,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: fmt.Sprintf("The pre-flight validation job %s failed on cluster %s.",
+				path.Join(job.Namespace, job.Name), cluster.Name),
+		})
+	}
+	hook.Status.SetCondition(migapi.Condition{
+		Type:     PreflightRunning,
+		Status:   True,
+		Category: Advisory,
+		Message: fmt.Sprintf("The pre-flight validation job %s is running on cluster %s.",
+			path.Join(job.Namespace, job.Name), cluster.Name),
+	})
+	return true, nil
+}
+
+// Record the outcome of the pre-flight validation of the current
+// generation and delete the resources created on the cluster.
+func (r ReconcileMigHook) endPreflight(client compat.Client, hook *migapi.MigHook, condition migapi.Condition) error {
+	condition.Durable = true
+	hook.Status.SetCondition(condition)
+	hook.Status.PreflightGeneration = hook.Generation
+	labels := hook.GetCorrelationLabels()
+	labels[PreflightLabel] = "true"
+	options := []k8sclient.DeleteAllOfOption{
+		k8sclient.InNamespace(hook.Spec.Preflight.Namespace),
+		k8sclient.MatchingLabels(labels),
+	}
+	err := client.DeleteAllOf(
+		context.TODO(),
+		&batchv1.Job{},
+		append(options, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground))...)
+	if err != nil {
+		return err
+	}
+	return client.DeleteAllOf(context.TODO(), &corev1.ConfigMap{}, options...)
+}
+
+// Get the pre-flight validation job of the current generation.
+// The job is created when not found.
+func (r ReconcileMigHook) ensurePreflightJob(client compat.Client, hook *migapi.MigHook) (*batchv1.Job, error) {
+	preflight := hook.Spec.Preflight
+	labels := hook.GetCorrelationLabels()
+	labels[PreflightLabel] = "true"
+	labels[PreflightGenerationLabel] = strconv.FormatInt(hook.Generation, 10)
+	list := batchv1.JobList{}
+	err := client.List(
+		context.TODO(),
+		&list,
+		k8sclient.InNamespace(preflight.Namespace),
+		k8sclient.MatchingLabels(labels))
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) > 0 {
+		return &list.Items[0], nil
+	}
+	deadlineSeconds := PreflightDeadlineSeconds
+	backoffLimit := int32(0)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    preflight.Namespace,
+			GenerateName: strings.ToLower(hook.Name + "-preflight-"),
+			Labels:       labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &deadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:    "preflight",
+							Image:   hook.Spec.Image,
+							Command: []string{"true"},
+						},
+					},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: preflight.ServiceAccount,
+					NodeSelector:       hook.Spec.NodeSelector,
+					Tolerations:        hook.Spec.Tolerations,
+				},
+			},
+		},
+	}
+	if !hook.Spec.Custom {
+		playbook, err := base64.StdEncoding.DecodeString(hook.Spec.Playbook)
+		if err != nil {
+			return nil, err
+		}
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    preflight.Namespace,
+				GenerateName: strings.ToLower(hook.Name + "-preflight-"),
+				Labels:       labels,
+			},
+			Data: map[string]string{
+				"playbook.yml": string(playbook),
+			},
+		}
+		err = client.Create(context.TODO(), configMap)
+		if err != nil {
+			return nil, err
+		}
+		container := &job.Spec.Template.Spec.Containers[0]
+		container.Command = []string{
+			"ansible-playbook",
+			"--syntax-check",
+			"/tmp/playbook/playbook.yml",
+		}
+		container.VolumeMounts = []corev1.VolumeMount{
+			{
+				Name:      "playbook",
+				MountPath: "/tmp/playbook",
+			},
+		}
+		job.Spec.Template.Spec.Volumes = []corev1.Volume{
+			{
+				Name: "playbook",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: configMap.Name,
+						},
+					},
+				},
+			},
+		}
+	}
+	log.Info("Creating pre-flight validation job for MigHook.",
+		"job", path.Join(job.Namespace, job.GenerateName))
+	err = client.Create(context.TODO(), job)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// List the permissions not granted to the service account.
+func deniedPermissions(client compat.Client, preflight *migapi.MigHookPreflight) ([]string, error) {
+	denied := []string{}
+	user := fmt.Sprintf("system:serviceaccount:%s:%s", preflight.Namespace, preflight.ServiceAccount)
+	for i := range preflight.Permissions {
+		attributes := preflight.Permissions[i]
+		review := &authv1.SubjectAccessReview{
+			Spec: authv1.SubjectAccessReviewSpec{
+				ResourceAttributes: &attributes,
+				User:               user,
+				Groups: []string{
+					"system:serviceaccounts",
+					"system:serviceaccounts:" + preflight.Namespace,
+				},
+			},
+		}
+		err := client.Create(context.TODO(), review)
+		if err != nil {
+			return nil, err
+		}
+		if !review.Status.Allowed {
+			denied = append(denied, describePermission(attributes))
+		}
+	}
+	return denied, nil
+}
+
+// Describe a permission as: verb group/resource[/subresource] [namespace/name].
+func describePermission(attributes authv1.ResourceAttributes) string {
+	resource := attributes.Resource
+	if attributes.Group != "" {
+		resource = attributes.Group + "/" + resource
+	}
+	if attributes.Subresource != "" {
+		resource += "/" + attributes.Subresource
+	}
+	description := attributes.Verb + " " + resource
+	if target := path.Join(attributes.Namespace, attributes.Name); target != "" {
+		description += " " + target
+	}
+	return description
+}
+
+// Get whether the image of a pre-flight container has been pulled.
+// The reason and message are set when the image cannot be pulled. A container
+// running or terminated for another reason has pulled its image, including a
+// container whose command cannot be run by the runtime.
+func imagePullState(state corev1.ContainerState) (bool, string, string) {
+	switch {
+	case state.Waiting != nil:
+		if imagePullErrors[state.Waiting.Reason] {
+			return false, state.Waiting.Reason, state.Waiting.Message
+		}
+		if containerStartErrors[state.Waiting.Reason] {
+			return true, "", ""
+		}
+	case state.Running != nil:
+		return true, "", ""
+	case state.Terminated != nil:
+		terminated := state.Terminated
+		if imagePullErrors[terminated.Reason] {
+			return false, terminated.Reason, terminated.Message
+		}
+		return true, "", ""
+	}
+	return false, "", ""
+}
+
+// Get whether the job reported a failure.
+func jobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// Read the tail of the log of the pre-flight validation pods.
+func preflightLogTail(cluster *migapi.MigCluster, client k8sclient.Client, pods []corev1.Pod) (string, error) {
+	restCfg, err := cluster.BuildRestConfig(client)
+	if err != nil {
+		return "", err
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return "", err
+	}
+	lines := []string{}
+	tailLines := PreflightLogTailLines
+	for _, pod := range pods {
+		content, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			TailLines: &tailLines,
+		}).DoRaw(context.TODO())
+		if err != nil {
+			return "", err
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, strings.TrimSpace(line))
+			}
+		}
+	}
+	return strings.Join(lines, " "), nil
+}
+
+// Build the condition reporting a successful pre-flight validation.
+func preflightSucceeded() migapi.Condition {
+	return migapi.Condition{
+		Type:     PreflightSucceeded,
+		Status:   True,
+		Category: Advisory,
+		Message:  "The pre-flight validation succeeded.",
+	}
+}
+
+// Delete the conditions set by the pre-flight validation.
+func deletePreflightConditions(hook *migapi.MigHook) {
+	hook.Status.DeleteCondition(
+		InvalidPreflight,
+		PreflightRunning,
+		PreflightSucceeded,
+		PreflightServiceAccountNotFound,
+		PreflightPermissionDenied,
+		PreflightImageNotPullable,
+		PreflightPlaybookInvalid,
+		PreflightFailed)
+}
@@ -0,0 +1,147 @@
+package mighook
+
+import (
+	"testing"
+
+	authv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_imagePullState(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       corev1.ContainerState
+		wantPulled  bool
+		wantReason  string
+		wantMessage string
+	}{
+		{
+			name:  "given a container creating, the image is not pulled yet",
+			state: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+		},
+		{
+			name: "given a waiting image pull error, the image cannot be pulled",
+			state: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+			wantReason:  "ImagePullBackOff",
+			wantMessage: "Back-off pulling image",
+		},
+		{
+			name: "given a waiting container that cannot be run, the image is pulled",
+			state: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason: "RunContainerError", Message: "executable file not found in $PATH"}},
+			wantPulled: true,
+		},
+		{
+			name:       "given a running container, the image is pulled",
+			state:      corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			wantPulled: true,
+		},
+		{
+			name:       "given a completed container, the image is pulled",
+			state:      corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+			wantPulled: true,
+		},
+		{
+			name:       "given a failed command, the image is pulled",
+			state:      corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+			wantPulled: true,
+		},
+		{
+			name: "given a terminated image pull error, the image cannot be pulled",
+			state: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason: "ErrImagePull", Message: "manifest unknown", ExitCode: 1}},
+			wantReason:  "ErrImagePull",
+			wantMessage: "manifest unknown",
+		},
+		{
+			name: "given a command missing from the image, the image is pulled",
+			state: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason: "ContainerCannotRun", Message: "executable file not found in $PATH", ExitCode: 128}},
+			wantPulled: true,
+		},
+		{
+			name:       "given a container the runtime cannot run without reason, the image is pulled",
+			state:      corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 128}},
+			wantPulled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pulled, reason, message := imagePullState(tt.state)
+			if pulled != tt.wantPulled {
+				t.Errorf("imagePullState() pulled = %v, want %v", pulled, tt.wantPulled)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("imagePullState() reason = %v, want %v", reason, tt.wantReason)
+			}
+			if message != tt.wantMessage {
+				t.Errorf("imagePullState() message = %v, want %v", message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_describePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes authv1.ResourceAttributes
+		want       string
+	}{
+		{
+			name:       "core resource",
+			attributes: authv1.ResourceAttributes{Verb: "list", Resource: "pods"},
+			want:       "list pods",
+		},
+		{
+			name: "named subresource in a namespace",
+			attributes: authv1.ResourceAttributes{
+				Verb: "create", Group: "apps", Resource: "deployments", Subresource: "scale",
+				Namespace: "ns-1", Name: "web"},
+			want: "create apps/deployments/scale ns-1/web",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describePermission(tt.attributes); got != tt.want {
+				t.Errorf("describePermission() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_jobFailed(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []batchv1.JobCondition
+		want       bool
+	}{
+		{
+			name: "given no conditions, the job has not failed",
+			want: false,
+		},
+		{
+			name: "given a failed condition, the job has failed",
+			conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"},
+			},
+			want: true,
+		},
+		{
+			name: "given a false failed condition, the job has not failed",
+			conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionFalse},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &batchv1.Job{Status: batchv1.JobStatus{Conditions: tt.conditions}}
+			if got := jobFailed(job); got != tt.want {
+				t.Errorf("jobFailed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,8 +3,12 @@ package mighook
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
+	"strings"
+
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/opentracing/opentracing-go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Types
@@ -14,11 +18,14 @@ const (
 	InvalidPlaybookData  = "InvalidPlaybookData"
 	InvalidAnsibleHook   = "InvalidAnsibleHook"
 	InvalidCustomHook    = "InvalidCustomHook"
+	InvalidExecHook      = "InvalidExecHook"
+	InvalidHookEnv       = "InvalidHookEnv"
 )
 
 // Categories
 const (
 	Critical = migapi.Critical
+	Advisory = migapi.Advisory
 )
 
 // Reasons
@@ -61,6 +68,14 @@ func (r ReconcileMigHook) validate(ctx context.Context, hook *migapi.MigHook) er
 	if err != nil {
 		return err
 	}
+	err = r.validateExec(ctx, hook)
+	if err != nil {
+		return err
+	}
+	err = r.validateEnv(ctx, hook)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -69,6 +84,9 @@ func (r ReconcileMigHook) validateImage(ctx context.Context, hook *migapi.MigHoo
 		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateImage")
 		defer span.Finish()
 	}
+	if hook.Spec.Exec != nil {
+		return nil
+	}
 	match := ReferenceRegexp.MatchString(hook.Spec.Image)
 
 	if !match {
@@ -131,7 +149,7 @@ func (r ReconcileMigHook) validateCustom(ctx context.Context, hook *migapi.MigHo
 			Category: Critical,
 			Message:  "An Ansible Playbook must not be specified when spec.custom is true.",
 		})
-	} else if !hook.Spec.Custom && hook.Spec.Playbook == "" {
+	} else if !hook.Spec.Custom && hook.Spec.Playbook == "" && hook.Spec.Exec == nil {
 		hook.Status.SetCondition(migapi.Condition{
 			Type:     InvalidAnsibleHook,
 			Status:   True,
@@ -142,3 +160,79 @@ func (r ReconcileMigHook) validateCustom(ctx context.Context, hook *migapi.MigHo
 	}
 	return nil
 }
+
+func (r ReconcileMigHook) validateExec(ctx context.Context, hook *migapi.MigHook) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateExec")
+		defer span.Finish()
+	}
+	exec := hook.Spec.Exec
+	if exec == nil {
+		return nil
+	}
+	if hook.Spec.Playbook != "" || hook.Spec.Image != "" {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidExecHook,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  "An image or an Ansible Playbook must not be specified when spec.exec is set.",
+		})
+		return nil
+	}
+	if len(exec.Command) == 0 {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidExecHook,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "A command must be specified in spec.exec.command.",
+		})
+		return nil
+	}
+	if exec.Selector == nil {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidExecHook,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "A pod selector must be specified in spec.exec.selector.",
+		})
+		return nil
+	}
+	if _, err := metav1.LabelSelectorAsSelector(exec.Selector); err != nil {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidExecHook,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  fmt.Sprintf("The pod selector in spec.exec.selector is invalid: %s.", err),
+		})
+	}
+	return nil
+}
+
+func (r ReconcileMigHook) validateEnv(ctx context.Context, hook *migapi.MigHook) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateEnv")
+		defer span.Finish()
+	}
+	reserved := []string{}
+	for _, env := range hook.Spec.Env {
+		if strings.HasPrefix(env.Name, migapi.HookEnvPrefix) {
+			reserved = append(reserved, env.Name)
+		}
+	}
+	if len(reserved) > 0 {
+		hook.Status.SetCondition(migapi.Condition{
+			Type:     InvalidHookEnv,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: fmt.Sprintf("Environment variables prefixed with %s are set by the controller"+
+				" and must not be specified in spec.env: []", migapi.HookEnvPrefix),
+			Items: reserved,
+		})
+	}
+	return nil
+}
@@ -53,7 +53,7 @@ func (t *Task) ensureInitialBackup() (*velero.Backup, error) {
 	newBackup.Spec.IncludedResources = toStringSlice(settings.IncludedInitialResources.Difference(toSet(t.PlanResources.MigPlan.Status.ExcludedResources)))
 	newBackup.Spec.IncludedResources = append(newBackup.Spec.IncludedResources, userIncludedResources...)
 	newBackup.Spec.ExcludedResources = toStringSlice(settings.ExcludedInitialResources.Union(toSet(t.PlanResources.MigPlan.Status.ExcludedResources)))
-	newBackup.Spec.LabelSelector = t.PlanResources.MigPlan.Spec.LabelSelector
+	t.filterBackup(client, newBackup, t.PlanResources.MigPlan.Spec.LabelSelector, false)
 	delete(newBackup.Annotations, migapi.QuiesceAnnotation)
 
 	if Settings.DisImgCopy {
@@ -87,10 +87,15 @@ func toSet(strSlice []string) mapset.Set {
 }
 
 // Get the initial backup on the source cluster.
+// When retrying, falls back to the completed initial backup of the retried migration.
 func (t *Task) getInitialBackup() (*velero.Backup, error) {
 	labels := t.Owner.GetCorrelationLabels()
 	labels[migapi.InitialBackupLabel] = t.UID()
-	return t.getBackup(labels)
+	backup, err := t.getBackup(labels)
+	if err != nil || backup != nil {
+		return backup, err
+	}
+	return t.getRetriedBackup(migapi.InitialBackupLabel)
 }
 
 // Ensure the second backup on the source cluster has been created and
@@ -132,7 +137,8 @@ func (t *Task) ensureStageBackup() (*velero.Backup, error) {
 	}
 	newBackup.Spec.IncludedResources = toStringSlice(includedResources.Difference(toSet(t.PlanResources.MigPlan.Status.ExcludedResources)))
 	newBackup.Spec.ExcludedResources = toStringSlice(settings.ExcludedStageResources.Union(toSet(t.PlanResources.MigPlan.Status.ExcludedResources)))
-	newBackup.Spec.LabelSelector = &labelSelector
+	// the stage backup includes the resources needed to migrate the volumes
+	t.filterBackup(client, newBackup, &labelSelector, true)
 	if Settings.DisImgCopy {
 		if newBackup.Annotations == nil {
 			newBackup.Annotations = map[string]string{}
@@ -149,10 +155,15 @@ func (t *Task) ensureStageBackup() (*velero.Backup, error) {
 }
 
 // Get the stage backup on the source cluster.
+// When retrying, falls back to the completed stage backup of the retried migration.
 func (t *Task) getStageBackup() (*velero.Backup, error) {
 	labels := t.Owner.GetCorrelationLabels()
 	labels[migapi.StageBackupLabel] = t.UID()
-	return t.getBackup(labels)
+	backup, err := t.getBackup(labels)
+	if err != nil || backup != nil {
+		return backup, err
+	}
+	return t.getRetriedBackup(migapi.StageBackupLabel)
 }
 
 func (t *Task) getPodVolumeBackupsForBackup(backup *velero.Backup) *velero.PodVolumeBackupList {
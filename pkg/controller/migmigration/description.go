@@ -39,6 +39,19 @@ var PhaseDescriptions = map[string]string{
 	PostBackupHooksFailed:                  "Migration failed while running user-defined post-backup hooks.",
 	PreRestoreHooksFailed:                  "Migration failed while running user-defined pre-restore hooks.",
 	PostRestoreHooksFailed:                 "Migration failed while running user-defined post-restore hooks.",
+	PreQuiesceHooks:                        "Waiting for user-defined pre-quiesce hooks to complete.",
+	PostDirectVolumeHooks:                  "Waiting for user-defined post-direct-volume hooks to complete.",
+	PreRollbackHooks:                       "Waiting for user-defined pre-rollback hooks to complete.",
+	PostRollbackHooks:                      "Waiting for user-defined post-rollback hooks to complete.",
+	PreQuiesceHooksFailed:                  "Migration failed while running user-defined pre-quiesce hooks.",
+	PostDirectVolumeHooksFailed:            "Migration failed while running user-defined post-direct-volume hooks.",
+	PreRollbackHooksFailed:                 "Rollback failed while running user-defined pre-rollback hooks.",
+	PostRollbackHooksFailed:                "Rollback failed while running user-defined post-rollback hooks.",
+	DeleteUnselectedResources:              "Deleting the restored resources not selected by the resource filters on the target cluster.",
+	TransformRestoredResources:             "Applying the restore transforms of the plan to the restored resources on the target cluster.",
+	TransformRestoredResourcesFailed:       "Migration failed while applying the restore transforms of the plan.",
+	CheckExistingResources:                 "Checking that the migrated resources do not exist in the target namespaces.",
+	CheckExistingResourcesFailed:           "Migration failed because migrated resources exist in the target namespaces.",
 	EnsureInitialBackup:                    "Creating initial Velero backup.",
 	InitialBackupCreated:                   "Waiting for initial Velero backup to complete.",
 	InitialBackupFailed:                    "Migration failed during initial Velero backup.",
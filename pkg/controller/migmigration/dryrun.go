@@ -0,0 +1,459 @@
+package migmigration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Dry run actions.
+const (
+	DryRunQuiesce   = "Quiesce"
+	DryRunUnquiesce = "Unquiesce"
+	DryRunAnnotate  = "Annotate"
+	DryRunStagePods = "CreateStagePods"
+	DryRunBackup    = "Backup"
+	DryRunRestore   = "Restore"
+	DryRunSwap      = "SwapPVCReferences"
+	DryRunDelete    = "Delete"
+	DryRunHooks     = "RunHooks"
+	DryRunTransform = "Transform"
+	DryRunCheck     = "CheckExisting"
+)
+
+// Key of the report in the dry run ConfigMap.
+const DryRunReportKey = "report.json"
+
+// DryRunReport describes what a migration would do.
+// Migration - The migration name.
+// Plan - The plan name.
+// Itinerary - The itinerary that would run.
+// Phases - The phases that would run, in order.
+type DryRunReport struct {
+	Migration string        `json:"migration"`
+	Plan      string        `json:"plan"`
+	Itinerary string        `json:"itinerary"`
+	Phases    []DryRunPhase `json:"phases"`
+}
+
+// DryRunPhase describes what a phase would do.
+// Name - The phase name.
+// Step - The pipeline step the phase belongs to.
+// Description - The phase description.
+// Action - What the phase would do to the listed resources.
+// Resources - The affected resources as <kind>/<namespace>/<name>.
+type DryRunPhase struct {
+	Name        string   `json:"name"`
+	Step        string   `json:"step"`
+	Description string   `json:"description,omitempty"`
+	Action      string   `json:"action,omitempty"`
+	Resources   []string `json:"resources,omitempty"`
+}
+
+// Get whether the migration is a dry run.
+func (t *Task) dryRun() bool {
+	return t.Owner.Spec.DryRun
+}
+
+// Walk the remaining phases of the itinerary and report what they
+// would do without changing the clusters. The report is written to a
+// ConfigMap and the migration completes with the DryRun condition
+// instead of Succeeded.
+func (t *Task) runDryRun() error {
+	report, err := t.buildDryRunReport()
+	if err != nil {
+		return err
+	}
+	cm, err := t.ensureDryRunConfigMap(report)
+	if err != nil {
+		return err
+	}
+	summary := []string{}
+	for _, phase := range report.Phases {
+		if phase.Action == "" {
+			continue
+		}
+		summary = append(summary,
+			fmt.Sprintf("%s: %s %d resource(s)", phase.Name, phase.Action, len(phase.Resources)))
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     DryRun,
+		Status:   True,
+		Reason:   Completed,
+		Category: Advisory,
+		Message: fmt.Sprintf("The dry run has completed, the report is in ConfigMap %s. Phases [].",
+			path.Join(cm.Namespace, cm.Name)),
+		Items:   summary,
+		Durable: true,
+	})
+	t.Log.Info("Dry run completed.",
+		"configMap", path.Join(cm.Namespace, cm.Name))
+	t.Phase = Completed
+	t.Step = StepCleanup
+	return nil
+}
+
+// Build the dry run report for the remaining phases of the itinerary.
+func (t *Task) buildDryRunReport() (*DryRunReport, error) {
+	report := &DryRunReport{
+		Migration: t.Owner.Name,
+		Plan:      t.PlanResources.MigPlan.Name,
+		Itinerary: t.Itinerary.Name,
+	}
+	started := false
+	for _, phase := range t.Itinerary.Phases {
+		if phase.Name == t.Phase {
+			started = true
+		}
+		if !started {
+			continue
+		}
+		flag, err := t.allFlags(phase)
+		if err != nil {
+			return nil, err
+		}
+		if !flag {
+			continue
+		}
+		flag, err = t.anyFlags(phase)
+		if err != nil {
+			return nil, err
+		}
+		if !flag {
+			continue
+		}
+		entry, err := t.dryRunPhase(phase)
+		if err != nil {
+			return nil, err
+		}
+		report.Phases = append(report.Phases, entry)
+	}
+	return report, nil
+}
+
+// Describe what the phase would do.
+func (t *Task) dryRunPhase(phase Phase) (DryRunPhase, error) {
+	var err error
+	entry := DryRunPhase{
+		Name:        phase.Name,
+		Step:        phase.Step,
+		Description: PhaseDescriptions[phase.Name],
+	}
+	switch phase.Name {
+	case QuiesceApplications:
+		entry.Action = DryRunQuiesce
+		entry.Resources, err = t.dryRunQuiesce()
+	case UnQuiesceSrcApplications:
+		entry.Action = DryRunUnquiesce
+		entry.Resources = namespaceResources(t.rollbackSourceNamespaces())
+	case UnQuiesceDestApplications:
+		entry.Action = DryRunUnquiesce
+		entry.Resources = namespaceResources(t.destinationNamespaces())
+	case AnnotateResources:
+		entry.Action = DryRunAnnotate
+		entry.Resources = append(namespaceResources(t.sourceNamespaces()), t.stagePVCResources()...)
+	case EnsureStagePodsFromRunning, EnsureStagePodsFromTemplates, EnsureStagePodsFromOrphanedPVCs:
+		entry.Action = DryRunStagePods
+		entry.Resources = t.stagePVCResources()
+	case EnsureInitialBackup:
+		entry.Action = DryRunBackup
+		entry.Resources = namespaceResources(t.sourceNamespaces())
+	case EnsureStageBackup:
+		entry.Action = DryRunBackup
+		entry.Resources = t.stagePVCResources()
+	case EnsureStageRestore, EnsureFinalRestore:
+		entry.Action = DryRunRestore
+		entry.Resources = t.namespaceMappingResources()
+	case SwapPVCReferences:
+		entry.Action = DryRunSwap
+		entry.Resources = t.pvcMappingResources()
+	case DeleteMigrated:
+		entry.Action = DryRunDelete
+		entry.Resources = namespaceResources(t.destinationNamespaces())
+	case PreQuiesceHooks:
+		entry.Action = DryRunHooks
+		entry.Resources = t.hookResources(migapi.PreQuiesceHookPhase)
+	case PreBackupHooks:
+		entry.Action = DryRunHooks
+		entry.Resources = t.hookResources(migapi.PreBackupHookPhase)
+	case PostBackupHooks:
+		entry.Action = DryRunHooks
+		entry.Resources = t.hookResources(migapi.PostBackupHookPhase)
+	case PreRestoreHooks:
+		entry.Action = DryRunHooks
+		entry.Resources = t.hookResources(migapi.PreRestoreHookPhase)
+	case PostRestoreHooks:
+		entry.Action = DryRunHooks
+		entry.Resources = t.hookResources(migapi.PostRestoreHookPhase)
+	case PostDirectVolumeHooks:
+		entry.Action = DryRunHooks
+		entry.Resources = t.hookResources(migapi.PostDirectVolumeHookPhase)
+	case PreRollbackHooks:
+		entry.Action = DryRunHooks
+		entry.Resources = t.hookResources(migapi.PreRollbackHookPhase)
+	case PostRollbackHooks:
+		entry.Action = DryRunHooks
+		entry.Resources = t.hookResources(migapi.PostRollbackHookPhase)
+	case CheckExistingResources:
+		entry.Action = DryRunCheck
+		entry.Resources, err = t.existingResources()
+	case TransformRestoredResources:
+		entry.Action = DryRunTransform
+		entry.Resources = t.transformResources()
+	}
+	return entry, err
+}
+
+// List the workloads on the source cluster that would be quiesced.
+func (t *Task) dryRunQuiesce() ([]string, error) {
+	client, err := t.getSourceClient()
+	if err != nil {
+		return nil, err
+	}
+	policy := t.quiescePolicy()
+	filter, err := newQuiescePolicyFilter(policy)
+	if err != nil {
+		return nil, err
+	}
+	resources := []string{}
+	for _, ns := range t.sourceNamespaces() {
+		custom, err := listQuiesceCustomResources(client, policy, ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range custom {
+			if filter.selects(w.kind, w.object) {
+				resources = append(resources, path.Join(w.kind, w.object.GetNamespace(), w.object.GetName()))
+			}
+		}
+		options := k8sclient.InNamespace(ns)
+		deployments := appsv1.DeploymentList{}
+		err = client.List(context.TODO(), &deployments, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range deployments.Items {
+			if !filter.selects(DeploymentKind, &r) {
+				continue
+			}
+			if r.Spec.Replicas == nil || *r.Spec.Replicas > 0 {
+				resources = append(resources, path.Join("Deployment", r.Namespace, r.Name))
+			}
+		}
+		statefulSets := appsv1.StatefulSetList{}
+		err = client.List(context.TODO(), &statefulSets, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range statefulSets.Items {
+			if !filter.selects(StatefulSetKind, &r) {
+				continue
+			}
+			if r.Spec.Replicas == nil || *r.Spec.Replicas > 0 {
+				resources = append(resources, path.Join("StatefulSet", r.Namespace, r.Name))
+			}
+		}
+		replicaSets := appsv1.ReplicaSetList{}
+		err = client.List(context.TODO(), &replicaSets, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range replicaSets.Items {
+			if len(r.OwnerReferences) > 0 || !filter.selects(ReplicaSetKind, &r) {
+				continue
+			}
+			if r.Spec.Replicas == nil || *r.Spec.Replicas > 0 {
+				resources = append(resources, path.Join("ReplicaSet", r.Namespace, r.Name))
+			}
+		}
+		daemonSets := appsv1.DaemonSetList{}
+		err = client.List(context.TODO(), &daemonSets, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range daemonSets.Items {
+			if !filter.selects(DaemonSetKind, &r) {
+				continue
+			}
+			resources = append(resources, path.Join("DaemonSet", r.Namespace, r.Name))
+		}
+		jobs := batchv1.JobList{}
+		err = client.List(context.TODO(), &jobs, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range jobs.Items {
+			if !filter.selects(JobKind, &r) {
+				continue
+			}
+			if r.Spec.Parallelism == nil || *r.Spec.Parallelism > 0 {
+				resources = append(resources, path.Join("Job", r.Namespace, r.Name))
+			}
+		}
+		cronJobs, err := t.dryRunQuiesceCronJobs(client, ns, filter)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, cronJobs...)
+	}
+	return resources, nil
+}
+
+// List the CronJobs in the namespace that would be suspended.
+func (t *Task) dryRunQuiesceCronJobs(client compat.Client, ns string, filter quiesceFilter) ([]string, error) {
+	resources := []string{}
+	options := k8sclient.InNamespace(ns)
+	if client.MinorVersion() < 21 {
+		list := batchv1beta.CronJobList{}
+		err := client.List(context.TODO(), &list, options)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range list.Items {
+			if !filter.selects(CronJobKind, &r) {
+				continue
+			}
+			if r.Spec.Suspend == nil || !*r.Spec.Suspend {
+				resources = append(resources, path.Join("CronJob", r.Namespace, r.Name))
+			}
+		}
+		return resources, nil
+	}
+	list := batchv1.CronJobList{}
+	err := client.List(context.TODO(), &list, options)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range list.Items {
+		if !filter.selects(CronJobKind, &r) {
+			continue
+		}
+		if r.Spec.Suspend == nil || !*r.Spec.Suspend {
+			resources = append(resources, path.Join("CronJob", r.Namespace, r.Name))
+		}
+	}
+	return resources, nil
+}
+
+// List the PVCs included in the stage backup.
+func (t *Task) stagePVCResources() []string {
+	resources := []string{}
+	for _, pv := range t.getStagePVs().List {
+		resources = append(resources,
+			path.Join("PersistentVolumeClaim", pv.PVC.Namespace, pv.PVC.GetSourceName()))
+	}
+	return resources
+}
+
+// List the source to destination namespace mapping.
+func (t *Task) namespaceMappingResources() []string {
+	resources := []string{}
+	mapping := t.PlanResources.MigPlan.GetNamespaceMapping()
+	for _, ns := range t.sourceNamespaces() {
+		resources = append(resources,
+			fmt.Sprintf("Namespace/%s -> Namespace/%s", ns, mapping[ns]))
+	}
+	return resources
+}
+
+// List the PVC references that would be swapped.
+func (t *Task) pvcMappingResources() []string {
+	resources := []string{}
+	for src, dest := range t.getPVCNameMapping() {
+		ns, name := path.Split(src)
+		if name == dest {
+			continue
+		}
+		resources = append(resources,
+			fmt.Sprintf("PersistentVolumeClaim/%s -> PersistentVolumeClaim/%s%s", src, ns, dest))
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// List the hooks that would run in the hook phase.
+func (t *Task) hookResources(hookPhase string) []string {
+	resources := []string{}
+	for _, hook := range t.PlanResources.MigPlan.Spec.Hooks {
+		if hook.Phase != hookPhase {
+			continue
+		}
+		resources = append(resources,
+			path.Join("MigHook", hook.Reference.Namespace, hook.Reference.Name))
+	}
+	return resources
+}
+
+// List the kinds transformed in the destination namespaces as resources.
+func (t *Task) transformResources() []string {
+	resources := []string{}
+	mapping := t.PlanResources.MigPlan.GetNamespaceMapping()
+	for _, transform := range t.PlanResources.MigPlan.Spec.Transforms {
+		for _, ns := range t.sourceNamespaces() {
+			if transform.AppliesTo(ns) {
+				resources = append(resources,
+					path.Join(transform.GroupKind().String(), mapping[ns]))
+			}
+		}
+	}
+	return resources
+}
+
+// List namespaces as resources.
+func namespaceResources(namespaces []string) []string {
+	resources := []string{}
+	for _, ns := range namespaces {
+		resources = append(resources, path.Join("Namespace", ns))
+	}
+	return resources
+}
+
+// Create or update the ConfigMap holding the dry run report.
+// The ConfigMap is named after the migration and owned by it.
+func (t *Task) ensureDryRunConfigMap(report *DryRunReport) (*corev1.ConfigMap, error) {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	cm := &corev1.ConfigMap{}
+	key := k8sclient.ObjectKey{
+		Namespace: t.Owner.Namespace,
+		Name:      t.Owner.Name + "-dry-run",
+	}
+	err = t.Client.Get(context.TODO(), key, cm)
+	if err != nil && !k8serror.IsNotFound(err) {
+		return nil, err
+	}
+	if k8serror.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels:    t.Owner.GetCorrelationLabels(),
+			},
+			Data: map[string]string{
+				DryRunReportKey: string(content),
+			},
+		}
+		migapi.SetOwnerReference(t.Owner, t.Owner, cm)
+		err = t.Client.Create(context.TODO(), cm)
+		return cm, err
+	}
+	cm.Data = map[string]string{
+		DryRunReportKey: string(content),
+	}
+	err = t.Client.Update(context.TODO(), cm)
+	return cm, err
+}
@@ -11,8 +11,12 @@ import (
 
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	dvmc "github.com/konveyor/mig-controller/pkg/controller/directvolumemigration"
+	"github.com/konveyor/mig-controller/pkg/settings"
 	kapi "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -26,7 +30,10 @@ func (t *Task) createDirectVolumeMigration() error {
 		return nil
 	}
 	t.Log.Info("Building DirectVolumeMigration resource definition")
-	dvm := t.buildDirectVolumeMigration()
+	dvm, err := t.buildDirectVolumeMigration()
+	if err != nil {
+		return err
+	}
 	if dvm == nil {
 		return errors.New("failed to build directvolumeclaim list")
 	}
@@ -37,13 +44,16 @@ func (t *Task) createDirectVolumeMigration() error {
 
 }
 
-func (t *Task) buildDirectVolumeMigration() *migapi.DirectVolumeMigration {
+func (t *Task) buildDirectVolumeMigration() (*migapi.DirectVolumeMigration, error) {
 	// Set correlation labels
 	labels := t.Owner.GetCorrelationLabels()
 	labels[migapi.DirectVolumeMigrationLabel] = t.UID()
-	pvcList := t.getDirectVolumeClaimList()
+	pvcList, err := t.getDirectVolumeClaimList()
+	if err != nil {
+		return nil, err
+	}
 	if pvcList == nil {
-		return nil
+		return nil, nil
 	}
 	dvm := &migapi.DirectVolumeMigration{
 		ObjectMeta: metav1.ObjectMeta{
@@ -56,10 +66,13 @@ func (t *Task) buildDirectVolumeMigration() *migapi.DirectVolumeMigration {
 			DestMigClusterRef:           t.PlanResources.DestMigCluster.GetObjectReference(),
 			PersistentVolumeClaims:      *pvcList,
 			CreateDestinationNamespaces: true,
+			RsyncOptions:                t.PlanResources.MigPlan.Spec.RsyncOptions.DeepCopy(),
+			RsyncTransferLimits:         t.PlanResources.MigPlan.Spec.RsyncTransferLimits.DeepCopy(),
+			Incremental:                 t.PlanResources.MigPlan.Spec.IncrementalVolumeMigration,
 		},
 	}
 	migapi.SetOwnerReference(t.Owner, t.Owner, dvm)
-	return dvm
+	return dvm, nil
 }
 
 func (t *Task) getDirectVolumeMigration() (*migapi.DirectVolumeMigration, error) {
@@ -120,7 +133,14 @@ func (t *Task) hasDirectVolumeMigrationCompleted(dvm *migapi.DirectVolumeMigrati
 }
 
 func (t *Task) getWarningForDVM(dvm *migapi.DirectVolumeMigration) (*migapi.Condition, error) {
-	conditions := dvm.Status.Conditions.FindConditionByCategory(dvmc.Warn)
+	conditions := []*migapi.Condition{}
+	for _, c := range dvm.Status.Conditions.FindConditionByCategory(dvmc.Warn) {
+		// verification failures do not block the migration
+		if c.Type == dvmc.RsyncVerificationFailed {
+			continue
+		}
+		conditions = append(conditions, c)
+	}
 	if len(conditions) > 0 {
 		return &migapi.Condition{
 			Type:     DirectVolumeMigrationBlocked,
@@ -155,6 +175,126 @@ func (t *Task) setDirectVolumeMigrationFailureWarning(dvm *migapi.DirectVolumeMi
 	})
 }
 
+// Set a condition on migmigration if the data of any volume differs after the migration.
+// The condition is critical when the dvm failed the verification, a warning otherwise.
+// Returns whether the data verification failed the dvm.
+func (t *Task) setDirectVolumeVerificationFailed(dvm *migapi.DirectVolumeMigration) bool {
+	condition := dvm.Status.Conditions.FindCondition(dvmc.RsyncVerificationFailed)
+	if condition == nil {
+		return false
+	}
+	message := fmt.Sprintf(
+		"DirectVolumeMigration (dvm): %s/%s data verification failed. See in dvm status.RsyncOperations",
+		dvm.GetNamespace(), dvm.GetName())
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     DirectVolumeVerificationFailed,
+		Status:   True,
+		Category: condition.Category,
+		Message:  message,
+		Items:    condition.Items,
+		Durable:  true,
+	})
+	return condition.Category == dvmc.Critical
+}
+
+// Record the transfers of an incremental DVM in the volume transfer history of the plan
+// and update the estimated duration of the volume transfers of the final migration.
+func (t *Task) recordVolumeTransferHistory(dvm *migapi.DirectVolumeMigration) error {
+	if !dvm.Spec.Incremental {
+		return nil
+	}
+	plan, err := t.Owner.GetPlan(t.Client)
+	if err != nil {
+		return err
+	}
+	if plan == nil {
+		return nil
+	}
+	if plan.Status.VolumeTransferHistory == nil {
+		plan.Status.VolumeTransferHistory = &migapi.VolumeTransferHistory{}
+	}
+	destClient, err := t.getDestinationClient()
+	if err != nil {
+		return err
+	}
+	history := plan.Status.VolumeTransferHistory
+	recorded := false
+	for _, operation := range dvm.Status.RsyncOperations {
+		for _, pvc := range dvm.Spec.PersistentVolumeClaims {
+			if pvc.ObjectReference == nil || operation.PVCReference == nil ||
+				pvc.Namespace != operation.PVCReference.Namespace || pvc.Name != operation.PVCReference.Name {
+				continue
+			}
+			targetUID, err := getTargetClaimUID(destClient, pvc)
+			if err != nil {
+				return err
+			}
+			if history.Record(t.Owner.GetName(), pvc, targetUID, operation) {
+				recorded = true
+			}
+		}
+	}
+	if !recorded {
+		return nil
+	}
+	maxTransfers := settings.Settings.DvmOpts.MaxTransfers
+	if dvm.Spec.RsyncTransferLimits != nil && dvm.Spec.RsyncTransferLimits.MaxTransfers != nil {
+		maxTransfers = *dvm.Spec.RsyncTransferLimits.MaxTransfers
+	}
+	history.EstimatedCutoverDuration = &metav1.Duration{
+		Duration: history.EstimateCutoverDuration(maxTransfers).Round(time.Second),
+	}
+	t.Log.Info("Recording volume transfer history on MigPlan",
+		"migPlan", path.Join(plan.Namespace, plan.Name),
+		"estimatedCutoverDuration", history.EstimatedCutoverDuration.Duration.String())
+	return t.Client.Update(context.TODO(), plan)
+}
+
+// Reset the volume transfer history of the PVCs of the destination namespaces rolled back.
+// The destination PVCs are deleted, the next migration must transfer the PVCs again.
+func (t *Task) resetVolumeTransferHistory() error {
+	if !t.Owner.Spec.RollbackScope.Includes(schema.GroupKind{Kind: "PersistentVolumeClaim"}) {
+		return nil
+	}
+	plan, err := t.Owner.GetPlan(t.Client)
+	if err != nil {
+		return err
+	}
+	if plan == nil || !plan.Status.VolumeTransferHistory.ResetTargetNamespaces(t.rollbackDestinationNamespaces()) {
+		return nil
+	}
+	t.Log.Info("Rollback: Resetting volume transfer history on MigPlan",
+		"migPlan", path.Join(plan.Namespace, plan.Name))
+	return t.Client.Update(context.TODO(), plan)
+}
+
+// Get the manifest of the last transfer of a PVC when the target PVC of the transfer still exists.
+// Empty when the target PVC was deleted or created again since the last transfer.
+func getLastSyncedManifest(client k8sclient.Client, history *migapi.VolumeTransferHistory, pvc migapi.PVCToMigrate) (string, error) {
+	if history == nil || pvc.ObjectReference == nil || history.FindPVC(pvc.Namespace, pvc.Name) == nil {
+		return "", nil
+	}
+	targetUID, err := getTargetClaimUID(client, pvc)
+	if err != nil {
+		return "", err
+	}
+	return history.GetLastSyncedManifest(pvc, targetUID), nil
+}
+
+// Get the UID of the target PVC of a PVC on the destination cluster, empty when not found.
+func getTargetClaimUID(client k8sclient.Client, pvc migapi.PVCToMigrate) (types.UID, error) {
+	claim := kapi.PersistentVolumeClaim{}
+	err := client.Get(context.TODO(),
+		types.NamespacedName{Namespace: pvc.TargetNamespace, Name: pvc.TargetName}, &claim)
+	if err != nil {
+		if k8serror.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return claim.UID, nil
+}
+
 func (t *Task) getDVMPodProgress(dvm migapi.DirectVolumeMigration) []string {
 	progress := []string{}
 	progressIterator := map[string][]*migapi.PodProgress{
@@ -177,7 +317,7 @@ func (t *Task) getDVMPodProgress(dvm migapi.DirectVolumeMigration) []string {
 					p += fmt.Sprintf(
 						" - Attempt %d of %d",
 						operation.CurrentAttempt,
-						dvmc.GetRsyncPodBackOffLimit(dvm))
+						dvmc.GetRsyncPodBackOffLimit(dvm, pod.PVCReference))
 				}
 			} else {
 				p = fmt.Sprintf("Rsync Pod %s: %s", path.Join(pod.Namespace, pod.Name), state)
@@ -197,20 +337,48 @@ func (t *Task) getDVMPodProgress(dvm migapi.DirectVolumeMigration) []string {
 	return progress
 }
 
-func (t *Task) getDirectVolumeClaimList() *[]migapi.PVCToMigrate {
+// Get the PVCs migrated by the DVM.
+// PVCs not selected by the resource filters are not migrated.
+func (t *Task) getDirectVolumeClaimList() (*[]migapi.PVCToMigrate, error) {
+	var client k8sclient.Client
+	if len(t.resourceFilters()) > 0 {
+		var err error
+		client, err = t.getSourceClient()
+		if err != nil {
+			return nil, err
+		}
+	}
 	nsMapping := t.PlanResources.MigPlan.GetNamespaceMapping()
+	// final migrations of incremental volume migrations skip the PVCs not changed since their last transfer
+	var history *migapi.VolumeTransferHistory
+	var destClient k8sclient.Client
+	if t.PlanResources.MigPlan.Spec.IncrementalVolumeMigration && !t.stage() {
+		history = t.PlanResources.MigPlan.Status.VolumeTransferHistory
+		var err error
+		destClient, err = t.getDestinationClient()
+		if err != nil {
+			return nil, err
+		}
+	}
 	pvcList := []migapi.PVCToMigrate{}
 	for _, pv := range t.PlanResources.MigPlan.Spec.PersistentVolumes.List {
 		if pv.Selection.Action != migapi.PvCopyAction || pv.Selection.CopyMethod != migapi.PvFilesystemCopyMethod {
 			continue
 		}
+		selected, err := t.selectsClaim(client, pv)
+		if err != nil {
+			return nil, err
+		}
+		if !selected {
+			continue
+		}
 		accessModes := pv.PVC.AccessModes
 		// if the user overrides access modes, set up the destination PVC with user-defined
 		// access mode
 		if pv.Selection.AccessMode != "" {
 			accessModes = []kapi.PersistentVolumeAccessMode{pv.Selection.AccessMode}
 		}
-		pvcList = append(pvcList, migapi.PVCToMigrate{
+		pvc := migapi.PVCToMigrate{
 			ObjectReference: &kapi.ObjectReference{
 				Name:      pv.PVC.GetSourceName(),
 				Namespace: pv.PVC.Namespace,
@@ -220,12 +388,18 @@ func (t *Task) getDirectVolumeClaimList() *[]migapi.PVCToMigrate {
 			TargetNamespace:    nsMapping[pv.PVC.Namespace],
 			TargetName:         pv.PVC.GetTargetName(),
 			Verify:             pv.Selection.Verify,
-		})
+			RsyncOptions:       pv.Selection.RsyncOptions.DeepCopy(),
+		}
+		pvc.LastSyncedManifest, err = getLastSyncedManifest(destClient, history, pvc)
+		if err != nil {
+			return nil, err
+		}
+		pvcList = append(pvcList, pvc)
 	}
 	if len(pvcList) > 0 {
-		return &pvcList
+		return &pvcList, nil
 	}
-	return nil
+	return nil, nil
 }
 
 func (t *Task) deleteDirectVolumeMigrationResources() error {
@@ -3,10 +3,15 @@ package migmigration
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
 	dvmc "github.com/konveyor/mig-controller/pkg/controller/directvolumemigration"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestTask_hasDirectVolumeMigrationCompleted(t1 *testing.T) {
@@ -160,3 +165,169 @@ func TestTask_hasDirectVolumeMigrationCompleted(t1 *testing.T) {
 		})
 	}
 }
+
+func TestTask_getWarningForDVM(t1 *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []migapi.Condition
+		want       *migapi.Condition
+	}{
+		{
+			name: "given a dvm warning, the migration is blocked",
+			conditions: []migapi.Condition{
+				{Type: dvmc.FailedCreatingRsyncPods, Status: True, Category: dvmc.Warn, Message: "failed creating pods"},
+			},
+			want: &migapi.Condition{
+				Type:     DirectVolumeMigrationBlocked,
+				Status:   True,
+				Reason:   migapi.NotReady,
+				Category: migapi.Warn,
+				Message:  "failed creating pods",
+			},
+		},
+		{
+			name: "given a dvm verification warning, the migration is not blocked",
+			conditions: []migapi.Condition{
+				{Type: dvmc.RsyncVerificationFailed, Status: True, Category: dvmc.Warn, Message: "data differs"},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{}
+			dvm := &migapi.DirectVolumeMigration{
+				Status: migapi.DirectVolumeMigrationStatus{
+					Conditions: migapi.Conditions{List: tt.conditions},
+				},
+			}
+			got, err := t.getWarningForDVM(dvm)
+			if err != nil {
+				t1.Errorf("getWarningForDVM() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t1.Errorf("getWarningForDVM() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_setDirectVolumeVerificationFailed(t1 *testing.T) {
+	tests := []struct {
+		name         string
+		conditions   []migapi.Condition
+		want         bool
+		wantCategory string
+	}{
+		{
+			name: "given no dvm verification failure, no condition is set",
+		},
+		{
+			name: "given a dvm verification warning, a warning is set",
+			conditions: []migapi.Condition{
+				{Type: dvmc.RsyncVerificationFailed, Status: True, Category: dvmc.Warn, Items: []string{"ns/pvc: 1 mismatched files"}},
+			},
+			wantCategory: migapi.Warn,
+		},
+		{
+			name: "given a dvm verification failure, the migration fails",
+			conditions: []migapi.Condition{
+				{Type: dvmc.RsyncVerificationFailed, Status: True, Category: dvmc.Critical, Items: []string{"ns/pvc: 1 mismatched files"}},
+			},
+			want:         true,
+			wantCategory: migapi.Critical,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{Owner: &migapi.MigMigration{}}
+			dvm := &migapi.DirectVolumeMigration{
+				Status: migapi.DirectVolumeMigrationStatus{
+					Conditions: migapi.Conditions{List: tt.conditions},
+				},
+			}
+			if got := t.setDirectVolumeVerificationFailed(dvm); got != tt.want {
+				t1.Errorf("setDirectVolumeVerificationFailed() = %v, want %v", got, tt.want)
+			}
+			condition := t.Owner.Status.FindCondition(DirectVolumeVerificationFailed)
+			category := ""
+			if condition != nil {
+				category = condition.Category
+			}
+			if category != tt.wantCategory {
+				t1.Errorf("setDirectVolumeVerificationFailed() category = %v, want %v", category, tt.wantCategory)
+			}
+		})
+	}
+}
+
+func Test_getLastSyncedManifest(t *testing.T) {
+	pvc := migapi.PVCToMigrate{
+		ObjectReference: &v1.ObjectReference{Namespace: "ns-1", Name: "pvc-1"},
+		TargetNamespace: "ns-2",
+		TargetName:      "pvc-1",
+	}
+	getTargetClaim := func(uid types.UID) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "pvc-1", UID: uid},
+		}
+	}
+	getHistory := func() *migapi.VolumeTransferHistory {
+		history := &migapi.VolumeTransferHistory{}
+		history.Record("stage-1", pvc, "uid-1", &migapi.RsyncOperation{
+			Succeeded: true,
+			Manifest:  "a",
+			Stats: &migapi.RsyncTransferStats{
+				Duration: &metav1.Duration{Duration: time.Minute},
+			},
+		})
+		return history
+	}
+	tests := []struct {
+		name    string
+		history *migapi.VolumeTransferHistory
+		objects []k8sclient.Object
+		want    string
+	}{
+		{
+			name:    "given no history, the PVC is transferred",
+			history: nil,
+			objects: []k8sclient.Object{getTargetClaim("uid-1")},
+			want:    "",
+		},
+		{
+			name:    "given the target PVC of the last transfer, the PVC is skipped when not changed",
+			history: getHistory(),
+			objects: []k8sclient.Object{getTargetClaim("uid-1")},
+			want:    "a",
+		},
+		{
+			name:    "given the target PVC deleted by a rollback, the PVC is transferred",
+			history: getHistory(),
+			objects: []k8sclient.Object{},
+			want:    "",
+		},
+		{
+			name:    "given the target PVC created again, the PVC is transferred",
+			history: getHistory(),
+			objects: []k8sclient.Object{getTargetClaim("uid-2")},
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := fakecompat.NewFakeClient(tt.objects...)
+			if err != nil {
+				t.Fatalf("NewFakeClient() error = %v", err)
+			}
+			got, err := getLastSyncedManifest(client, tt.history, pvc)
+			if err != nil {
+				t.Errorf("getLastSyncedManifest() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("getLastSyncedManifest() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
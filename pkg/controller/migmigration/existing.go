@@ -0,0 +1,86 @@
+package migmigration
+
+import (
+	"fmt"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/existing"
+	"github.com/konveyor/mig-controller/pkg/gvk"
+	velero "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+// The existing resources listed in the errors of a failed migration.
+const ExistingResourcesReportLimit = 20
+
+// Find the resources of the source namespaces already existing in
+// the destination namespaces and not restored by the plan.
+func (t *Task) findExistingResources() ([]existing.Resource, error) {
+	srcClient, GVRs, err := gvk.GetNamespacedGVRsForCluster(t.PlanResources.SrcMigCluster, t.Client)
+	if err != nil {
+		return nil, err
+	}
+	destClient, _, err := gvk.GetNamespacedGVRsForCluster(t.PlanResources.DestMigCluster, t.Client)
+	if err != nil {
+		return nil, err
+	}
+	finder := existing.Finder{
+		Source:      srcClient,
+		Destination: destClient,
+		GVRs:        GVRs,
+		Plan:        t.PlanResources.MigPlan,
+		Filters:     t.resourceFilters(),
+	}
+	resources, err := finder.Find()
+	if err != nil {
+		return nil, err
+	}
+	return existing.Conflicts(resources), nil
+}
+
+// Check that no resource of the source namespaces exists in the
+// destination namespaces as required by the Fail existing resource policy.
+// Returns: the errors reported when resources exist.
+func (t *Task) checkExistingResources() ([]string, error) {
+	resources, err := t.findExistingResources()
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, nil
+	}
+	t.Log.Info("Found resources existing in the destination namespaces.",
+		"count", len(resources))
+	reasons := []string{
+		fmt.Sprintf("%d resource(s) already exist in the destination namespaces and the plan existing resource policy is %s.",
+			len(resources), migapi.ExistingResourceFail),
+	}
+	for i, resource := range resources {
+		if i == ExistingResourcesReportLimit {
+			reasons = append(reasons, fmt.Sprintf("... and %d more.", len(resources)-i))
+			break
+		}
+		reasons = append(reasons, "Resource exists: "+resource.String())
+	}
+	return reasons, nil
+}
+
+// Get the Velero policy for the resources existing on the destination cluster.
+func (t *Task) veleroExistingResourcePolicy() velero.PolicyType {
+	if t.PlanResources.MigPlan.Spec.GetExistingResourcePolicy() == migapi.ExistingResourceUpdate {
+		return velero.PolicyTypeUpdate
+	}
+	return velero.PolicyTypeNone
+}
+
+// List the resources existing in the destination namespaces.
+func (t *Task) existingResources() ([]string, error) {
+	resources, err := t.findExistingResources()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, resource := range resources {
+		names = append(names, resource.String())
+	}
+	return names, nil
+}
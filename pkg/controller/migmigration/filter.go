@@ -0,0 +1,211 @@
+package migmigration
+
+import (
+	"context"
+	"path"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/gvk"
+	"github.com/pkg/errors"
+	velero "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Get the resource filters.
+// The filters of the migration override the filters of the plan.
+func (t *Task) resourceFilters() migapi.ResourceFilters {
+	if len(t.Owner.Spec.ResourceFilters) > 0 {
+		return t.Owner.Spec.ResourceFilters
+	}
+	return t.PlanResources.MigPlan.Spec.ResourceFilters
+}
+
+// Apply the resource filters to a Backup selecting the resources matching the base selector.
+// The included kinds restrict the included resources unless keepIncludedResources is set.
+// The Backup selects a superset of the resources selected by the filters when the filters
+// match resources by name or annotation, or by kind or label in some of the namespaces only.
+// The resources on the source cluster are not modified.
+func (t *Task) filterBackup(client k8sclient.Client, backup *velero.Backup, base *metav1.LabelSelector, keepIncludedResources bool) {
+	filters := t.resourceFilters()
+	backup.Spec.LabelSelector = base
+	if len(filters) == 0 {
+		return
+	}
+	namespaces := t.sourceNamespaces()
+	backup.Spec.ExcludedNamespaces = append(backup.Spec.ExcludedNamespaces, filters.ExcludedNamespaces()...)
+	backup.Spec.ExcludedResources = append(backup.Spec.ExcludedResources, t.filterResources(client, filters.ExcludedKinds())...)
+	if included := t.filterResources(client, filters.IncludedKinds(namespaces)); len(included) > 0 && !keepIncludedResources {
+		backup.Spec.IncludedResources = included
+	}
+	// Velero does not support both a label selector and OR label selectors.
+	selectors := filters.LabelSelectors(base, namespaces)
+	switch {
+	case selectors == nil:
+	case len(selectors) == 1:
+		backup.Spec.LabelSelector = selectors[0]
+	default:
+		backup.Spec.LabelSelector = nil
+		backup.Spec.OrLabelSelectors = selectors
+	}
+}
+
+// Apply the resource filters to a Restore.
+// The backups only include the resources of the included kinds, the stage
+// restores must restore the stage pods whatever the included kinds.
+func (t *Task) filterRestore(client k8sclient.Client, restore *velero.Restore) {
+	filters := t.resourceFilters()
+	if len(filters) == 0 {
+		return
+	}
+	restore.Spec.ExcludedResources = append(restore.Spec.ExcludedResources, t.filterResources(client, filters.ExcludedKinds())...)
+}
+
+// Get whether resources not selected by the resource filters are restored,
+// the filters cannot all be expressed as Velero selectors.
+func (t *Task) hasUnselectedResources() bool {
+	filters := t.resourceFilters()
+	return len(filters) > 0 && !filters.SelectedByVelero(t.sourceNamespaces())
+}
+
+// Delete the resources created by the final restore on the destination cluster
+// which are not selected by the resource filters. The resources owned by other
+// resources follow their owner and are not deleted.
+func (t *Task) deleteUnselectedResources() error {
+	restore, err := t.getFinalRestore()
+	if err != nil {
+		return err
+	}
+	if restore == nil {
+		return errors.New("Restore not found")
+	}
+	client, GVRs, err := gvk.GetNamespacedGVRsForCluster(t.PlanResources.DestMigCluster, t.Client)
+	if err != nil {
+		return err
+	}
+	return t.deleteUnselected(client, GVRs, restore.Name)
+}
+
+// Delete the resources of the GVRs created by a restore in the destination
+// namespaces which are not selected by the resource filters.
+func (t *Task) deleteUnselected(client dynamic.Interface, GVRs []schema.GroupVersionResource, restoreName string) error {
+	filters := t.resourceFilters()
+	mapping := t.PlanResources.MigPlan.GetNamespaceMapping()
+	for _, gvr := range GVRs {
+		for _, ns := range t.sourceNamespaces() {
+			list, err := client.Resource(gvr).Namespace(mapping[ns]).List(
+				context.TODO(),
+				metav1.ListOptions{
+					LabelSelector: labels.SelectorFromSet(map[string]string{
+						velero.RestoreNameLabel: restoreName,
+					}).String(),
+				})
+			if err != nil {
+				if k8serror.IsMethodNotSupported(err) || k8serror.IsNotFound(err) || k8serror.IsForbidden(err) {
+					continue
+				}
+				return err
+			}
+			for i := range list.Items {
+				object := &list.Items[i]
+				if len(object.GetOwnerReferences()) > 0 {
+					continue
+				}
+				// the filters select the resources of the source namespaces
+				source := &metav1.ObjectMeta{
+					Namespace:   ns,
+					Name:        object.GetName(),
+					Labels:      object.GetLabels(),
+					Annotations: object.GetAnnotations(),
+				}
+				selected, err := filters.Selects(object.GroupVersionKind().GroupKind(), source)
+				if err != nil {
+					return err
+				}
+				if selected {
+					continue
+				}
+				err = client.Resource(gvr).Namespace(object.GetNamespace()).Delete(
+					context.TODO(),
+					object.GetName(),
+					metav1.DeleteOptions{})
+				if err != nil && !k8serror.IsNotFound(err) {
+					return err
+				}
+				t.Log.Info("Deleted restored resource not selected by the resource filters.",
+					"resource", gvr.GroupResource().String(),
+					"name", path.Join(object.GetNamespace(), object.GetName()))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get the resources of kinds selected by the resource filters.
+// Kinds unknown to the cluster are ignored.
+func (t *Task) filterResources(client k8sclient.Client, kinds []metav1.GroupKind) []string {
+	resources := []string{}
+	for _, kind := range kinds {
+		mapping, err := client.RESTMapper().RESTMapping(schema.GroupKind{Group: kind.Group, Kind: kind.Kind})
+		if err != nil {
+			continue
+		}
+		resource := mapping.Resource.Resource
+		if mapping.Resource.Group != "" {
+			resource += "." + mapping.Resource.Group
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// Get whether the PVC of a PV is selected by the resource filters.
+// The result is cached for the duration of the reconcile.
+func (t *Task) selectsClaim(client k8sclient.Client, pv migapi.PV) (bool, error) {
+	filters := t.resourceFilters()
+	if len(filters) == 0 {
+		return true, nil
+	}
+	key := pv.PVC.Namespace + "/" + pv.PVC.GetSourceName()
+	if selected, found := t.selectedClaims[key]; found {
+		return selected, nil
+	}
+	pvc := &corev1.PersistentVolumeClaim{}
+	pvc.Namespace = pv.PVC.Namespace
+	pvc.Name = pv.PVC.GetSourceName()
+	// only the label and annotation filters need the PVC
+	needsClaim := false
+	for _, filter := range filters {
+		needsClaim = needsClaim || filter.LabelSelector != nil || len(filter.Annotations) > 0
+	}
+	if !needsClaim {
+		return filters.Selects(schema.GroupKind{Kind: "PersistentVolumeClaim"}, pvc)
+	}
+	err := client.Get(
+		context.TODO(),
+		k8sclient.ObjectKey{
+			Namespace: pv.PVC.Namespace,
+			Name:      pv.PVC.GetSourceName(),
+		},
+		pvc)
+	if err != nil {
+		if !k8serror.IsNotFound(err) {
+			return false, err
+		}
+	}
+	selected, err := filters.Selects(schema.GroupKind{Kind: "PersistentVolumeClaim"}, pvc)
+	if err != nil {
+		return false, err
+	}
+	if t.selectedClaims == nil {
+		t.selectedClaims = map[string]bool{}
+	}
+	t.selectedClaims[key] = selected
+	return selected, nil
+}
@@ -0,0 +1,296 @@
+package migmigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/pods"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/exec"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The output of an exec hook command kept in the status.
+const HookExecOutputLimit = 1024
+
+// Run an exec hook: execute the command in the selected running pods.
+// The commands run in the background and are polled on each reconcile, the
+// results are recorded in the migration status so that a completed hook is
+// not executed again while the other hooks of the phase run. A failed hook
+// is handled following the failure policy of the hook.
+// Returns: `true` when the hook completed, the status reported in the
+// pipeline and an error when the hook failed. The status is followed by
+// the details of a failure and is empty when the hook could not be run.
+func (t *Task) runExecHook(client k8sclient.Client, hook migapi.MigPlanHook, migHook migapi.MigHook, index int) (bool, []string, error) {
+	hookName := path.Join(migHook.Namespace, migHook.Name)
+	results := t.Owner.Status.FindHookExecResults(hook.Phase, index)
+	attempt := 0
+	if len(results) > 0 {
+		attempt = results[0].Attempt
+	}
+	if hookExecRunning(results) {
+		key := hookExecKey(t.Owner, hook.Phase, index, attempt)
+		completed, found := hookExecutions.take(key)
+		switch {
+		case !found:
+			// The controller restarted while the commands were running.
+			results = interruptHookExec(results)
+		case completed == nil:
+			return false, []string{fmt.Sprintf("Exec in %d pod(s): Running", len(results))}, nil
+		default:
+			results = completed
+		}
+		t.Owner.Status.SetHookExecResults(hook.Phase, index, results)
+	}
+	if attempt == 0 || (!hookExecSucceeded(results) &&
+		hook.GetFailurePolicy() == migapi.HookRetry && attempt <= hook.GetRetries()) {
+		attempt++
+		if attempt > 1 {
+			t.Log.Info("Exec hook failed, retrying.",
+				"migHook", hookName,
+				"attempt", attempt)
+		}
+		var err error
+		results, err = t.execHook(client, hook, migHook, index, attempt)
+		if err != nil {
+			return false, nil, err
+		}
+		t.Owner.Status.SetHookExecResults(hook.Phase, index, results)
+		if hookExecRunning(results) {
+			return false, []string{fmt.Sprintf("Exec in %d pod(s): Running", len(results))}, nil
+		}
+	}
+
+	status := fmt.Sprintf("Exec in %d pod(s)", len(results))
+	if hookExecSucceeded(results) {
+		return true, []string{status + ": Succeeded"}, nil
+	}
+	failure := hookExecFailure(results)
+	if hook.GetFailurePolicy() == migapi.HookIgnore {
+		t.Log.Info("Exec hook failed, ignoring the failure.",
+			"migHook", hookName)
+		return true, []string{status + ": Failed, ignored", "Reason: " + failure}, nil
+	}
+	return false, []string{status + ": Failed", "Reason: " + failure},
+		fmt.Errorf("Exec hook %s failed: %s", hookName, failure)
+}
+
+// Start the command of an exec hook in the selected running pods.
+// Returns: the results of the commands, marked as running until they complete.
+func (t *Task) execHook(client k8sclient.Client, hook migapi.MigPlanHook, migHook migapi.MigHook, index, attempt int) ([]migapi.HookExecResult, error) {
+	spec := migHook.Spec.Exec
+	selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	podList := corev1.PodList{}
+	err = client.List(
+		context.TODO(),
+		&podList,
+		k8sclient.InNamespace(hook.ExecutionNamespace),
+		k8sclient.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+	restCfg, err := t.getHookRestConfig(migHook)
+	if err != nil {
+		return nil, err
+	}
+	hookName := path.Join(migHook.Namespace, migHook.Name)
+	results := []migapi.HookExecResult{}
+	commands := []*pods.PodCommand{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		container := spec.Container
+		if container == "" && len(pod.Spec.Containers) > 0 {
+			container = pod.Spec.Containers[0].Name
+		}
+		commands = append(commands, &pods.PodCommand{
+			RestCfg:   restCfg,
+			Pod:       pod,
+			Container: container,
+			Args:      spec.Command,
+			Timeout:   spec.GetTimeout(),
+		})
+		results = append(results, migapi.HookExecResult{
+			Phase:     hook.Phase,
+			Index:     index,
+			Hook:      hookName,
+			Pod:       path.Join(pod.Namespace, pod.Name),
+			Container: container,
+			Attempt:   attempt,
+			Running:   true,
+		})
+		t.Log.Info("Executing hook command in pod.",
+			"migHook", hookName,
+			"pod", path.Join(pod.Namespace, pod.Name),
+			"container", container)
+	}
+	if len(results) == 0 {
+		results = append(results, migapi.HookExecResult{
+			Phase:   hook.Phase,
+			Index:   index,
+			Hook:    hookName,
+			Attempt: attempt,
+			Error: fmt.Sprintf("no running pod in namespace %s matches the selector %s",
+				hook.ExecutionNamespace, selector.String()),
+		})
+		return results, nil
+	}
+	hookExecutions.start(hookExecKey(t.Owner, hook.Phase, index, attempt), commands, results)
+
+	return results, nil
+}
+
+// Exec hook commands running in the background.
+var hookExecutions = &hookExecutionMap{
+	executions: map[string]*hookExecution{},
+}
+
+// The commands of an exec hook attempt.
+// results - The results of the commands, updated as the commands complete.
+// running - The number of commands running.
+type hookExecution struct {
+	results []migapi.HookExecResult
+	running int
+}
+
+// Exec hook attempts keyed by migration, phase, index and attempt.
+type hookExecutionMap struct {
+	mutex      sync.Mutex
+	executions map[string]*hookExecution
+}
+
+// Start the commands in the background.
+// Each command is bound by its timeout.
+func (m *hookExecutionMap) start(key string, commands []*pods.PodCommand, results []migapi.HookExecResult) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	execution := &hookExecution{
+		results: append([]migapi.HookExecResult{}, results...),
+		running: len(commands),
+	}
+	m.executions[key] = execution
+	for i, cmd := range commands {
+		go func(i int, cmd *pods.PodCommand) {
+			err := cmd.Run()
+			m.mutex.Lock()
+			defer m.mutex.Unlock()
+			result := &execution.results[i]
+			result.Running = false
+			result.Stdout = truncateHookOutput(cmd.Out.String())
+			result.Stderr = truncateHookOutput(cmd.Err.String())
+			setHookExecError(result, err)
+			execution.running--
+		}(i, cmd)
+	}
+}
+
+// Take the results of the commands when all of them completed.
+// Returns: the results (nil while running) and whether the attempt is found.
+func (m *hookExecutionMap) take(key string) ([]migapi.HookExecResult, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	execution, found := m.executions[key]
+	if !found {
+		return nil, false
+	}
+	if execution.running > 0 {
+		return nil, true
+	}
+	delete(m.executions, key)
+	return execution.results, true
+}
+
+// Get the key of an exec hook attempt.
+func hookExecKey(migration *migapi.MigMigration, phase string, index, attempt int) string {
+	return fmt.Sprintf("%s/%s/%d/%d", migration.UID, phase, index, attempt)
+}
+
+// Get whether the command is running in any of the pods.
+func hookExecRunning(results []migapi.HookExecResult) bool {
+	for i := range results {
+		if results[i].Running {
+			return true
+		}
+	}
+	return false
+}
+
+// Fail the commands which were running when the controller restarted.
+func interruptHookExec(results []migapi.HookExecResult) []migapi.HookExecResult {
+	interrupted := []migapi.HookExecResult{}
+	for _, result := range results {
+		if result.Running {
+			result.Running = false
+			result.Error = "the command was interrupted by a restart of the controller"
+		}
+		interrupted = append(interrupted, result)
+	}
+	return interrupted
+}
+
+// Record the error returned by the command in the result.
+// A command that exits with a non-zero code sets the exit code.
+func setHookExecError(result *migapi.HookExecResult, err error) {
+	if err == nil {
+		return
+	}
+	if exitErr, cast := err.(exec.CodeExitError); cast {
+		result.ExitCode = exitErr.Code
+		return
+	}
+	result.Error = err.Error()
+}
+
+// Keep the end of the command output.
+func truncateHookOutput(output string) string {
+	if len(output) <= HookExecOutputLimit {
+		return output
+	}
+	return "..." + output[len(output)-HookExecOutputLimit:]
+}
+
+// Get whether the command succeeded in all the pods.
+func hookExecSucceeded(results []migapi.HookExecResult) bool {
+	for i := range results {
+		if !results[i].Succeeded() {
+			return false
+		}
+	}
+	return len(results) > 0
+}
+
+// Describe the first failed command.
+func hookExecFailure(results []migapi.HookExecResult) string {
+	for _, result := range results {
+		if result.Succeeded() {
+			continue
+		}
+		if result.Error != "" {
+			return result.Error
+		}
+		return fmt.Sprintf("command exited with code %d in pod %s", result.ExitCode, result.Pod)
+	}
+	return ""
+}
+
+// Get the REST configuration for the cluster targeted by the hook.
+func (t *Task) getHookRestConfig(migHook migapi.MigHook) (*rest.Config, error) {
+	switch migHook.Spec.TargetCluster {
+	case "destination":
+		return t.PlanResources.DestMigCluster.BuildRestConfig(t.Client)
+	case "source":
+		return t.PlanResources.SrcMigCluster.BuildRestConfig(t.Client)
+	default:
+		return nil, fmt.Errorf("targetCluster must be 'source' or 'destination'. %s unknown", migHook.Spec.TargetCluster)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/base64"
 	"fmt"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -14,94 +16,330 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const HookJobFailedLimit = 6
 const BackoffLimitExceededError = "BackoffLimitExceeded"
 
+// The tail of the log of a failed hook pod reported in the pipeline.
+const (
+	HookLogTailLines = 10
+	HookLogLimit     = 2048
+)
+
+// Run the hooks of the phase one at a time in the order listed in the plan.
+// The result of each hook is reported in the progress of the pipeline step.
+// Returns: `true` when all the hooks completed.
 func (t *Task) runHooks(hookPhase string) (bool, error) {
-	hook := migapi.MigPlanHook{}
-	var client k8sclient.Client
-	var err error
+	hooks := t.getPhaseHooks(hookPhase)
+	if len(hooks) == 0 {
+		t.Log.Info("No hook attached to MigPlan for HookPhase, continuing.",
+			"hookPhase", hookPhase)
+		return true, nil
+	}
+	progress := []string{}
+	completed := true
+	var failure error
+	for i, hook := range hooks {
+		if !completed || failure != nil {
+			progress = append(progress, hookProgress(i, len(hooks), hook, "Pending"))
+			continue
+		}
+		done, status, err := t.runHook(hook, i)
+		if err != nil {
+			if len(status) == 0 {
+				return false, err
+			}
+			failure = err
+		}
+		for _, line := range status {
+			progress = append(progress, hookProgress(i, len(hooks), hook, line))
+		}
+		completed = done
+	}
+	t.setProgress(progress)
+	if failure != nil {
+		return false, failure
+	}
 
+	return completed, nil
+}
+
+// Get the hooks of the phase in the order listed in the plan.
+func (t *Task) getPhaseHooks(hookPhase string) []migapi.MigPlanHook {
+	hooks := []migapi.MigPlanHook{}
 	for _, h := range t.PlanResources.MigPlan.Spec.Hooks {
-		if h.Phase == hookPhase {
-			hook = h
+		if h.Phase == hookPhase && h.Reference != nil {
+			hooks = append(hooks, h)
 		}
 	}
+	return hooks
+}
 
+// Format the progress of a hook.
+func hookProgress(index, count int, hook migapi.MigPlanHook, status string) string {
+	return fmt.Sprintf("Hook %d/%d %s: %s",
+		index+1, count,
+		path.Join(hook.Reference.Namespace, hook.Reference.Name),
+		status)
+}
+
+// Run the hook at the specified index of its phase.
+// A failed hook job is handled following the failure policy of the hook.
+// Returns: `true` when the hook completed, the status reported in the
+// pipeline and an error when the hook failed. The status is followed by
+// the details of a failure and is empty when the hook could not be run.
+func (t *Task) runHook(hook migapi.MigPlanHook, index int) (bool, []string, error) {
 	migHook := migapi.MigHook{}
+	t.Log.Info("Found MigHook ref attached for phase, starting hook job.",
+		"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
+		"migHookPhase", hook.Phase,
+		"migHookIndex", index)
+	err := t.Client.Get(
+		context.TODO(),
+		types.NamespacedName{
+			Name:      hook.Reference.Name,
+			Namespace: hook.Reference.Namespace,
+		},
+		&migHook)
+	if err != nil {
+		return false, nil, err
+	}
 
-	if hook.Reference != nil {
-		t.Log.Info("Found MigHook ref attached for phase, starting hook job.",
-			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
-			"migHookPhase", hookPhase)
-		err = t.Client.Get(
-			context.TODO(),
-			types.NamespacedName{
-				Name:      hook.Reference.Name,
-				Namespace: hook.Reference.Namespace,
-			},
-			&migHook)
+	t.Log.Info("Getting k8s client for MigHook",
+		"migHook", path.Join(migHook.Namespace, migHook.Name))
+	client, err := t.getHookClient(migHook)
+	if err != nil {
+		return false, nil, err
+	}
+	if migHook.Spec.Exec != nil {
+		return t.runExecHook(client, hook, migHook, index)
+	}
+
+	svc := corev1.ServiceAccount{}
+	ref := types.NamespacedName{
+		Namespace: hook.ExecutionNamespace,
+		Name:      hook.ServiceAccount,
+	}
+	t.Log.Info("Getting executor ServiceAccount for MigHook ",
+		"serviceAccount", path.Join(ref.Namespace, ref.Name),
+		"migHook", path.Join(migHook.Namespace, migHook.Name))
+	err = client.Get(context.TODO(), ref, &svc)
+	if err != nil {
+		return false, nil, err
+	}
+
+	jobs, err := t.listHookJobs(client, migHook, hook.Phase, index)
+	if err != nil {
+		return false, nil, err
+	}
+	attempt := len(jobs)
+	if attempt == 0 {
+		err = t.createHookJob(client, hook, migHook, index)
 		if err != nil {
-			return false, err
+			return false, nil, err
 		}
-
-		t.Log.Info("Getting k8s client for MigHook",
+		return false, []string{"Job created"}, nil
+	}
+	runningJob := &jobs[attempt-1]
+	// Logs abnormal events for Hook Jobs if any are found
+	migevent.LogAbnormalEventsForResource(
+		client, t.Log,
+		"Found abnormal event for Hook Job",
+		types.NamespacedName{Namespace: runningJob.Namespace, Name: runningJob.Name},
+		runningJob.UID, "Job")
+	jobName := path.Join(runningJob.Namespace, runningJob.Name)
+
+	switch {
+	case runningJob.Status.Succeeded == 1:
+		return true, []string{fmt.Sprintf("Job %s: Succeeded", jobName)}, nil
+	case !hookJobFailed(runningJob):
+		return false, []string{fmt.Sprintf("Job %s: Running", jobName)}, nil
+	}
+	details := t.hookJobFailureDetails(client, migHook, runningJob)
+	switch {
+	case hook.GetFailurePolicy() == migapi.HookIgnore:
+		t.Log.Info("Hook job failed, ignoring the failure.",
+			"job", jobName,
 			"migHook", path.Join(migHook.Namespace, migHook.Name))
-		client, err = t.getHookClient(migHook)
+		return true, append([]string{fmt.Sprintf("Job %s: Failed, ignored", jobName)}, details...), nil
+	case attempt <= hook.GetRetries():
+		t.Log.Info("Hook job failed, retrying.",
+			"job", jobName,
+			"migHook", path.Join(migHook.Namespace, migHook.Name),
+			"attempt", attempt+1)
+		err = t.createHookJob(client, hook, migHook, index)
 		if err != nil {
-			return false, err
+			return false, nil, err
+		}
+		status := fmt.Sprintf("Job %s: Failed, retrying (attempt %d/%d)",
+			jobName, attempt+1, hook.GetRetries()+1)
+		return false, append([]string{status}, details...), nil
+	default:
+		err = fmt.Errorf("Hook job %s failed.", runningJob.Name)
+		if reason := hookJobFailureReason(runningJob); reason != "" {
+			err = fmt.Errorf("Hook job %s failed: %s", runningJob.Name, reason)
 		}
+		return false, append([]string{fmt.Sprintf("Job %s: Failed", jobName)}, details...), err
+	}
+}
+
+// Build and create the job running the hook.
+func (t *Task) createHookJob(client k8sclient.Client, hook migapi.MigPlanHook, migHook migapi.MigHook, index int) error {
+	t.Log.Info("Building Job resource definition for MigHook",
+		"migHook", path.Join(migHook.Namespace, migHook.Name))
+	job, err := t.prepareJob(hook, migHook, client)
+	if err != nil {
+		return err
+	}
+	job.Labels[migapi.HookIndexLabel] = strconv.Itoa(index)
 
-		svc := corev1.ServiceAccount{}
-		ref := types.NamespacedName{
-			Namespace: hook.ExecutionNamespace,
-			Name:      hook.ServiceAccount,
+	t.Log.Info("Creating Job for MigHook",
+		"job", path.Join(job.Namespace, job.GenerateName),
+		"migHook", path.Join(migHook.Namespace, migHook.Name))
+	return client.Create(context.TODO(), job)
+}
+
+// List the jobs created for the hook at the specified index of
+// its phase, oldest first. There is a job for each attempt.
+func (t *Task) listHookJobs(client k8sclient.Client, migHook migapi.MigHook, hookPhase string, index int) ([]batchv1.Job, error) {
+	list := batchv1.JobList{}
+	labels := migHook.GetCorrelationLabels()
+	labels[migapi.HookPhaseLabel] = hookPhase
+	labels[migapi.HookOwnerLabel] = string(t.Owner.UID)
+	labels[migapi.HookIndexLabel] = strconv.Itoa(index)
+	err := client.List(
+		context.TODO(),
+		&list,
+		k8sclient.MatchingLabels(labels))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].CreationTimestamp.Before(&list.Items[j].CreationTimestamp)
+	})
+	return list.Items, nil
+}
+
+// Describe why the hook job failed: the reason reported by the job and,
+// for the last pod of the job, the reason its container terminated and
+// the tail of its log. Problems reading the pods are logged and reported
+// as details.
+func (t *Task) hookJobFailureDetails(client k8sclient.Client, migHook migapi.MigHook, job *batchv1.Job) []string {
+	details := []string{}
+	if reason := hookJobFailureReason(job); reason != "" {
+		details = append(details, "Reason: "+reason)
+	}
+	podList := corev1.PodList{}
+	err := client.List(
+		context.TODO(),
+		&podList,
+		k8sclient.InNamespace(job.Namespace),
+		k8sclient.MatchingLabels{"job-name": job.Name})
+	if err != nil {
+		t.Log.Info("Failed to list the pods of the hook job.",
+			"job", path.Join(job.Namespace, job.Name),
+			"error", err.Error())
+		return details
+	}
+	if len(podList.Items) == 0 {
+		return details
+	}
+	sort.Slice(podList.Items, func(i, j int) bool {
+		return podList.Items[i].CreationTimestamp.Before(&podList.Items[j].CreationTimestamp)
+	})
+	pod := &podList.Items[len(podList.Items)-1]
+	podName := path.Join(pod.Namespace, pod.Name)
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			terminated = status.LastTerminationState.Terminated
 		}
-		t.Log.Info("Getting executor ServiceAccount for MigHook ",
-			"serviceAccount", path.Join(svc.Namespace, svc.Name),
-			"migHook", path.Join(migHook.Namespace, migHook.Name))
-		err = client.Get(context.TODO(), ref, &svc)
-		if err != nil {
-			return false, err
+		if terminated != nil && terminated.ExitCode != 0 {
+			details = append(details, fmt.Sprintf("Pod %s: %s, exit code %d. %s",
+				podName, terminated.Reason, terminated.ExitCode, terminated.Message))
 		}
+	}
+	lines, err := t.hookPodLogTail(migHook, pod)
+	if err != nil {
+		t.Log.Info("Failed to read the log of the hook pod.",
+			"pod", podName,
+			"error", err.Error())
+		return append(details, fmt.Sprintf("Pod %s: log unavailable: %s", podName, err.Error()))
+	}
+	for _, line := range lines {
+		details = append(details, fmt.Sprintf("Pod %s log: %s", podName, line))
+	}
+	return details
+}
 
-		t.Log.Info("Building Job resource definition for MigHook",
-			"migHook", path.Join(migHook.Namespace, migHook.Name))
-		job, err := t.prepareJob(hook, migHook, client)
-		if err != nil {
-			return false, err
+// Get the reason reported by the job for its failure.
+func hookJobFailureReason(job *batchv1.Job) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type != batchv1.JobFailed || condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		if condition.Message == "" {
+			return condition.Reason
 		}
+		return condition.Reason + ": " + condition.Message
+	}
+	return ""
+}
 
-		t.Log.Info("Creating Job for MigHook",
-			"job", path.Join(job.Namespace, job.Name),
-			"migHook", migHook.Namespace, migHook.Name)
-		result, err := t.ensureJob(job, hook, migHook, client)
-		if err != nil {
-			return false, err
+// Read the tail of the log of a hook pod.
+func (t *Task) hookPodLogTail(migHook migapi.MigHook, pod *corev1.Pod) ([]string, error) {
+	restCfg, err := t.getHookRestConfig(migHook)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, err
+	}
+	tailLines := int64(HookLogTailLines)
+	limitBytes := int64(HookLogLimit)
+	log, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		TailLines:  &tailLines,
+		LimitBytes: &limitBytes,
+	}).DoRaw(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{}
+	for _, line := range strings.Split(string(log), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
 		}
+	}
+	return lines, nil
+}
 
-		return result, nil
+// Get whether the hook job failed.
+func hookJobFailed(job *batchv1.Job) bool {
+	if job.Status.Failed >= HookJobFailedLimit {
+		return true
 	}
-	t.Log.Info("No hook attached to MigPlan for HookPhase, continuing.",
-		"hookPhase", hookPhase)
-	return true, nil
+	return len(job.Status.Conditions) > 0 && job.Status.Conditions[0].Reason == BackoffLimitExceededError
 }
 
 func (t *Task) stopHookJobs() (bool, error) {
 	var client k8sclient.Client
 	var err error
 
-	migHook := migapi.MigHook{}
+	phaseIndex := map[string]int{}
 	for _, hook := range t.PlanResources.MigPlan.Spec.Hooks {
-		t.Log.Info("Found MigHook ref, stopping hook job(s).",
-			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name))
 		if hook.Reference == nil {
 			continue
 		}
+		index := phaseIndex[hook.Phase]
+		phaseIndex[hook.Phase]++
+		t.Log.Info("Found MigHook ref, stopping hook job(s).",
+			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name))
 
+		migHook := migapi.MigHook{}
 		t.Log.Info("Getting MigHook",
 			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name))
 		err = t.Client.Get(
@@ -122,19 +360,19 @@ func (t *Task) stopHookJobs() (bool, error) {
 			return false, err
 		}
 
-		// Get the job for the hook and kill it.
+		// Get the jobs for the hook and kill the running ones.
 		t.Log.Info("Attempting to kill job for MigHook",
 			"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
 			"migHookPhase", hook.Phase)
-		runningJob, err := migHook.GetPhaseJob(client, hook.Phase, string(t.Owner.UID))
-		if runningJob == nil && err == nil {
-			// No active Job for hook
-			t.Log.Info("No active job found for MigHook. Continuing.",
-				"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
-				"migHookPhase", hook.Phase)
-			continue
-		} else {
-			// Job found
+		jobs, err := t.listHookJobs(client, migHook, hook.Phase, index)
+		if err != nil {
+			return false, err
+		}
+		for i := range jobs {
+			runningJob := &jobs[i]
+			if runningJob.Status.Succeeded == 1 || hookJobFailed(runningJob) {
+				continue
+			}
 			t.Log.Info("Deleting hook job found for MigHook. Continuing.",
 				"job", path.Join(runningJob.Namespace, runningJob.Name),
 				"migHook", path.Join(hook.Reference.Namespace, hook.Reference.Name),
@@ -146,51 +384,10 @@ func (t *Task) stopHookJobs() (bool, error) {
 					"job", path.Join(runningJob.Namespace, runningJob.Name))
 			}
 		}
-
 	}
 	return true, nil
 }
 
-func (t *Task) ensureJob(job *batchv1.Job, hook migapi.MigPlanHook, migHook migapi.MigHook, client k8sclient.Client) (bool, error) {
-	runningJob, err := migHook.GetPhaseJob(client, hook.Phase, string(t.Owner.UID))
-	if runningJob != nil {
-		// Logs abnormal events for Hook Jobs if any are found
-		migevent.LogAbnormalEventsForResource(
-			client, t.Log,
-			"Found abnormal event for Hook Job",
-			types.NamespacedName{Namespace: runningJob.Namespace, Name: runningJob.Name},
-			runningJob.UID, "Job")
-	}
-
-	if runningJob == nil && err == nil {
-		err = client.Create(context.TODO(), job)
-		if err != nil {
-			return false, err
-		}
-		return false, nil
-	} else if err != nil {
-		return false, err
-	} else if runningJob.Status.Failed >= HookJobFailedLimit {
-		err := fmt.Errorf("Hook job %s failed.", runningJob.Name)
-		t.setProgress([]string{
-			fmt.Sprintf("Job %s/%s: Failed", runningJob.Namespace, runningJob.Name)})
-		return false, err
-	} else if len(runningJob.Status.Conditions) > 0 && runningJob.Status.Conditions[0].Reason == BackoffLimitExceededError {
-		err := fmt.Errorf("Hook job %s failed.", runningJob.Name)
-		t.setProgress([]string{
-			fmt.Sprintf("Job %s/%s: Failed", runningJob.Namespace, runningJob.Name)})
-		return false, err
-	} else if runningJob.Status.Succeeded == 1 {
-		t.setProgress([]string{
-			fmt.Sprintf("Job %s/%s: Succeeded", runningJob.Namespace, runningJob.Name)})
-		return true, nil
-	} else {
-		t.setProgress([]string{
-			fmt.Sprintf("Job %s/%s: Running", runningJob.Namespace, runningJob.Name)})
-		return false, nil
-	}
-}
-
 func (t *Task) prepareJob(hook migapi.MigPlanHook, migHook migapi.MigHook, client k8sclient.Client) (*batchv1.Job, error) {
 	job := &batchv1.Job{}
 
@@ -203,17 +400,20 @@ func (t *Task) prepareJob(hook migapi.MigPlanHook, migHook migapi.MigHook, clien
 			return nil, err
 		}
 
+		// The ConfigMap may have been created by a previous attempt.
+		// The name is generated so the job mounts the one found or created.
 		phaseConfigMap, err := migHook.GetPhaseConfigMap(client, hook.Phase, string(t.Owner.UID))
-		if phaseConfigMap == nil && err == nil {
-
+		if err != nil {
+			return nil, err
+		}
+		if phaseConfigMap == nil {
 			err = client.Create(context.TODO(), configMap)
 			if err != nil {
 				return nil, err
 			}
-		} else if err != nil {
-			return nil, err
+			phaseConfigMap = configMap
 		}
-		job = t.playbookJobTemplate(hook, migHook, configMap.Name)
+		job = t.playbookJobTemplate(hook, migHook, phaseConfigMap.Name)
 	}
 
 	return job, nil
@@ -306,6 +506,11 @@ func (t *Task) baseJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook)
 		deadlineSeconds = migHook.Spec.ActiveDeadlineSeconds
 	}
 
+	resources := corev1.ResourceRequirements{}
+	if migHook.Spec.Resources != nil {
+		resources = *migHook.Spec.Resources
+	}
+
 	labels := migHook.GetCorrelationLabels()
 	labels[migapi.HookPhaseLabel] = hook.Phase
 	labels[migapi.HookOwnerLabel] = string(t.Owner.UID)
@@ -321,25 +526,67 @@ func (t *Task) baseJobTemplate(hook migapi.MigPlanHook, migHook migapi.MigHook)
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
 						{
-							Name:  strings.ToLower(t.PlanResources.MigPlan.Name + "-" + hook.Phase),
-							Image: migHook.Spec.Image,
-							Env: []corev1.EnvVar{
-								{
-									Name:  "MIGRATION_NAMESPACES",
-									Value: strings.Join(t.PlanResources.MigPlan.Spec.Namespaces, ","),
-								},
-								{
-									Name:  "MIGRATION_PLAN_NAME",
-									Value: t.PlanResources.MigPlan.Name,
-								},
-							},
+							Name:      strings.ToLower(t.PlanResources.MigPlan.Name + "-" + hook.Phase),
+							Image:     migHook.Spec.Image,
+							Env:       append(t.hookContextEnv(hook), migHook.Spec.Env...),
+							EnvFrom:   migHook.Spec.EnvFrom,
+							Resources: resources,
 						},
 					},
 					RestartPolicy:         "OnFailure",
 					ServiceAccountName:    hook.ServiceAccount,
 					ActiveDeadlineSeconds: &deadlineSeconds,
+					NodeSelector:          migHook.Spec.NodeSelector,
+					Tolerations:           migHook.Spec.Tolerations,
 				},
 			},
 		},
 	}
 }
+
+// Build the environment describing the migration to the hook.
+func (t *Task) hookContextEnv(hook migapi.MigPlanHook) []corev1.EnvVar {
+	plan := t.PlanResources.MigPlan
+	migrationType := "Final"
+	switch {
+	case t.rollback():
+		migrationType = "Rollback"
+	case t.stage():
+		migrationType = "Stage"
+	}
+	nsMapping := plan.GetNamespaceMapping()
+	mapping := []string{}
+	for _, ns := range plan.GetSourceNamespaces() {
+		mapping = append(mapping, ns+":"+nsMapping[ns])
+	}
+	pvcs := []string{}
+	for _, pv := range plan.Spec.PersistentVolumes.List {
+		if pv.Selection.Action == migapi.PvSkipAction {
+			continue
+		}
+		pvcs = append(pvcs, path.Join(pv.PVC.Namespace, pv.PVC.GetSourceName()))
+	}
+	env := map[string]string{
+		migapi.HookEnvNamespaces:         strings.Join(plan.Spec.Namespaces, ","),
+		migapi.HookEnvPlanName:           plan.Name,
+		migapi.HookEnvMigrationName:      t.Owner.Name,
+		migapi.HookEnvMigrationUID:       string(t.Owner.UID),
+		migapi.HookEnvItinerary:          t.Itinerary.Name,
+		migapi.HookEnvType:               migrationType,
+		migapi.HookEnvPhase:              hook.Phase,
+		migapi.HookEnvSourceCluster:      t.PlanResources.SrcMigCluster.Name,
+		migapi.HookEnvDestinationCluster: t.PlanResources.DestMigCluster.Name,
+		migapi.HookEnvNamespaceMapping:   strings.Join(mapping, ","),
+		migapi.HookEnvPVCs:               strings.Join(pvcs, ","),
+	}
+	names := []string{}
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	vars := []corev1.EnvVar{}
+	for _, name := range names {
+		vars = append(vars, corev1.EnvVar{Name: name, Value: env[name]})
+	}
+	return vars
+}
@@ -34,6 +34,14 @@ var (
 	},
 		[]string{"type", "status"},
 	)
+
+	// 'phase' - the phase that exceeded its deadline
+	phaseTimeoutCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cam_app_workload_migration_phase_timeouts",
+		Help: "Count of MigMigration phases that exceeded their deadline sorted by phase",
+	},
+		[]string{"phase"},
+	)
 )
 
 func recordMetrics(client client.Client) {
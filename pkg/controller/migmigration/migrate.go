@@ -81,7 +81,21 @@ func (r *ReconcileMigMigration) migrate(ctx context.Context, migration *migapi.M
 		return task.Requeue, nil
 	}
 
+	// Report
+	// Written before the Completed phase is recorded so that the report
+	// is written again by the next reconcile when writing it fails.
+	if task.Phase == Completed && !task.dryRun() {
+		err = task.ensureReport()
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	// Result
+	// The phase start is the beginning of the phase deadline.
+	if migration.Status.Phase != task.Phase || migration.Status.PhaseStartTimestamp == nil {
+		migration.Status.PhaseStartTimestamp = &metav1.Time{Time: time.Now()}
+	}
 	migration.Status.Phase = task.Phase
 	migration.Status.Itinerary = task.Itinerary.Name
 
@@ -91,6 +105,10 @@ func (r *ReconcileMigMigration) migrate(ctx context.Context, migration *migapi.M
 		migtrace.CloseMigrationSpan(string(migration.GetUID()))
 
 		migration.Status.DeleteCondition(migapi.Running)
+		// A dry run completes with the DryRun condition, nothing was migrated.
+		if task.dryRun() {
+			return NoReQ, nil
+		}
 		failed := task.Owner.Status.FindCondition(migapi.Failed)
 		warnings := task.Owner.Status.FindConditionByCategory(migapi.Warn)
 		if failed == nil && len(warnings) == 0 {
@@ -118,6 +136,9 @@ func (r *ReconcileMigMigration) migrate(ctx context.Context, migration *migapi.M
 
 	phase, n, total := task.Itinerary.progressReport(task.Phase)
 	message := fmt.Sprintf("Step: %d/%d", n, total)
+	if task.paused() {
+		message += " (paused)"
+	}
 	migration.Status.SetCondition(migapi.Condition{
 		Type:     migapi.Running,
 		Status:   True,
@@ -0,0 +1,89 @@
+package migmigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Get whether the migration should be held at the current phase boundary.
+// A pause never blocks a cancel or the cleanup of a failed migration.
+func (t *Task) paused() bool {
+	return t.Owner.Spec.Paused && t.migrating()
+}
+
+// Hold the migration at the current phase.
+// The phase is not run and the DVM/DIM children are paused as well.
+func (t *Task) pause() error {
+	err := t.setChildMigrationsPaused(true)
+	if err != nil {
+		return err
+	}
+	if !t.Owner.Status.HasCondition(Paused) {
+		t.Log.Info("Pausing migration at phase boundary.")
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     Paused,
+		Status:   True,
+		Reason:   t.Phase,
+		Category: Advisory,
+		Message:  fmt.Sprintf("The migration is paused before phase %s.", t.Phase),
+		Durable:  true,
+	})
+	t.Requeue = NoReQ
+	return nil
+}
+
+// Resume a previously paused migration from the recorded phase.
+func (t *Task) resume() error {
+	if !t.Owner.Status.HasCondition(Paused) {
+		return nil
+	}
+	err := t.setChildMigrationsPaused(false)
+	if err != nil {
+		return err
+	}
+	t.Log.Info("Resuming paused migration.")
+	t.Owner.Status.DeleteCondition(Paused)
+	// The deadline of the phase restarts when resumed.
+	t.Owner.Status.PhaseStartTimestamp = &metav1.Time{Time: time.Now()}
+	return nil
+}
+
+// Propagate the paused state to the DirectVolumeMigration and
+// DirectImageMigration owned by the migration, when they exist.
+func (t *Task) setChildMigrationsPaused(paused bool) error {
+	dvm, err := t.getDirectVolumeMigration()
+	if err != nil {
+		return err
+	}
+	if dvm != nil && dvm.Spec.Paused != paused {
+		dvm.Spec.Paused = paused
+		t.Log.Info("Updating paused state of DirectVolumeMigration.",
+			"directVolumeMigration", path.Join(dvm.Namespace, dvm.Name),
+			"paused", paused)
+		err = t.Client.Update(context.TODO(), dvm)
+		if err != nil {
+			return err
+		}
+	}
+	dim, err := t.getDirectImageMigration()
+	if err != nil {
+		return err
+	}
+	if dim != nil && dim.Spec.Paused != paused {
+		dim.Spec.Paused = paused
+		t.Log.Info("Updating paused state of DirectImageMigration.",
+			"directImageMigration", path.Join(dim.Namespace, dim.Name),
+			"paused", paused)
+		err = t.Client.Update(context.TODO(), dim)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -14,18 +14,35 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta "k8s.io/api/batch/v1beta1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// Quiesce applications on source cluster
+// Quiesce applications on source cluster.
+// When the quiesce policy defines stages, only the workloads
+// of the first stage are quiesced here, see: ensureQuiesced().
 func (t *Task) quiesceApplications() error {
 	client, err := t.getSourceClient()
 	if err != nil {
 		return err
 	}
-	err = t.quiesceCronJobs(client)
+	policy := t.quiescePolicy()
+	filter, err := newQuiesceFilter(policy, 0)
+	if err != nil {
+		return err
+	}
+	return t.quiesceWorkloads(client, policy, filter)
+}
+
+// Quiesce the workloads selected by the filter.
+func (t *Task) quiesceWorkloads(client compat.Client, policy *migapi.QuiescePolicy, filter quiesceFilter) error {
+	err := t.quiesceCustomResources(client, policy, filter)
+	if err != nil {
+		return err
+	}
+	err = t.quiesceCronJobs(client, filter)
 	if err != nil {
 		return err
 	}
@@ -33,23 +50,27 @@ func (t *Task) quiesceApplications() error {
 	//if err != nil {
 	//	return err
 	//}
-	err = t.quiesceDeployments(client)
+	err = t.quiesceDeployments(client, filter)
 	if err != nil {
 		return err
 	}
-	err = t.quiesceStatefulSets(client)
+	ordered := false
+	if kind := policy.FindKind(StatefulSetKind); kind != nil {
+		ordered = kind.Ordered
+	}
+	err = t.quiesceStatefulSets(client, filter, ordered)
 	if err != nil {
 		return err
 	}
-	err = t.quiesceReplicaSets(client)
+	err = t.quiesceReplicaSets(client, filter)
 	if err != nil {
 		return err
 	}
-	err = t.quiesceDaemonSets(client)
+	err = t.quiesceDaemonSets(client, filter)
 	if err != nil {
 		return err
 	}
-	err = t.quiesceJobs(client)
+	err = t.quiesceJobs(client, filter)
 	if err != nil {
 		return err
 	}
@@ -63,11 +84,14 @@ func (t *Task) unQuiesceSrcApplications() error {
 		return err
 	}
 	t.Log.Info("Unquiescing applications on source cluster.")
-	err = t.unQuiesceApplications(srcClient, t.sourceNamespaces())
+	t.startRollbackReport()
+	err = t.unQuiesceApplications(srcClient, t.rollbackSourceNamespaces(), t.Owner.Spec.RollbackScope)
+	// The report records the failure as well.
+	reportErr := t.updateRollbackReport()
 	if err != nil {
 		return err
 	}
-	return nil
+	return reportErr
 }
 
 func (t *Task) unQuiesceDestApplications() error {
@@ -76,16 +100,22 @@ func (t *Task) unQuiesceDestApplications() error {
 		return err
 	}
 	t.Log.Info("Unquiescing applications on destination cluster.")
-	err = t.unQuiesceApplications(destClient, t.destinationNamespaces())
+	err = t.unQuiesceApplications(destClient, t.destinationNamespaces(), nil)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-// Unquiesce applications using client and namespace list given
-func (t *Task) unQuiesceApplications(client compat.Client, namespaces []string) error {
-	err := t.unQuiesceCronJobs(client, namespaces)
+// Unquiesce applications using client and namespace list given.
+// Only the workloads of the kinds included in the (optional) rollback
+// scope are unquiesced.
+func (t *Task) unQuiesceApplications(client compat.Client, namespaces []string, scope *migapi.RollbackScope) error {
+	policy, err := t.unQuiescePolicy()
+	if err != nil {
+		return err
+	}
+	err = t.unQuiesceCustomResources(client, policy, namespaces, scope)
 	if err != nil {
 		return err
 	}
@@ -93,25 +123,43 @@ func (t *Task) unQuiesceApplications(client compat.Client, namespaces []string)
 	//if err != nil {
 	//	return err
 	//}
-	err = t.unQuiesceDeployments(client, namespaces)
-	if err != nil {
-		return err
-	}
-	err = t.unQuiesceStatefulSets(client, namespaces)
-	if err != nil {
-		return err
-	}
-	err = t.unQuiesceReplicaSets(client, namespaces)
-	if err != nil {
-		return err
-	}
-	err = t.unQuiesceDaemonSets(client, namespaces)
-	if err != nil {
-		return err
-	}
-	err = t.unQuiesceJobs(client, namespaces)
-	if err != nil {
-		return err
+	workloads := []struct {
+		kind      schema.GroupKind
+		unQuiesce func() error
+	}{
+		{
+			kind:      schema.GroupKind{Group: "batch", Kind: CronJobKind},
+			unQuiesce: func() error { return t.unQuiesceCronJobs(client, namespaces) },
+		},
+		{
+			kind:      schema.GroupKind{Group: "apps", Kind: DeploymentKind},
+			unQuiesce: func() error { return t.unQuiesceDeployments(client, namespaces) },
+		},
+		{
+			kind:      schema.GroupKind{Group: "apps", Kind: StatefulSetKind},
+			unQuiesce: func() error { return t.unQuiesceStatefulSets(client, namespaces) },
+		},
+		{
+			kind:      schema.GroupKind{Group: "apps", Kind: ReplicaSetKind},
+			unQuiesce: func() error { return t.unQuiesceReplicaSets(client, namespaces) },
+		},
+		{
+			kind:      schema.GroupKind{Group: "apps", Kind: DaemonSetKind},
+			unQuiesce: func() error { return t.unQuiesceDaemonSets(client, namespaces) },
+		},
+		{
+			kind:      schema.GroupKind{Group: "batch", Kind: JobKind},
+			unQuiesce: func() error { return t.unQuiesceJobs(client, namespaces) },
+		},
+	}
+	for _, workload := range workloads {
+		if !scope.Includes(workload.kind) {
+			continue
+		}
+		err = workload.unQuiesce()
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -221,8 +269,8 @@ func (t *Task) unQuiesceDeploymentConfigs(client k8sclient.Client, namespaces []
 	return nil
 }
 
-// Scales down all Deployments
-func (t *Task) quiesceDeployments(client k8sclient.Client) error {
+// Scales down all Deployments selected by the filter
+func (t *Task) quiesceDeployments(client k8sclient.Client, filter quiesceFilter) error {
 	zero := int32(0)
 	for _, ns := range t.sourceNamespaces() {
 		list := appsv1.DeploymentList{}
@@ -235,6 +283,9 @@ func (t *Task) quiesceDeployments(client k8sclient.Client) error {
 			return err
 		}
 		for _, deployment := range list.Items {
+			if !filter.selects(DeploymentKind, &deployment) {
+				continue
+			}
 			if deployment.Annotations == nil {
 				deployment.Annotations = make(map[string]string)
 			}
@@ -306,6 +357,8 @@ func (t *Task) unQuiesceDeployments(client k8sclient.Client, namespaces []string
 				migapi.ReplicasAnnotation),
 				"deployment", path.Join(deployment.Namespace, deployment.Name))
 			err = client.Update(context.TODO(), &deployment)
+			t.recordRestored("apps/v1", DeploymentKind, &deployment,
+				fmt.Sprintf("Replicas restored to %d.", *deployment.Spec.Replicas), err)
 			if err != nil {
 				return err
 			}
@@ -315,8 +368,11 @@ func (t *Task) unQuiesceDeployments(client k8sclient.Client, namespaces []string
 	return nil
 }
 
-// Scales down all StatefulSets.
-func (t *Task) quiesceStatefulSets(client k8sclient.Client) error {
+// Scales down all StatefulSets selected by the filter.
+// When ordered, the replicas are decreased one at a time once the
+// previously removed pod terminated, so pods terminate in reverse
+// ordinal order whatever the pod management policy.
+func (t *Task) quiesceStatefulSets(client k8sclient.Client, filter quiesceFilter, ordered bool) error {
 	zero := int32(0)
 	for _, ns := range t.sourceNamespaces() {
 		list := appsv1.StatefulSetList{}
@@ -329,20 +385,32 @@ func (t *Task) quiesceStatefulSets(client k8sclient.Client) error {
 			return err
 		}
 		for _, set := range list.Items {
-			t.Log.Info(fmt.Sprintf("Quiescing StatefulSet. "+
-				"Changing Spec.Replicas from [%v->%v]. "+
-				"Annotating with [%v: %v]",
-				set.Spec.Replicas, zero,
-				migapi.ReplicasAnnotation, set.Spec.Replicas),
-				"statefulSet", path.Join(set.Namespace, set.Name))
+			if !filter.selects(StatefulSetKind, &set) {
+				continue
+			}
 			if set.Annotations == nil {
 				set.Annotations = make(map[string]string)
 			}
 			if *set.Spec.Replicas == zero {
 				continue
 			}
-			set.Annotations[migapi.ReplicasAnnotation] = strconv.FormatInt(int64(*set.Spec.Replicas), 10)
-			set.Spec.Replicas = &zero
+			replicas := zero
+			if ordered {
+				if set.Status.Replicas > *set.Spec.Replicas {
+					continue
+				}
+				replicas = *set.Spec.Replicas - 1
+			}
+			t.Log.Info(fmt.Sprintf("Quiescing StatefulSet. "+
+				"Changing Spec.Replicas from [%v->%v]. "+
+				"Annotating with [%v: %v]",
+				*set.Spec.Replicas, replicas,
+				migapi.ReplicasAnnotation, *set.Spec.Replicas),
+				"statefulSet", path.Join(set.Namespace, set.Name))
+			if _, exist := set.Annotations[migapi.ReplicasAnnotation]; !exist || !ordered {
+				set.Annotations[migapi.ReplicasAnnotation] = strconv.FormatInt(int64(*set.Spec.Replicas), 10)
+			}
+			set.Spec.Replicas = &replicas
 			err = client.Update(context.TODO(), &set)
 			if err != nil {
 				return err
@@ -391,6 +459,8 @@ func (t *Task) unQuiesceStatefulSets(client k8sclient.Client, namespaces []strin
 				set.Spec.Replicas = &restoredReplicas
 			}
 			err = client.Update(context.TODO(), &set)
+			t.recordRestored("apps/v1", StatefulSetKind, &set,
+				fmt.Sprintf("Replicas restored to %d.", *set.Spec.Replicas), err)
 			if err != nil {
 				return err
 			}
@@ -399,8 +469,8 @@ func (t *Task) unQuiesceStatefulSets(client k8sclient.Client, namespaces []strin
 	return nil
 }
 
-// Scales down all ReplicaSets.
-func (t *Task) quiesceReplicaSets(client k8sclient.Client) error {
+// Scales down all ReplicaSets selected by the filter.
+func (t *Task) quiesceReplicaSets(client k8sclient.Client, filter quiesceFilter) error {
 	zero := int32(0)
 	for _, ns := range t.sourceNamespaces() {
 		list := appsv1.ReplicaSetList{}
@@ -418,6 +488,9 @@ func (t *Task) quiesceReplicaSets(client k8sclient.Client) error {
 					"replicaSet", path.Join(set.Namespace, set.Name))
 				continue
 			}
+			if !filter.selects(ReplicaSetKind, &set) {
+				continue
+			}
 			if set.Annotations == nil {
 				set.Annotations = make(map[string]string)
 			}
@@ -482,6 +555,8 @@ func (t *Task) unQuiesceReplicaSets(client k8sclient.Client, namespaces []string
 				0, restoredReplicas, migapi.ReplicasAnnotation),
 				"replicaSet", path.Join(set.Namespace, set.Name))
 			err = client.Update(context.TODO(), &set)
+			t.recordRestored("apps/v1", ReplicaSetKind, &set,
+				fmt.Sprintf("Replicas restored to %d.", *set.Spec.Replicas), err)
 			if err != nil {
 				return err
 			}
@@ -490,8 +565,8 @@ func (t *Task) unQuiesceReplicaSets(client k8sclient.Client, namespaces []string
 	return nil
 }
 
-// Scales down all DaemonSets.
-func (t *Task) quiesceDaemonSets(client k8sclient.Client) error {
+// Scales down all DaemonSets selected by the filter.
+func (t *Task) quiesceDaemonSets(client k8sclient.Client, filter quiesceFilter) error {
 	for _, ns := range t.sourceNamespaces() {
 		list := appsv1.DaemonSetList{}
 		options := k8sclient.InNamespace(ns)
@@ -503,6 +578,9 @@ func (t *Task) quiesceDaemonSets(client k8sclient.Client) error {
 			return err
 		}
 		for _, set := range list.Items {
+			if !filter.selects(DaemonSetKind, &set) {
+				continue
+			}
 			if set.Annotations == nil {
 				set.Annotations = make(map[string]string)
 			}
@@ -569,6 +647,8 @@ func (t *Task) unQuiesceDaemonSets(client k8sclient.Client, namespaces []string)
 				nodeSelector, migapi.NodeSelectorAnnotation),
 				"daemonSet", path.Join(set.Namespace, set.Name))
 			err = client.Update(context.TODO(), &set)
+			t.recordRestored("apps/v1", DaemonSetKind, &set,
+				fmt.Sprintf("Node selector restored to %v.", nodeSelector), err)
 			if err != nil {
 				return err
 			}
@@ -577,8 +657,8 @@ func (t *Task) unQuiesceDaemonSets(client k8sclient.Client, namespaces []string)
 	return nil
 }
 
-// Suspends all CronJobs
-func (t *Task) quiesceCronJobs(client compat.Client) error {
+// Suspends all CronJobs selected by the filter
+func (t *Task) quiesceCronJobs(client compat.Client, filter quiesceFilter) error {
 	for _, ns := range t.sourceNamespaces() {
 
 		if client.MinorVersion() < 21 {
@@ -589,10 +669,13 @@ func (t *Task) quiesceCronJobs(client compat.Client) error {
 				return err
 			}
 			for _, r := range list.Items {
+				if !filter.selects(CronJobKind, &r) {
+					continue
+				}
 				if r.Annotations == nil {
 					r.Annotations = make(map[string]string)
 				}
-				if r.Spec.Suspend == pointer.BoolPtr(true) {
+				if r.Spec.Suspend != nil && *r.Spec.Suspend {
 					continue
 				}
 				r.Annotations[migapi.SuspendAnnotation] = "true"
@@ -616,10 +699,13 @@ func (t *Task) quiesceCronJobs(client compat.Client) error {
 				return err
 			}
 			for _, r := range list.Items {
+				if !filter.selects(CronJobKind, &r) {
+					continue
+				}
 				if r.Annotations == nil {
 					r.Annotations = make(map[string]string)
 				}
-				if r.Spec.Suspend == pointer.BoolPtr(true) {
+				if r.Spec.Suspend != nil && *r.Spec.Suspend {
 					continue
 				}
 				r.Annotations[migapi.SuspendAnnotation] = "true"
@@ -664,6 +750,7 @@ func (t *Task) unQuiesceCronJobs(client compat.Client, namespaces []string) erro
 				t.Log.Info("Unquiescing Cron Job. Setting [Spec.Suspend=false]",
 					"cronJob", path.Join(r.Namespace, r.Name))
 				err = client.Update(context.TODO(), &r)
+				t.recordRestored("batch/v1beta1", CronJobKind, &r, "Suspend restored to false.", err)
 				if err != nil {
 					return err
 				}
@@ -689,6 +776,7 @@ func (t *Task) unQuiesceCronJobs(client compat.Client, namespaces []string) erro
 				t.Log.Info("Unquiescing Cron Job. Setting [Spec.Suspend=false]",
 					"cronJob", path.Join(r.Namespace, r.Name))
 				err = client.Update(context.TODO(), &r)
+				t.recordRestored("batch/v1", CronJobKind, &r, "Suspend restored to false.", err)
 				if err != nil {
 					return err
 				}
@@ -700,8 +788,8 @@ func (t *Task) unQuiesceCronJobs(client compat.Client, namespaces []string) erro
 	return nil
 }
 
-// Scales down all Jobs
-func (t *Task) quiesceJobs(client k8sclient.Client) error {
+// Scales down all Jobs selected by the filter
+func (t *Task) quiesceJobs(client k8sclient.Client, filter quiesceFilter) error {
 	zero := int32(0)
 	for _, ns := range t.sourceNamespaces() {
 		list := batchv1.JobList{}
@@ -714,10 +802,13 @@ func (t *Task) quiesceJobs(client k8sclient.Client) error {
 			return err
 		}
 		for _, job := range list.Items {
+			if !filter.selects(JobKind, &job) {
+				continue
+			}
 			if job.Annotations == nil {
 				job.Annotations = make(map[string]string)
 			}
-			if job.Spec.Parallelism == &zero {
+			if job.Spec.Parallelism == nil || *job.Spec.Parallelism == zero {
 				continue
 			}
 			job.Annotations[migapi.ReplicasAnnotation] = strconv.FormatInt(int64(*job.Spec.Parallelism), 10)
@@ -773,6 +864,8 @@ func (t *Task) unQuiesceJobs(client k8sclient.Client, namespaces []string) error
 				parallelReplicas, migapi.ReplicasAnnotation),
 				"job", path.Join(job.Namespace, job.Name))
 			err = client.Update(context.TODO(), &job)
+			t.recordRestored("batch/v1", JobKind, &job,
+				fmt.Sprintf("Parallelism restored to %d.", *job.Spec.Parallelism), err)
 			if err != nil {
 				return err
 			}
@@ -783,8 +876,14 @@ func (t *Task) unQuiesceJobs(client k8sclient.Client, namespaces []string) error
 }
 
 // Ensure scaled down pods have terminated.
+// With a quiesce policy, the workloads of the following stages are
+// quiesced as the pods of the previous stage terminate.
 // Returns: `true` when all pods terminated.
 func (t *Task) ensureQuiescedPodsTerminated() (bool, error) {
+	policy := t.quiescePolicy()
+	if policy != nil {
+		return t.ensureQuiesced(policy)
+	}
 	kinds := map[string]bool{
 		"ReplicationController": true,
 		"StatefulSet":           true,
@@ -801,6 +900,7 @@ func (t *Task) ensureQuiescedPodsTerminated() (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	blocking := []string{}
 	for _, ns := range t.sourceNamespaces() {
 		list := v1.PodList{}
 		options := k8sclient.InNamespace(ns)
@@ -812,23 +912,21 @@ func (t *Task) ensureQuiescedPodsTerminated() (bool, error) {
 			return false, err
 		}
 		for _, pod := range list.Items {
-			if pod.Annotations == nil {
-				pod.Annotations = make(map[string]string)
-			}
 			if _, found := skippedPhases[pod.Status.Phase]; found {
 				continue
 			}
 			for _, ref := range pod.OwnerReferences {
 				if _, found := kinds[ref.Kind]; found {
-					t.Log.Info("Found quiesced Pod on source cluster"+
-						" that has not yet terminated. Waiting.",
-						"pod", path.Join(pod.Namespace, pod.Name),
-						"podPhase", pod.Status.Phase)
-					return false, nil
+					blocking = append(blocking, path.Join(pod.Namespace, pod.Name))
+					break
 				}
 			}
 		}
 	}
+	if len(blocking) > 0 {
+		t.setQuiescedPodsRunning("default", blocking)
+		return false, nil
+	}
 
 	return true, nil
 }
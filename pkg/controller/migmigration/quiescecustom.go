@@ -0,0 +1,321 @@
+package migmigration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// List the custom resources of a scale rule in the namespace.
+// Returns an empty list when the custom resource is not installed.
+func listCustomResources(client compat.Client, rule *migapi.QuiesceCustomResource, ns string) ([]unstructured.Unstructured, error) {
+	list := unstructured.UnstructuredList{}
+	gvk := rule.GroupVersionKind()
+	gvk.Kind += "List"
+	list.SetGroupVersionKind(gvk)
+	err := client.List(context.TODO(), &list, k8sclient.InNamespace(ns))
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// List the custom resources with a scale rule in the namespace.
+func listQuiesceCustomResources(client compat.Client, policy *migapi.QuiescePolicy, ns string) ([]quiesceWorkload, error) {
+	workloads := []quiesceWorkload{}
+	if policy == nil {
+		return workloads, nil
+	}
+	for i := range policy.CustomResources {
+		rule := &policy.CustomResources[i]
+		items, err := listCustomResources(client, rule, ns)
+		if err != nil {
+			return nil, err
+		}
+		for j := range items {
+			workloads = append(workloads, quiesceWorkload{kind: rule.Kind, object: &items[j]})
+		}
+	}
+	return workloads, nil
+}
+
+// Scale down or pause the custom resources selected by the filter
+// following the scale rules of the quiesce policy.
+func (t *Task) quiesceCustomResources(client compat.Client, policy *migapi.QuiescePolicy, filter quiesceFilter) error {
+	if policy == nil {
+		return nil
+	}
+	for _, ns := range t.sourceNamespaces() {
+		for i := range policy.CustomResources {
+			rule := &policy.CustomResources[i]
+			items, err := listCustomResources(client, rule, ns)
+			if err != nil {
+				return err
+			}
+			for j := range items {
+				resource := &items[j]
+				if !filter.selects(rule.Kind, resource) {
+					continue
+				}
+				changed, err := quiesceCustomResource(rule, resource)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					continue
+				}
+				t.Log.Info("Quiescing custom resource.",
+					"kind", rule.Kind,
+					"resource", path.Join(resource.GetNamespace(), resource.GetName()))
+				err = client.Update(context.TODO(), resource)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Restore the custom resources annotated when quiesced
+// following the scale rules of the quiesce policy.
+// Only the kinds included in the (optional) rollback scope are restored.
+func (t *Task) unQuiesceCustomResources(client compat.Client, policy *migapi.QuiescePolicy, namespaces []string, scope *migapi.RollbackScope) error {
+	if policy == nil {
+		return nil
+	}
+	for _, ns := range namespaces {
+		for i := range policy.CustomResources {
+			rule := &policy.CustomResources[i]
+			if !scope.Includes(rule.GroupVersionKind().GroupKind()) {
+				continue
+			}
+			items, err := listCustomResources(client, rule, ns)
+			if err != nil {
+				return err
+			}
+			for j := range items {
+				resource := &items[j]
+				changed, err := unQuiesceCustomResource(rule, resource)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					continue
+				}
+				t.Log.Info("Unquiescing custom resource.",
+					"kind", rule.Kind,
+					"resource", path.Join(resource.GetNamespace(), resource.GetName()))
+				err = client.Update(context.TODO(), resource)
+				t.recordRestored(resource.GetAPIVersion(), rule.Kind, resource,
+					fmt.Sprintf("%s restored.", rule.ReplicasPath+rule.PausePath), err)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get the policy used to restore the quiesced custom resources.
+// The custom resources may have been quiesced by another migration of
+// the plan, such as the migration rolled back, following the rules of
+// its own quiesce policy. The scale rules of the policy of this migration
+// come first, followed by the rules of the plan and of the other
+// migrations of the plan, the most recent first.
+func (t *Task) unQuiescePolicy() (*migapi.QuiescePolicy, error) {
+	policies := []*migapi.QuiescePolicy{t.quiescePolicy()}
+	if t.PlanResources == nil || t.PlanResources.MigPlan == nil {
+		return mergeQuiesceCustomResources(policies...), nil
+	}
+	policies = append(policies, t.PlanResources.MigPlan.Spec.QuiescePolicy)
+	migrations, err := t.PlanResources.MigPlan.ListMigrations(t.Client)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[j].CreationTimestamp.Before(&migrations[i].CreationTimestamp)
+	})
+	for _, migration := range migrations {
+		if migration.UID != t.Owner.UID {
+			policies = append(policies, migration.Spec.QuiescePolicy)
+		}
+	}
+	return mergeQuiesceCustomResources(policies...), nil
+}
+
+// Merge the scale rules of the custom resources of the policies.
+// The first rule found for a kind is used.
+// Returns: `nil` when no policy defines scale rules.
+func mergeQuiesceCustomResources(policies ...*migapi.QuiescePolicy) *migapi.QuiescePolicy {
+	merged := &migapi.QuiescePolicy{}
+	for _, policy := range policies {
+		if policy == nil {
+			continue
+		}
+		for _, rule := range policy.CustomResources {
+			if merged.FindCustomResource(rule.Group, rule.Kind) == nil {
+				merged.CustomResources = append(merged.CustomResources, *rule.DeepCopy())
+			}
+		}
+	}
+	if len(merged.CustomResources) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// Scale down or pause a custom resource.
+// The original value is saved in the same annotations used for the
+// built-in kinds: the replicas in the ReplicasAnnotation and the JSON
+// encoded pause field in the PausedAnnotation. An empty annotation
+// means the field was not set.
+// Returns: `true` when the resource has been changed.
+func quiesceCustomResource(rule *migapi.QuiesceCustomResource, resource *unstructured.Unstructured) (bool, error) {
+	annotations := resource.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if rule.ReplicasPath != "" {
+		if _, found := annotations[migapi.ReplicasAnnotation]; found {
+			return false, nil
+		}
+		fields, err := migapi.ParseFieldPath(rule.ReplicasPath)
+		if err != nil {
+			return false, err
+		}
+		replicas, found, err := nestedReplicas(resource, fields)
+		if err != nil {
+			return false, err
+		}
+		if found && replicas == 0 {
+			return false, nil
+		}
+		annotations[migapi.ReplicasAnnotation] = ""
+		if found {
+			annotations[migapi.ReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+		}
+		err = unstructured.SetNestedField(resource.Object, int64(0), fields...)
+		if err != nil {
+			return false, err
+		}
+		resource.SetAnnotations(annotations)
+		return true, nil
+	}
+	if _, found := annotations[migapi.PausedAnnotation]; found {
+		return false, nil
+	}
+	fields, err := migapi.ParseFieldPath(rule.PausePath)
+	if err != nil {
+		return false, err
+	}
+	value := rule.GetPauseValue()
+	current, found, err := unstructured.NestedFieldNoCopy(resource.Object, fields...)
+	if err != nil {
+		return false, err
+	}
+	original := ""
+	if found {
+		encoded, err := json.Marshal(current)
+		if err != nil {
+			return false, err
+		}
+		pause, err := json.Marshal(value)
+		if err != nil {
+			return false, err
+		}
+		if string(encoded) == string(pause) {
+			return false, nil
+		}
+		original = string(encoded)
+	}
+	annotations[migapi.PausedAnnotation] = original
+	err = unstructured.SetNestedField(resource.Object, value, fields...)
+	if err != nil {
+		return false, err
+	}
+	resource.SetAnnotations(annotations)
+	return true, nil
+}
+
+// Restore a custom resource scaled down or paused by quiesceCustomResource().
+// Returns: `true` when the resource has been changed.
+func unQuiesceCustomResource(rule *migapi.QuiesceCustomResource, resource *unstructured.Unstructured) (bool, error) {
+	annotations := resource.GetAnnotations()
+	if annotations == nil {
+		return false, nil
+	}
+	key := migapi.PausedAnnotation
+	fieldPath := rule.PausePath
+	if rule.ReplicasPath != "" {
+		key = migapi.ReplicasAnnotation
+		fieldPath = rule.ReplicasPath
+	}
+	original, found := annotations[key]
+	if !found {
+		return false, nil
+	}
+	fields, err := migapi.ParseFieldPath(fieldPath)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case original == "":
+		unstructured.RemoveNestedField(resource.Object, fields...)
+	case rule.ReplicasPath != "":
+		replicas, err := strconv.ParseInt(original, 10, 64)
+		if err != nil {
+			return false, err
+		}
+		err = unstructured.SetNestedField(resource.Object, replicas, fields...)
+		if err != nil {
+			return false, err
+		}
+	default:
+		var value interface{}
+		err = json.Unmarshal([]byte(original), &value)
+		if err != nil {
+			return false, err
+		}
+		err = unstructured.SetNestedField(resource.Object, value, fields...)
+		if err != nil {
+			return false, err
+		}
+	}
+	delete(annotations, key)
+	resource.SetAnnotations(annotations)
+	return true, nil
+}
+
+// Get the replica field of a custom resource.
+func nestedReplicas(resource *unstructured.Unstructured, fields []string) (int64, bool, error) {
+	value, found, err := unstructured.NestedFieldNoCopy(resource.Object, fields...)
+	if err != nil || !found {
+		return 0, found, err
+	}
+	switch n := value.(type) {
+	case int64:
+		return n, true, nil
+	case float64:
+		return int64(n), true, nil
+	default:
+		return 0, true, fmt.Errorf("%s of %s %s is not an integer",
+			strings.Join(fields, "."), resource.GetKind(), resource.GetName())
+	}
+}
@@ -0,0 +1,363 @@
+package migmigration
+
+import (
+	"context"
+	"path"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/compat"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Quiesced workload kinds.
+const (
+	DeploymentKind  = "Deployment"
+	StatefulSetKind = "StatefulSet"
+	ReplicaSetKind  = "ReplicaSet"
+	DaemonSetKind   = "DaemonSet"
+	CronJobKind     = "CronJob"
+	JobKind         = "Job"
+)
+
+// Selects the workloads quiesced by the migration.
+// A `nil` filter selects all workloads.
+type quiesceFilter func(kind string, object metav1.Object) bool
+
+// Get whether the workload is selected.
+func (f quiesceFilter) selects(kind string, object metav1.Object) bool {
+	return f == nil || f(kind, object)
+}
+
+// A workload in the source namespaces.
+type quiesceWorkload struct {
+	kind   string
+	object metav1.Object
+}
+
+// Get the quiesce policy of the migration.
+// Falls back to the policy of the plan, `nil` when neither is set.
+func (t *Task) quiescePolicy() *migapi.QuiescePolicy {
+	if t.Owner.Spec.QuiescePolicy != nil {
+		return t.Owner.Spec.QuiescePolicy
+	}
+	if t.PlanResources != nil && t.PlanResources.MigPlan != nil {
+		return t.PlanResources.MigPlan.Spec.QuiescePolicy
+	}
+	return nil
+}
+
+// Build the filter selecting the workloads quiesced at the specified stage.
+// The workloads not matching the selector of any stage belong to the last
+// stage, numbered len(policy.Stages). Workloads owned by a custom resource
+// with a scale rule are left to their operator. Returns a `nil` filter
+// without policy.
+func newQuiesceFilter(policy *migapi.QuiescePolicy, stage int) (quiesceFilter, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	selector := labels.Everything()
+	if policy.Selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(policy.Selector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	exclude := labels.Nothing()
+	if policy.ExcludeSelector != nil {
+		var err error
+		exclude, err = metav1.LabelSelectorAsSelector(policy.ExcludeSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	stages := []labels.Selector{}
+	for _, s := range policy.Stages {
+		stageSelector, err := metav1.LabelSelectorAsSelector(s.Selector)
+		if err != nil {
+			return nil, err
+		}
+		stages = append(stages, stageSelector)
+	}
+	filter := func(kind string, object metav1.Object) bool {
+		if settings := policy.FindKind(kind); settings != nil && settings.Skip {
+			return false
+		}
+		if policy.Excluded(kind, object) || policy.OwnedByCustomResource(object) {
+			return false
+		}
+		set := labels.Set(object.GetLabels())
+		if !selector.Matches(set) || exclude.Matches(set) {
+			return false
+		}
+		for i := range stages {
+			if stages[i].Matches(set) {
+				return i == stage
+			}
+		}
+		return stage == len(stages)
+	}
+
+	return filter, nil
+}
+
+// Build a filter selecting the workloads quiesced at any stage.
+func newQuiescePolicyFilter(policy *migapi.QuiescePolicy) (quiesceFilter, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	filters := []quiesceFilter{}
+	for stage := 0; stage <= len(policy.Stages); stage++ {
+		filter, err := newQuiesceFilter(policy, stage)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	filter := func(kind string, object metav1.Object) bool {
+		for _, f := range filters {
+			if f.selects(kind, object) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return filter, nil
+}
+
+// Quiesce the workloads stage by stage following the quiesce policy.
+// The workloads of a stage are quiesced once the pods of the previous
+// stages have terminated.
+// Returns: `true` when the pods of all stages terminated.
+func (t *Task) ensureQuiesced(policy *migapi.QuiescePolicy) (bool, error) {
+	client, err := t.getSourceClient()
+	if err != nil {
+		return false, err
+	}
+	for stage := 0; stage <= len(policy.Stages); stage++ {
+		filter, err := newQuiesceFilter(policy, stage)
+		if err != nil {
+			return false, err
+		}
+		err = t.quiesceWorkloads(client, policy, filter)
+		if err != nil {
+			return false, err
+		}
+		blocking, err := t.findQuiesceBlockingPods(client, policy, filter)
+		if err != nil {
+			return false, err
+		}
+		if len(blocking) > 0 {
+			name := "default"
+			if stage < len(policy.Stages) {
+				name = policy.Stages[stage].Name
+			}
+			t.setQuiescedPodsRunning(name, blocking)
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Find the pods of the workloads selected by the filter that have not
+// terminated. Pods that outlived the graceful timeout of their
+// workload kind are deleted forcefully.
+// Returns the blocking pods as namespace/name.
+func (t *Task) findQuiesceBlockingPods(client compat.Client, policy *migapi.QuiescePolicy, filter quiesceFilter) ([]string, error) {
+	blocking := []string{}
+	for _, ns := range t.sourceNamespaces() {
+		workloads, err := listQuiesceCustomResources(client, policy, ns)
+		if err != nil {
+			return nil, err
+		}
+		builtin, err := t.listQuiesceWorkloads(client, ns)
+		if err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, builtin...)
+		owners := quiesceOwners(workloads, filter)
+		list := v1.PodList{}
+		err = client.List(context.TODO(), &list, k8sclient.InNamespace(ns))
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			pod := &list.Items[i]
+			if pod.Status.Phase == v1.PodSucceeded ||
+				pod.Status.Phase == v1.PodFailed ||
+				pod.Status.Phase == v1.PodUnknown {
+				continue
+			}
+			for _, ref := range pod.OwnerReferences {
+				kind, found := owners[ref.UID]
+				if !found {
+					continue
+				}
+				deleted, err := t.deleteTimedOutPod(client, policy.FindKind(kind), pod)
+				if err != nil {
+					return nil, err
+				}
+				if !deleted {
+					blocking = append(blocking, path.Join(pod.Namespace, pod.Name))
+				}
+				break
+			}
+		}
+	}
+
+	return blocking, nil
+}
+
+// Map the UIDs of the selected workloads to their kind, including the
+// workloads they own such as the ReplicaSets of Deployments, the Jobs of
+// CronJobs and the workloads of custom resources.
+func quiesceOwners(workloads []quiesceWorkload, filter quiesceFilter) map[types.UID]string {
+	owners := map[types.UID]string{}
+	for _, w := range workloads {
+		if filter.selects(w.kind, w.object) {
+			owners[w.object.GetUID()] = w.kind
+		}
+	}
+	for added := true; added; {
+		added = false
+		for _, w := range workloads {
+			if _, found := owners[w.object.GetUID()]; found {
+				continue
+			}
+			for _, ref := range w.object.GetOwnerReferences() {
+				if kind, found := owners[ref.UID]; found {
+					owners[w.object.GetUID()] = kind
+					added = true
+					break
+				}
+			}
+		}
+	}
+	return owners
+}
+
+// Forcefully delete a terminating pod that outlived the graceful timeout.
+// Returns: `true` when deleted.
+func (t *Task) deleteTimedOutPod(client compat.Client, settings *migapi.QuiesceKind, pod *v1.Pod) (bool, error) {
+	if settings == nil || settings.GracefulTimeout == nil || pod.DeletionTimestamp == nil {
+		return false, nil
+	}
+	deadline := podDeletionRequested(pod).Add(settings.GracefulTimeout.Duration)
+	if time.Now().Before(deadline) {
+		return false, nil
+	}
+	t.Log.Info("Forcefully deleting quiesced Pod on source cluster "+
+		"that did not terminate within the graceful timeout.",
+		"pod", path.Join(pod.Namespace, pod.Name),
+		"kind", settings.Kind,
+		"gracefulTimeout", settings.GracefulTimeout.Duration)
+	err := client.Delete(context.TODO(), pod, k8sclient.GracePeriodSeconds(0))
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+// Get when the deletion of a terminating pod was requested.
+// The deletion timestamp of the pod is the end of its grace period.
+func podDeletionRequested(pod *v1.Pod) time.Time {
+	requested := pod.DeletionTimestamp.Time
+	if pod.DeletionGracePeriodSeconds != nil {
+		requested = requested.Add(-time.Duration(*pod.DeletionGracePeriodSeconds) * time.Second)
+	}
+	return requested
+}
+
+// List the quiesced workload kinds in the namespace.
+func (t *Task) listQuiesceWorkloads(client compat.Client, ns string) ([]quiesceWorkload, error) {
+	workloads := []quiesceWorkload{}
+	options := k8sclient.InNamespace(ns)
+	deployments := appsv1.DeploymentList{}
+	err := client.List(context.TODO(), &deployments, options)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		workloads = append(workloads, quiesceWorkload{kind: DeploymentKind, object: &deployments.Items[i]})
+	}
+	statefulSets := appsv1.StatefulSetList{}
+	err = client.List(context.TODO(), &statefulSets, options)
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		workloads = append(workloads, quiesceWorkload{kind: StatefulSetKind, object: &statefulSets.Items[i]})
+	}
+	replicaSets := appsv1.ReplicaSetList{}
+	err = client.List(context.TODO(), &replicaSets, options)
+	if err != nil {
+		return nil, err
+	}
+	for i := range replicaSets.Items {
+		workloads = append(workloads, quiesceWorkload{kind: ReplicaSetKind, object: &replicaSets.Items[i]})
+	}
+	daemonSets := appsv1.DaemonSetList{}
+	err = client.List(context.TODO(), &daemonSets, options)
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		workloads = append(workloads, quiesceWorkload{kind: DaemonSetKind, object: &daemonSets.Items[i]})
+	}
+	jobs := batchv1.JobList{}
+	err = client.List(context.TODO(), &jobs, options)
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs.Items {
+		workloads = append(workloads, quiesceWorkload{kind: JobKind, object: &jobs.Items[i]})
+	}
+	if client.MinorVersion() < 21 {
+		cronJobs := batchv1beta.CronJobList{}
+		err = client.List(context.TODO(), &cronJobs, options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range cronJobs.Items {
+			workloads = append(workloads, quiesceWorkload{kind: CronJobKind, object: &cronJobs.Items[i]})
+		}
+	} else {
+		cronJobs := batchv1.CronJobList{}
+		err = client.List(context.TODO(), &cronJobs, options)
+		if err != nil {
+			return nil, err
+		}
+		for i := range cronJobs.Items {
+			workloads = append(workloads, quiesceWorkload{kind: CronJobKind, object: &cronJobs.Items[i]})
+		}
+	}
+
+	return workloads, nil
+}
+
+// Report the quiesced pods that have not terminated.
+func (t *Task) setQuiescedPodsRunning(stage string, pods []string) {
+	t.Log.Info("Found quiesced Pods on source cluster"+
+		" that have not yet terminated. Waiting.",
+		"stage", stage,
+		"pods", pods)
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     QuiescedPodsRunning,
+		Status:   True,
+		Reason:   stage,
+		Category: Advisory,
+		Message:  "Waiting for quiesced Pods [] to terminate.",
+		Items:    pods,
+	})
+}
@@ -0,0 +1,456 @@
+package migmigration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	velero "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MigrationReport summarizes a completed migration.
+// Migration - The migration name.
+// Namespace - The migration namespace.
+// Plan - The plan name.
+// PlanDigest - The digest of the plan spec.
+// Type - The migration type: Stage, Final or Rollback.
+// Result - The migration result: Succeeded, SucceededWithWarnings, Failed or Canceled.
+// Started - When the migration started.
+// Completed - When the migration completed.
+// Duration - The migration duration.
+// Steps - The pipeline steps.
+// Velero - The Velero backups and restores.
+// Volumes - The volumes copied by restic and rsync.
+// BytesTransferred - The bytes copied by restic and rsync.
+// Hooks - The results of the exec hooks.
+// Warnings - The warnings reported by the migration.
+// Errors - The errors reported by the migration.
+// Unhealthy - The unhealthy resources found by the verification.
+type MigrationReport struct {
+	Migration        string                      `json:"migration"`
+	Namespace        string                      `json:"namespace"`
+	Plan             string                      `json:"plan"`
+	PlanDigest       string                      `json:"planDigest"`
+	Type             string                      `json:"type"`
+	Result           string                      `json:"result"`
+	Started          *metav1.Time                `json:"started,omitempty"`
+	Completed        *metav1.Time                `json:"completed,omitempty"`
+	Duration         string                      `json:"duration,omitempty"`
+	Steps            []ReportStep                `json:"steps,omitempty"`
+	Velero           []ReportVelero              `json:"velero,omitempty"`
+	Volumes          []ReportVolume              `json:"volumes,omitempty"`
+	BytesTransferred int64                       `json:"bytesTransferred"`
+	Hooks            []migapi.HookExecResult     `json:"hooks,omitempty"`
+	Warnings         []string                    `json:"warnings,omitempty"`
+	Errors           []string                    `json:"errors,omitempty"`
+	Unhealthy        []migapi.UnhealthyNamespace `json:"unhealthy,omitempty"`
+}
+
+// ReportStep summarizes a pipeline step.
+// Name - The step name.
+// Duration - The step duration.
+// Message - The step message.
+// Progress - The step progress, including the results of the hooks.
+// Failed - The step has failed.
+// Skipped - The step was skipped.
+type ReportStep struct {
+	migapi.Timed `json:",inline"`
+	Name         string   `json:"name"`
+	Duration     string   `json:"duration,omitempty"`
+	Message      string   `json:"message,omitempty"`
+	Progress     []string `json:"progress,omitempty"`
+	Failed       bool     `json:"failed,omitempty"`
+	Skipped      bool     `json:"skipped,omitempty"`
+}
+
+// ReportVelero summarizes a Velero backup or restore.
+// Kind - Backup or Restore.
+// Name - The Velero CR name.
+// Phase - The Velero phase.
+// Items - The items backed up or restored.
+// TotalItems - The items to back up or restore.
+// Warnings - The number of warnings.
+// Errors - The number of errors.
+type ReportVelero struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Phase      string `json:"phase"`
+	Items      int    `json:"items"`
+	TotalItems int    `json:"totalItems"`
+	Warnings   int    `json:"warnings"`
+	Errors     int    `json:"errors"`
+}
+
+// ReportVolume summarizes the copy of a volume.
+// Method - restic or rsync.
+// Namespace - The PVC namespace.
+// Name - The PVC name.
+// Phase - The copy phase.
+// BytesDone - The bytes copied, reported by restic only.
+// TotalBytes - The bytes to copy with restic, the PV capacity with rsync.
+// Progress - The rsync progress percentage.
+// TransferRate - The rsync transfer rate.
+// Duration - The copy duration.
+type ReportVolume struct {
+	Method       string `json:"method"`
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	Phase        string `json:"phase,omitempty"`
+	BytesDone    int64  `json:"bytesDone,omitempty"`
+	TotalBytes   int64  `json:"totalBytes,omitempty"`
+	Progress     string `json:"progress,omitempty"`
+	TransferRate string `json:"transferRate,omitempty"`
+	Duration     string `json:"duration,omitempty"`
+}
+
+// Generate the report of the completed migration and write it to
+// a ConfigMap owned by the migration.
+func (t *Task) ensureReport() error {
+	report, err := t.buildReport()
+	if err != nil {
+		return err
+	}
+	cm, err := t.ensureReportConfigMap(report)
+	if err != nil {
+		return err
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     MigrationReported,
+		Status:   True,
+		Reason:   report.Result,
+		Category: Advisory,
+		Message: fmt.Sprintf("The migration report is in ConfigMap %s.",
+			path.Join(cm.Namespace, cm.Name)),
+		Durable: true,
+	})
+	t.Log.Info("Migration report created.",
+		"configMap", path.Join(cm.Namespace, cm.Name),
+		"result", report.Result)
+	return nil
+}
+
+// Build the report of the migration.
+func (t *Task) buildReport() (*MigrationReport, error) {
+	plan := t.PlanResources.MigPlan
+	report := &MigrationReport{
+		Migration:  t.Owner.Name,
+		Namespace:  t.Owner.Namespace,
+		Plan:       plan.Name,
+		PlanDigest: plan.GetSpecDigest(),
+		Type:       t.reportType(),
+		Result:     t.reportResult(),
+		Started:    t.Owner.Status.StartTimestamp,
+		Completed:  &metav1.Time{Time: time.Now()},
+		Hooks:      t.Owner.Status.HookExecResults,
+		Errors:     t.Owner.Status.Errors,
+		Unhealthy:  t.Owner.Status.UnhealthyResources.Namespaces,
+	}
+	if report.Started != nil {
+		report.Duration = report.Completed.Sub(report.Started.Time).Round(time.Second).String()
+	}
+	for _, step := range t.Owner.Status.Pipeline {
+		entry := ReportStep{
+			Timed:    step.Timed,
+			Name:     step.Name,
+			Message:  step.Message,
+			Progress: step.Progress,
+			Failed:   step.Failed,
+			Skipped:  step.Skipped,
+		}
+		if step.Started != nil && step.Completed != nil {
+			entry.Duration = step.Completed.Sub(step.Started.Time).Round(time.Second).String()
+		}
+		report.Steps = append(report.Steps, entry)
+	}
+	for _, condition := range t.Owner.Status.FindConditionByCategory(migapi.Warn) {
+		report.Warnings = append(report.Warnings, condition.Message)
+	}
+	err := t.reportVelero(report)
+	if err != nil {
+		return nil, err
+	}
+	err = t.reportDirectVolumes(report)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Get the migration type.
+func (t *Task) reportType() string {
+	switch {
+	case t.rollback():
+		return "Rollback"
+	case t.stage():
+		return "Stage"
+	default:
+		return "Final"
+	}
+}
+
+// Get the migration result.
+func (t *Task) reportResult() string {
+	switch {
+	case t.canceled():
+		return Canceled
+	case t.failed():
+		return migapi.Failed
+	case len(t.Owner.Status.FindConditionByCategory(migapi.Warn)) > 0:
+		return SucceededWithWarnings
+	default:
+		return migapi.Succeeded
+	}
+}
+
+// Add the Velero backups and restores and the volumes copied by restic.
+func (t *Task) reportVelero(report *MigrationReport) error {
+	backups := []*velero.Backup{}
+	backup, err := t.getInitialBackup()
+	if err != nil {
+		return err
+	}
+	if backup != nil {
+		backups = append(backups, backup)
+	}
+	backup, err = t.getStageBackup()
+	if err != nil {
+		return err
+	}
+	if backup != nil {
+		backups = append(backups, backup)
+	}
+	for _, backup := range backups {
+		items, total := getBackupStats(backup)
+		report.Velero = append(report.Velero, ReportVelero{
+			Kind:       "Backup",
+			Name:       backup.Name,
+			Phase:      string(backup.Status.Phase),
+			Items:      items,
+			TotalItems: total,
+			Warnings:   backup.Status.Warnings,
+			Errors:     backup.Status.Errors,
+		})
+		for _, pvb := range t.getPodVolumeBackupsForBackup(backup).Items {
+			volume := ReportVolume{
+				Method:     "restic",
+				Namespace:  pvb.Spec.Pod.Namespace,
+				Name:       pvb.Spec.Volume,
+				Phase:      string(pvb.Status.Phase),
+				BytesDone:  pvb.Status.Progress.BytesDone,
+				TotalBytes: pvb.Status.Progress.TotalBytes,
+			}
+			if pvb.Status.StartTimestamp != nil && pvb.Status.CompletionTimestamp != nil {
+				volume.Duration = pvb.Status.CompletionTimestamp.Sub(pvb.Status.StartTimestamp.Time).Round(time.Second).String()
+			}
+			report.Volumes = append(report.Volumes, volume)
+			report.BytesTransferred += volume.BytesDone
+		}
+	}
+	restores := []*velero.Restore{}
+	restore, err := t.getStageRestore()
+	if err != nil {
+		return err
+	}
+	if restore != nil {
+		restores = append(restores, restore)
+	}
+	restore, err = t.getFinalRestore()
+	if err != nil {
+		return err
+	}
+	if restore != nil {
+		restores = append(restores, restore)
+	}
+	for _, restore := range restores {
+		items, total := getRestoreStats(restore)
+		report.Velero = append(report.Velero, ReportVelero{
+			Kind:       "Restore",
+			Name:       restore.Name,
+			Phase:      string(restore.Status.Phase),
+			Items:      items,
+			TotalItems: total,
+			Warnings:   restore.Status.Warnings,
+			Errors:     restore.Status.Errors,
+		})
+	}
+	return nil
+}
+
+// Add the volumes copied by rsync.
+func (t *Task) reportDirectVolumes(report *MigrationReport) error {
+	dvm, err := t.getDirectVolumeMigration()
+	if err != nil {
+		return err
+	}
+	if dvm == nil {
+		return nil
+	}
+	add := func(pods []*migapi.PodProgress, phase string) {
+		for _, pod := range pods {
+			if pod.PVCReference == nil {
+				continue
+			}
+			volume := ReportVolume{
+				Method:       "rsync",
+				Namespace:    pod.PVCReference.Namespace,
+				Name:         pod.PVCReference.Name,
+				Phase:        phase,
+				Progress:     pod.LastObservedProgressPercent,
+				TransferRate: pod.LastObservedTransferRate,
+			}
+			if pod.TotalElapsedTime != nil {
+				volume.Duration = pod.TotalElapsedTime.Round(time.Second).String()
+			}
+			for _, pv := range t.PlanResources.MigPlan.Spec.PersistentVolumes.List {
+				if pv.PVC.Namespace == volume.Namespace && pv.PVC.GetSourceName() == volume.Name {
+					volume.TotalBytes = pv.Capacity.Value()
+					break
+				}
+			}
+			// the bytes transferred are reported by the rsync statistics
+			operation := dvm.Status.GetRsyncOperationStatusForPVC(pod.PVCReference)
+			if operation.Stats != nil {
+				volume.BytesDone = operation.Stats.BytesTransferred
+			}
+			report.Volumes = append(report.Volumes, volume)
+			report.BytesTransferred += volume.BytesDone
+		}
+	}
+	add(dvm.Status.SuccessfulPods, "Succeeded")
+	add(dvm.Status.FailedPods, "Failed")
+	return nil
+}
+
+// Create or update the report ConfigMap.
+func (t *Task) ensureReportConfigMap(report *MigrationReport) (*corev1.ConfigMap, error) {
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]string{
+		migapi.MigrationReportJSONKey:     string(content),
+		migapi.MigrationReportMarkdownKey: report.Markdown(),
+	}
+	cm := &corev1.ConfigMap{}
+	key := k8sclient.ObjectKey{
+		Namespace: t.Owner.Namespace,
+		Name:      t.Owner.GetReportConfigMapName(),
+	}
+	err = t.Client.Get(context.TODO(), key, cm)
+	if err != nil && !k8serror.IsNotFound(err) {
+		return nil, err
+	}
+	if k8serror.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels:    t.Owner.GetCorrelationLabels(),
+			},
+			Data: data,
+		}
+		migapi.SetOwnerReference(t.Owner, t.Owner, cm)
+		err = t.Client.Create(context.TODO(), cm)
+		return cm, err
+	}
+	cm.Data = data
+	err = t.Client.Update(context.TODO(), cm)
+	return cm, err
+}
+
+// Markdown renders the report as a Markdown document.
+func (r *MigrationReport) Markdown() string {
+	md := &strings.Builder{}
+	fmt.Fprintf(md, "# Migration %s/%s\n\n", r.Namespace, r.Migration)
+	fmt.Fprintf(md, "| | |\n|---|---|\n")
+	fmt.Fprintf(md, "| Plan | %s |\n", r.Plan)
+	fmt.Fprintf(md, "| Plan digest | `%s` |\n", r.PlanDigest)
+	fmt.Fprintf(md, "| Type | %s |\n", r.Type)
+	fmt.Fprintf(md, "| Result | %s |\n", r.Result)
+	if r.Started != nil {
+		fmt.Fprintf(md, "| Started | %s |\n", r.Started.UTC().Format(time.RFC3339))
+	}
+	if r.Completed != nil {
+		fmt.Fprintf(md, "| Completed | %s |\n", r.Completed.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(md, "| Duration | %s |\n", r.Duration)
+	fmt.Fprintf(md, "| Bytes transferred | %d |\n", r.BytesTransferred)
+	if len(r.Steps) > 0 {
+		fmt.Fprintf(md, "\n## Steps\n\n| Step | Status | Duration |\n|---|---|---|\n")
+		for _, step := range r.Steps {
+			fmt.Fprintf(md, "| %s | %s | %s |\n", step.Name, step.Message, step.Duration)
+		}
+	}
+	if len(r.Velero) > 0 {
+		fmt.Fprintf(md, "\n## Velero\n\n| Kind | Name | Phase | Items | Warnings | Errors |\n|---|---|---|---|---|---|\n")
+		for _, v := range r.Velero {
+			fmt.Fprintf(md, "| %s | %s | %s | %d/%d | %d | %d |\n",
+				v.Kind, v.Name, v.Phase, v.Items, v.TotalItems, v.Warnings, v.Errors)
+		}
+	}
+	if len(r.Volumes) > 0 {
+		fmt.Fprintf(md, "\n## Volumes\n\n| Method | Volume | Phase | Bytes | Progress | Duration |\n|---|---|---|---|---|---|\n")
+		for _, v := range r.Volumes {
+			fmt.Fprintf(md, "| %s | %s | %s | %d/%d | %s | %s |\n",
+				v.Method, path.Join(v.Namespace, v.Name), v.Phase, v.BytesDone, v.TotalBytes, v.Progress, v.Duration)
+		}
+	}
+	hookSteps := []ReportStep{}
+	for _, step := range r.Steps {
+		if strings.HasSuffix(step.Name, "Hooks") && len(step.Progress) > 0 {
+			hookSteps = append(hookSteps, step)
+		}
+	}
+	if len(hookSteps) > 0 || len(r.Hooks) > 0 {
+		fmt.Fprintf(md, "\n## Hooks\n\n")
+		for _, step := range hookSteps {
+			for _, progress := range step.Progress {
+				fmt.Fprintf(md, "- %s: %s\n", step.Name, progress)
+			}
+		}
+		for _, result := range r.Hooks {
+			status := "Succeeded"
+			if !result.Succeeded() {
+				status = "Failed"
+			}
+			fmt.Fprintf(md, "- %s exec %s in %s: %s\n", result.Phase, result.Hook, result.Pod, status)
+		}
+	}
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"Warnings", r.Warnings},
+		{"Errors", r.Errors},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(md, "\n## %s\n\n", section.title)
+		for _, item := range section.items {
+			fmt.Fprintf(md, "- %s\n", item)
+		}
+	}
+	if len(r.Unhealthy) > 0 {
+		fmt.Fprintf(md, "\n## Unhealthy resources\n\n")
+		for _, ns := range r.Unhealthy {
+			for _, workload := range ns.Workloads {
+				fmt.Fprintf(md, "- %s/%s", ns.Name, workload.Name)
+				if len(workload.Resources) > 0 {
+					fmt.Fprintf(md, ": %s", strings.Join(workload.Resources, ", "))
+				}
+				fmt.Fprintf(md, "\n")
+			}
+		}
+	}
+	return md.String()
+}
@@ -46,6 +46,7 @@ func (t *Task) ensureFinalRestore() (*velero.Restore, error) {
 		return nil, err
 	}
 	newRestore.Labels[migapi.FinalRestoreLabel] = t.UID()
+	newRestore.Spec.ExistingResourcePolicy = t.veleroExistingResourcePolicy()
 	newRestore.Labels[migapi.MigMigrationDebugLabel] = t.Owner.Name
 	newRestore.Labels[migapi.MigPlanDebugLabel] = t.Owner.Spec.MigPlanRef.Name
 	newRestore.Labels[migapi.MigMigrationLabel] = string(t.Owner.UID)
@@ -439,6 +440,7 @@ func (t *Task) buildRestore(client k8sclient.Client, backupName string, restoreT
 		},
 	}
 	t.updateRestore(restore, backupName)
+	t.filterRestore(client, restore)
 	return restore, nil
 }
 
@@ -0,0 +1,151 @@
+package migmigration
+
+import (
+	"fmt"
+	"path"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	velero "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Get whether the migration retries a failed migration.
+func (t *Task) retry() bool {
+	return t.Owner.Spec.RetryMigrationRef != nil
+}
+
+// Get the failed migration referenced by `retryMigrationRef`.
+// Returns `nil` when the migration is not a retry or the reference cannot be resolved.
+func (t *Task) getRetriedMigration() (*migapi.MigMigration, error) {
+	if !t.retry() {
+		return nil, nil
+	}
+	return migapi.GetMigration(t.Client, t.Owner.Spec.RetryMigrationRef)
+}
+
+// Get the phase a retry starts at.
+// This is the first phase in the itinerary of the step that failed
+// in the retried migration. Returns "" when the retry needs to run
+// the whole itinerary.
+func (t *Task) getRetryPhase(retried *migapi.MigMigration) (string, error) {
+	var failedStep *migapi.Step
+	for _, step := range retried.Status.Pipeline {
+		if step.Failed {
+			failedStep = step
+			break
+		}
+	}
+	if failedStep == nil || failedStep.Name == StepPrepare {
+		return "", nil
+	}
+	for _, phase := range t.Itinerary.Phases {
+		if phase.Step != failedStep.Name || phase.Name == Completed {
+			continue
+		}
+		flag, err := t.allFlags(phase)
+		if err != nil {
+			return "", err
+		}
+		if !flag {
+			continue
+		}
+		flag, err = t.anyFlags(phase)
+		if err != nil {
+			return "", err
+		}
+		if !flag {
+			continue
+		}
+		return phase.Name, nil
+	}
+	return "", nil
+}
+
+// Record the step that failed in the retried migration.
+// The retry skips to the step once the Prepare step completes. The
+// stage pods created by the retried migration are adopted so they
+// are cleaned up by this migration. Nothing is recorded when the retry
+// needs to run the whole itinerary.
+func (t *Task) recordRetryPhase() error {
+	retried, err := t.getRetriedMigration()
+	if err != nil || retried == nil {
+		return err
+	}
+	phase, err := t.getRetryPhase(retried)
+	if err != nil {
+		return err
+	}
+	if phase == "" {
+		t.Log.Info("Retried migration did not fail past the Prepare step, "+
+			"running the whole itinerary.",
+			"retriedMigration", path.Join(retried.Namespace, retried.Name))
+		return nil
+	}
+	stagePods := retried.Status.FindCondition(StagePodsCreated)
+	if stagePods != nil {
+		t.Owner.Status.SetCondition(*stagePods)
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     Retrying,
+		Status:   True,
+		Reason:   phase,
+		Category: Advisory,
+		Message: fmt.Sprintf("The migration retries failed migration %s starting at phase %s.",
+			retried.Name, phase),
+		Durable: true,
+	})
+	return nil
+}
+
+// Skip to the phase recorded by `recordRetryPhase()`.
+// Returns `true` when skipped.
+func (t *Task) skipToRetryPhase() bool {
+	retrying := t.Owner.Status.FindCondition(Retrying)
+	if retrying == nil || retrying.Reason == "" {
+		return false
+	}
+	t.Log.Info("Skipping to the failed step of the retried migration.",
+		"retryPhase", retrying.Reason)
+	t.Phase = retrying.Reason
+	t.Step = t.Itinerary.GetStepForPhase(retrying.Reason)
+	return true
+}
+
+// Get a completed Backup of the retried migration.
+// The backup is found using the specified label which has the UID of
+// the migration as value. When the retried migration is itself a retry
+// which reused a backup, the retry chain is followed back to the
+// migration which created the backup.
+// Returns `nil` when there is no reusable Backup.
+func (t *Task) getRetriedBackup(label string) (*velero.Backup, error) {
+	retried, err := t.getRetriedMigration()
+	if err != nil || retried == nil {
+		return nil, err
+	}
+	visited := map[types.UID]bool{t.Owner.UID: true}
+	migration := retried
+	for migration != nil && !visited[migration.UID] {
+		visited[migration.UID] = true
+		labels := migration.GetCorrelationLabels()
+		labels[label] = string(migration.UID)
+		backup, err := t.getBackup(labels)
+		if err != nil {
+			return nil, err
+		}
+		if backup != nil {
+			if backup.Status.Phase != velero.BackupPhaseCompleted {
+				return nil, nil
+			}
+			t.Log.Info("Reusing Velero Backup of the retried migration.",
+				"backup", path.Join(backup.Namespace, backup.Name),
+				"retriedMigration", path.Join(retried.Namespace, retried.Name),
+				"backupMigration", path.Join(migration.Namespace, migration.Name))
+			return backup, nil
+		}
+		migration, err = migapi.GetMigration(t.Client, migration.Spec.RetryMigrationRef)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
@@ -10,6 +10,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -21,17 +24,22 @@ func (t *Task) deleteMigrated() error {
 	//	return err
 	//}
 
-	err := t.deleteMigratedNamespaceScopedResources()
-	if err != nil {
-		return err
+	t.startRollbackReport()
+	// The destination PVCs are deleted, reset their transfer history first.
+	err := t.resetVolumeTransferHistory()
+	if err == nil {
+		err = t.deleteMigratedNamespaceScopedResources()
 	}
-
-	err = t.deleteMovedNfsPVs()
+	if err == nil {
+		err = t.deleteMovedNfsPVs()
+	}
+	// The report records the failure as well.
+	reportErr := t.updateRollbackReport()
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return reportErr
 }
 
 //func (t *Task) deleteDeploymentConfigLeftoverPods() error {
@@ -134,33 +142,47 @@ func (t *Task) deleteMigratedNamespaceScopedResources() error {
 		return err
 	}
 
-	clientListOptions := k8sclient.ListOptions{}
-	matchingLabels := k8sclient.MatchingLabels(map[string]string{
-		migapi.MigPlanLabel: string(t.PlanResources.MigPlan.UID),
-	})
-	matchingLabels.ApplyToList(&clientListOptions)
-	listOptions := clientListOptions.AsListOptions()
+	listOptions, err := t.rollbackListOptions()
+	if err != nil {
+		return err
+	}
+	scope := t.Owner.Spec.RollbackScope
 	for _, gvr := range GVRs {
-		for _, ns := range t.destinationNamespaces() {
+		for _, ns := range t.rollbackDestinationNamespaces() {
 			gvkCombined := gvr.Group + "/" + gvr.Version + "/" + gvr.Resource
 			t.Log.Info(fmt.Sprintf("Rollback: Searching destination cluster namespace for resources "+
 				"with migrated-by label."),
 				"namespace", ns,
 				"gvk", gvkCombined,
-				"label", fmt.Sprintf("%v:%v", migapi.MigPlanLabel, string(t.PlanResources.MigPlan.UID)))
+				"label", listOptions.LabelSelector)
 			deletePropagationPolicy := metav1.DeletePropagationBackground
-			err = client.Resource(gvr).DeleteCollection(context.Background(), metav1.DeleteOptions{}, *listOptions)
-			if err == nil {
-				continue
-			}
-			if !k8serror.IsMethodNotSupported(err) && !k8serror.IsNotFound(err) {
-				return err
-			}
+			// Listed first so the rollback report names what is deleted.
 			list, err := client.Resource(gvr).Namespace(ns).List(context.Background(), *listOptions)
 			if err != nil {
 				return err
 			}
-			for _, r := range list.Items {
+			if len(list.Items) == 0 {
+				continue
+			}
+			// A scoped rollback deletes the selected resources one by one.
+			if scope == nil {
+				err = client.Resource(gvr).Namespace(ns).DeleteCollection(context.Background(), metav1.DeleteOptions{}, *listOptions)
+				if err == nil {
+					for i := range list.Items {
+						r := &list.Items[i]
+						t.recordDeleted(r.GetAPIVersion(), r.GetKind(), r, nil)
+					}
+					continue
+				}
+				if !k8serror.IsMethodNotSupported(err) && !k8serror.IsNotFound(err) {
+					return err
+				}
+			}
+			for i := range list.Items {
+				r := &list.Items[i]
+				if !scope.Includes(r.GroupVersionKind().GroupKind()) {
+					continue
+				}
 				// delete any dependent resources
 				err = client.Resource(gvr).Namespace(ns).Delete(context.Background(), r.GetName(), metav1.DeleteOptions{PropagationPolicy: &deletePropagationPolicy})
 				if err != nil {
@@ -169,9 +191,11 @@ func (t *Task) deleteMigratedNamespaceScopedResources() error {
 					if k8serror.IsMethodNotSupported(err) || k8serror.IsNotFound(err) {
 						continue
 					}
+					t.recordDeleted(r.GetAPIVersion(), r.GetKind(), r, err)
 					log.Error(err, fmt.Sprintf("Failed to request delete on: %s", gvr.String()))
 					return err
 				}
+				t.recordDeleted(r.GetAPIVersion(), r.GetKind(), r, nil)
 				log.Info("DELETION REQUESTED for resource on destination cluster with matching migrated-by label",
 					"gvk", gvkCombined,
 					"resource", path.Join(ns, r.GetName()))
@@ -190,20 +214,33 @@ func (t *Task) deleteMovedNfsPVs() error {
 		return err
 	}
 
+	scope := t.Owner.Spec.RollbackScope
+	if !scope.Includes(schema.GroupKind{Kind: "PersistentVolume"}) {
+		return nil
+	}
 	// Only delete PVs with matching 'migrated-by-migplan' label.
-	listOptions := k8sclient.MatchingLabels(map[string]string{
-		migapi.MigPlanLabel: string(t.PlanResources.MigPlan.UID),
-	})
+	selector, err := t.rollbackSelector()
+	if err != nil {
+		return err
+	}
 	list := corev1.PersistentVolumeList{}
-	err = dstClient.List(context.TODO(), &list, listOptions)
+	err = dstClient.List(context.TODO(), &list, k8sclient.MatchingLabelsSelector{Selector: selector})
 	if err != nil {
 		return err
 	}
+	namespaces := map[string]bool{}
+	for _, ns := range t.rollbackDestinationNamespaces() {
+		namespaces[ns] = true
+	}
 	for _, pv := range list.Items {
 		// Skip unless PV type = NFS
 		if pv.Spec.NFS == nil {
 			continue
 		}
+		// Skip unless bound in a rolled back namespace
+		if scope != nil && (pv.Spec.ClaimRef == nil || !namespaces[pv.Spec.ClaimRef.Namespace]) {
+			continue
+		}
 		// Skip delete unless ReclaimPolicy=Retain
 		if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
 			continue
@@ -215,10 +252,12 @@ func (t *Task) deleteMovedNfsPVs() error {
 			if k8serror.IsMethodNotSupported(err) || k8serror.IsNotFound(err) {
 				continue
 			}
+			t.recordDeletedPV(&pv, err)
 			log.Error(err, "Failed to request delete on moved PV",
 				"persistentVolume", pv.Name)
 			return err
 		}
+		t.recordDeletedPV(&pv, nil)
 		log.Info("Deleted moved NFS PV from destination cluster", "persistentVolume", pv.Name)
 	}
 
@@ -233,14 +272,13 @@ func (t *Task) ensureMigratedResourcesDeleted() (bool, error) {
 		return false, err
 	}
 
-	clientListOptions := k8sclient.ListOptions{}
-	matchingLabels := k8sclient.MatchingLabels(map[string]string{
-		migapi.MigPlanLabel: string(t.PlanResources.MigPlan.UID),
-	})
-	matchingLabels.ApplyToList(&clientListOptions)
-	listOptions := clientListOptions.AsListOptions()
+	listOptions, err := t.rollbackListOptions()
+	if err != nil {
+		return false, err
+	}
+	scope := t.Owner.Spec.RollbackScope
 	for _, gvr := range GVRs {
-		for _, ns := range t.destinationNamespaces() {
+		for _, ns := range t.rollbackDestinationNamespaces() {
 			gvkCombinedName := gvr.Group + "/" + gvr.Version + "/" + gvr.Resource
 			log.Info("Rollback: Checking for leftover resources in destination cluster",
 				"gvk", gvkCombinedName, "namespace", ns)
@@ -249,16 +287,79 @@ func (t *Task) ensureMigratedResourcesDeleted() (bool, error) {
 				return false, err
 			}
 			// Wait for resources with deletion timestamps
-			if len(list.Items) > 0 {
+			for _, r := range list.Items {
+				if !scope.Includes(r.GroupVersionKind().GroupKind()) {
+					continue
+				}
 				t.Log.Info("Resource(s) found with in destination cluster "+
 					"that have NOT finished terminating. These resource(s) "+
 					"are associated with the MigPlan and deletion has been requested.",
 					"gvk", gvkCombinedName,
 					"namespace", ns)
-				return false, err
+				return false, nil
 			}
 		}
 	}
 
 	return true, nil
 }
+
+// Get the source namespaces rolled back.
+// All the source namespaces unless the rollback is scoped.
+func (t *Task) rollbackSourceNamespaces() []string {
+	scope := t.Owner.Spec.RollbackScope
+	namespaces := []string{}
+	for _, ns := range t.sourceNamespaces() {
+		if scope.IncludesNamespace(ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// Get the destination namespaces rolled back.
+// All the destination namespaces unless the rollback is scoped.
+func (t *Task) rollbackDestinationNamespaces() []string {
+	scope := t.Owner.Spec.RollbackScope
+	if scope == nil {
+		return t.destinationNamespaces()
+	}
+	mapping := t.PlanResources.MigPlan.GetNamespaceMapping()
+	namespaces := []string{}
+	for _, ns := range t.rollbackSourceNamespaces() {
+		namespaces = append(namespaces, mapping[ns])
+	}
+	return namespaces
+}
+
+// Get the selector of the migrated resources rolled back.
+// Selects the resources labeled as migrated by the plan and
+// matching the label selector of the rollback scope.
+func (t *Task) rollbackSelector() (labels.Selector, error) {
+	selector := labels.Everything()
+	scope := t.Owner.Spec.RollbackScope
+	if scope != nil && scope.LabelSelector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(scope.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	requirement, err := labels.NewRequirement(
+		migapi.MigPlanLabel,
+		selection.Equals,
+		[]string{string(t.PlanResources.MigPlan.UID)})
+	if err != nil {
+		return nil, err
+	}
+	return selector.Add(*requirement), nil
+}
+
+// Get the list options selecting the migrated resources rolled back.
+func (t *Task) rollbackListOptions() (*metav1.ListOptions, error) {
+	selector, err := t.rollbackSelector()
+	if err != nil {
+		return nil, err
+	}
+	return &metav1.ListOptions{LabelSelector: selector.String()}, nil
+}
@@ -0,0 +1,213 @@
+package migmigration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Key of the report in the rollback ConfigMap.
+const RollbackReportKey = "report.json"
+
+// RollbackReport lists what a rollback changed on the clusters.
+// Migration - The migration name.
+// Plan - The plan name.
+// Deleted - The migrated resources deleted on the destination cluster.
+// PersistentVolumes - The moved NFS PVs deleted on the destination cluster.
+// Restored - The workloads unquiesced on the source cluster.
+type RollbackReport struct {
+	Migration         string                `json:"migration"`
+	Plan              string                `json:"plan"`
+	Deleted           []RollbackReportEntry `json:"deleted,omitempty"`
+	PersistentVolumes []RollbackReportEntry `json:"persistentVolumes,omitempty"`
+	Restored          []RollbackReportEntry `json:"restored,omitempty"`
+}
+
+// RollbackReportEntry describes a resource changed by the rollback.
+// APIVersion, Kind, Namespace, Name - The resource.
+// Detail - What was changed.
+// Error - Why the change failed, empty when it succeeded.
+type RollbackReportEntry struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Get the key identifying the resource of the entry.
+func (r *RollbackReportEntry) key() string {
+	return path.Join(r.APIVersion, r.Kind, r.Namespace, r.Name)
+}
+
+// Merge the entries, the latest entry for a resource wins.
+func mergeRollbackEntries(entries, latest []RollbackReportEntry) []RollbackReportEntry {
+	index := map[string]int{}
+	for i := range entries {
+		index[entries[i].key()] = i
+	}
+	for _, entry := range latest {
+		if i, found := index[entry.key()]; found {
+			entries[i] = entry
+			continue
+		}
+		index[entry.key()] = len(entries)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Merge the report into this report.
+func (r *RollbackReport) merge(latest *RollbackReport) {
+	r.Deleted = mergeRollbackEntries(r.Deleted, latest.Deleted)
+	r.PersistentVolumes = mergeRollbackEntries(r.PersistentVolumes, latest.PersistentVolumes)
+	r.Restored = mergeRollbackEntries(r.Restored, latest.Restored)
+}
+
+// Get the number of failed entries.
+func (r *RollbackReport) failed() int {
+	count := 0
+	for _, list := range [][]RollbackReportEntry{r.Deleted, r.PersistentVolumes, r.Restored} {
+		for _, entry := range list {
+			if entry.Error != "" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Build a report entry.
+func newRollbackEntry(apiVersion, kind string, object metav1.Object, detail string, err error) RollbackReportEntry {
+	entry := RollbackReportEntry{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  object.GetNamespace(),
+		Name:       object.GetName(),
+		Detail:     detail,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// Start recording what the rollback changes.
+// Nothing is recorded unless the migration is a rollback.
+func (t *Task) startRollbackReport() {
+	if !t.rollback() {
+		return
+	}
+	t.RollbackReport = &RollbackReport{
+		Migration: t.Owner.Name,
+		Plan:      t.PlanResources.MigPlan.Name,
+	}
+}
+
+// Record a migrated resource deleted on the destination cluster.
+func (t *Task) recordDeleted(apiVersion, kind string, object metav1.Object, err error) {
+	if t.RollbackReport == nil {
+		return
+	}
+	t.RollbackReport.Deleted = append(t.RollbackReport.Deleted,
+		newRollbackEntry(apiVersion, kind, object, "", err))
+}
+
+// Record a moved PV deleted on the destination cluster.
+func (t *Task) recordDeletedPV(pv *corev1.PersistentVolume, err error) {
+	if t.RollbackReport == nil {
+		return
+	}
+	detail := ""
+	if pv.Spec.ClaimRef != nil {
+		detail = fmt.Sprintf("Bound to claim %s.",
+			path.Join(pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name))
+	}
+	t.RollbackReport.PersistentVolumes = append(t.RollbackReport.PersistentVolumes,
+		newRollbackEntry("v1", "PersistentVolume", pv, detail, err))
+}
+
+// Record a workload unquiesced on the source cluster.
+func (t *Task) recordRestored(apiVersion, kind string, object metav1.Object, detail string, err error) {
+	if t.RollbackReport == nil {
+		return
+	}
+	t.RollbackReport.Restored = append(t.RollbackReport.Restored,
+		newRollbackEntry(apiVersion, kind, object, detail, err))
+}
+
+// Merge the recorded changes into the rollback report ConfigMap and
+// reference it in the RollbackReported condition.
+func (t *Task) updateRollbackReport() error {
+	if t.RollbackReport == nil {
+		return nil
+	}
+	cm := &corev1.ConfigMap{}
+	key := k8sclient.ObjectKey{
+		Namespace: t.Owner.Namespace,
+		Name:      t.Owner.Name + "-rollback",
+	}
+	err := t.Client.Get(context.TODO(), key, cm)
+	if err != nil && !k8serror.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+	report := &RollbackReport{}
+	if content, exists := cm.Data[RollbackReportKey]; found && exists {
+		err = json.Unmarshal([]byte(content), report)
+		if err != nil {
+			return err
+		}
+	}
+	report.Migration = t.RollbackReport.Migration
+	report.Plan = t.RollbackReport.Plan
+	report.merge(t.RollbackReport)
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if found {
+		cm.Data = map[string]string{
+			RollbackReportKey: string(content),
+		}
+		err = t.Client.Update(context.TODO(), cm)
+	} else {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				Labels:    t.Owner.GetCorrelationLabels(),
+			},
+			Data: map[string]string{
+				RollbackReportKey: string(content),
+			},
+		}
+		migapi.SetOwnerReference(t.Owner, t.Owner, cm)
+		err = t.Client.Create(context.TODO(), cm)
+	}
+	if err != nil {
+		return err
+	}
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     RollbackReported,
+		Status:   True,
+		Reason:   t.Phase,
+		Category: Advisory,
+		Message: fmt.Sprintf("The rollback report is in ConfigMap %s. "+
+			"Deleted %d resource(s) and %d PersistentVolume(s), restored %d workload(s), %d failed.",
+			path.Join(cm.Namespace, cm.Name),
+			len(report.Deleted), len(report.PersistentVolumes), len(report.Restored), report.failed()),
+		Durable: true,
+	})
+	t.Log.Info("Updated rollback report.",
+		"configMap", path.Join(cm.Namespace, cm.Name))
+	return nil
+}
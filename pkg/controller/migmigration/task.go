@@ -45,6 +45,19 @@ const (
 	PostBackupHooksFailed                  = "PostBackupHooksFailed"
 	PreRestoreHooksFailed                  = "PreRestoreHooksFailed"
 	PostRestoreHooksFailed                 = "PostRestoreHooksFailed"
+	PreQuiesceHooks                        = "PreQuiesceHooks"
+	PostDirectVolumeHooks                  = "PostDirectVolumeHooks"
+	PreRollbackHooks                       = "PreRollbackHooks"
+	PostRollbackHooks                      = "PostRollbackHooks"
+	PreQuiesceHooksFailed                  = "PreQuiesceHooksFailed"
+	PostDirectVolumeHooksFailed            = "PostDirectVolumeHooksFailed"
+	PreRollbackHooksFailed                 = "PreRollbackHooksFailed"
+	PostRollbackHooksFailed                = "PostRollbackHooksFailed"
+	DeleteUnselectedResources              = "DeleteUnselectedResources"
+	TransformRestoredResources             = "TransformRestoredResources"
+	TransformRestoredResourcesFailed       = "TransformRestoredResourcesFailed"
+	CheckExistingResources                 = "CheckExistingResources"
+	CheckExistingResourcesFailed           = "CheckExistingResourcesFailed"
 	EnsureInitialBackup                    = "EnsureInitialBackup"
 	InitialBackupCreated                   = "InitialBackupCreated"
 	InitialBackupFailed                    = "InitialBackupFailed"
@@ -100,23 +113,31 @@ const (
 
 // Flags
 const (
-	Quiesce             = 0x001   // Only when QuiescePods (true).
-	HasStagePods        = 0x002   // Only when stage pods created.
-	HasPVs              = 0x004   // Only when PVs migrated.
-	HasVerify           = 0x008   // Only when the plan has enabled verification
-	HasISs              = 0x010   // Only when ISs migrated
-	DirectImage         = 0x020   // Only when using direct image migration
-	IndirectImage       = 0x040   // Only when using indirect image migration
-	DirectVolume        = 0x080   // Only when using direct volume migration
-	IndirectVolume      = 0x100   // Only when using indirect volume migration
-	HasStageBackup      = 0x200   // True when stage backup is needed
-	EnableImage         = 0x400   // True when disable_image_migration is unset
-	EnableVolume        = 0x800   // True when disable_volume is unset
-	HasPreBackupHooks   = 0x1000  // True when prebackup hooks exist
-	HasPostBackupHooks  = 0x2000  // True when postbackup hooks exist
-	HasPreRestoreHooks  = 0x4000  // True when postbackup hooks exist
-	HasPostRestoreHooks = 0x8000  // True when postbackup hooks exist
-	StorageConversion   = 0x10000 // True when the migration is a storage conversion
+	Quiesce                  = 0x001     // Only when QuiescePods (true).
+	HasStagePods             = 0x002     // Only when stage pods created.
+	HasPVs                   = 0x004     // Only when PVs migrated.
+	HasVerify                = 0x008     // Only when the plan has enabled verification
+	HasISs                   = 0x010     // Only when ISs migrated
+	DirectImage              = 0x020     // Only when using direct image migration
+	IndirectImage            = 0x040     // Only when using indirect image migration
+	DirectVolume             = 0x080     // Only when using direct volume migration
+	IndirectVolume           = 0x100     // Only when using indirect volume migration
+	HasStageBackup           = 0x200     // True when stage backup is needed
+	EnableImage              = 0x400     // True when disable_image_migration is unset
+	EnableVolume             = 0x800     // True when disable_volume is unset
+	HasPreBackupHooks        = 0x1000    // True when prebackup hooks exist
+	HasPostBackupHooks       = 0x2000    // True when postbackup hooks exist
+	HasPreRestoreHooks       = 0x4000    // True when postbackup hooks exist
+	HasPostRestoreHooks      = 0x8000    // True when postbackup hooks exist
+	StorageConversion        = 0x10000   // True when the migration is a storage conversion
+	HasPreQuiesceHooks       = 0x20000   // True when prequiesce hooks exist
+	HasPostDirectVolumeHooks = 0x40000   // True when postdirectvolume hooks exist
+	HasPreRollbackHooks      = 0x80000   // True when prerollback hooks exist
+	HasPostRollbackHooks     = 0x100000  // True when postrollback hooks exist
+	HasTransforms            = 0x200000  // True when restore transforms exist
+	FailOnExistingResources  = 0x400000  // True when the existing resource policy is Fail
+	HasUnselectedResources   = 0x800000  // True when the resource filters are not all applied by Velero
+	UnscopedRollback         = 0x1000000 // True when the rollback is not scoped
 )
 
 // Migration steps
@@ -156,6 +177,7 @@ var StageItinerary = Itinerary{
 		{Name: WaitForStaleStagePodsTerminated, Step: StepPrepare},
 		//{Name: CreateRegistries, Step: StepPrepare, all: IndirectImage | EnableImage | HasISs},
 		//{Name: CreateDirectImageMigration, Step: StepStageBackup, all: DirectImage | EnableImage},
+		{Name: PreQuiesceHooks, Step: PreQuiesceHooks, all: Quiesce | HasPreQuiesceHooks},
 		{Name: QuiesceApplications, Step: StepStageBackup, all: Quiesce},
 		{Name: EnsureQuiesced, Step: StepStageBackup, all: Quiesce},
 		//{Name: CreateDirectVolumeMigration, Step: StepStageBackup, all: DirectVolume | EnableVolume},
@@ -176,6 +198,7 @@ var StageItinerary = Itinerary{
 		{Name: StageRestoreCreated, Step: StepStageRestore, all: HasStageBackup},
 		//{Name: WaitForDirectImageMigrationToComplete, Step: StepDirectImage, all: DirectImage | EnableImage},
 		//{Name: WaitForDirectVolumeMigrationToComplete, Step: StepDirectVolume, all: DirectVolume | EnableVolume},
+		{Name: PostDirectVolumeHooks, Step: PostDirectVolumeHooks, all: DirectVolume | EnableVolume | HasPostDirectVolumeHooks},
 		{Name: SwapPVCReferences, Step: StepCleanup, all: StorageConversion | Quiesce},
 		//{Name: DeleteRegistries, Step: StepCleanup},
 		{Name: EnsureStagePodsDeleted, Step: StepCleanup, all: HasStagePods},
@@ -202,10 +225,12 @@ var FinalItinerary = Itinerary{
 		{Name: WaitForVeleroReady, Step: StepPrepare},
 		//{Name: WaitForRegistriesReady, Step: StepPrepare, all: IndirectImage | EnableImage | HasISs},
 		{Name: EnsureCloudSecretPropagated, Step: StepPrepare},
+		{Name: CheckExistingResources, Step: StepPrepare, all: FailOnExistingResources},
 		{Name: PreBackupHooks, Step: PreBackupHooks, all: HasPreBackupHooks},
 		//{Name: CreateDirectImageMigration, Step: StepBackup, all: DirectImage | EnableImage},
 		{Name: EnsureInitialBackup, Step: StepBackup},
 		{Name: InitialBackupCreated, Step: StepBackup},
+		{Name: PreQuiesceHooks, Step: PreQuiesceHooks, all: Quiesce | HasPreQuiesceHooks},
 		{Name: QuiesceApplications, Step: StepStageBackup, all: Quiesce},
 		{Name: EnsureQuiesced, Step: StepStageBackup, all: Quiesce},
 		{Name: EnsureStagePodsFromRunning, Step: StepStageBackup, all: HasPVs | IndirectVolume},
@@ -226,11 +251,14 @@ var FinalItinerary = Itinerary{
 		{Name: EnsureAnnotationsDeleted, Step: StepStageRestore, all: HasStageBackup},
 		//{Name: WaitForDirectImageMigrationToComplete, Step: StepDirectImage, all: DirectImage | EnableImage},
 		//{Name: WaitForDirectVolumeMigrationToComplete, Step: StepDirectVolume, all: DirectVolume | EnableVolume},
+		{Name: PostDirectVolumeHooks, Step: PostDirectVolumeHooks, all: DirectVolume | EnableVolume | HasPostDirectVolumeHooks},
 		{Name: PostBackupHooks, Step: PostBackupHooks, all: HasPostBackupHooks},
 		{Name: PreRestoreHooks, Step: PreRestoreHooks, all: HasPreRestoreHooks},
 		{Name: EnsureInitialBackupReplicated, Step: StepRestore},
 		{Name: EnsureFinalRestore, Step: StepRestore},
 		{Name: FinalRestoreCreated, Step: StepRestore},
+		{Name: DeleteUnselectedResources, Step: StepRestore, all: HasUnselectedResources},
+		{Name: TransformRestoredResources, Step: StepRestore, all: HasTransforms},
 		{Name: UnQuiesceDestApplications, Step: StepRestore},
 		{Name: PostRestoreHooks, Step: PostRestoreHooks, all: HasPostRestoreHooks},
 		{Name: SwapPVCReferences, Step: StepCleanup, all: StorageConversion | Quiesce},
@@ -271,15 +299,17 @@ var RollbackItinerary = Itinerary{
 	Name: "Rollback",
 	Phases: []Phase{
 		{Name: Rollback, Step: StepCleanupVelero},
-		{Name: DeleteBackups, Step: StepCleanupVelero},
-		{Name: DeleteRestores, Step: StepCleanupVelero},
+		{Name: DeleteBackups, Step: StepCleanupVelero, all: UnscopedRollback},
+		{Name: DeleteRestores, Step: StepCleanupVelero, all: UnscopedRollback},
 		//{Name: DeleteRegistries, Step: StepCleanupHelpers},
 		{Name: EnsureStagePodsDeleted, Step: StepCleanupHelpers},
-		{Name: EnsureAnnotationsDeleted, Step: StepCleanupHelpers, any: HasPVs | HasISs},
+		{Name: EnsureAnnotationsDeleted, Step: StepCleanupHelpers, all: UnscopedRollback, any: HasPVs | HasISs},
 		{Name: SwapPVCReferences, Step: StepCleanupMigrated, all: StorageConversion},
+		{Name: PreRollbackHooks, Step: PreRollbackHooks, all: HasPreRollbackHooks},
 		{Name: DeleteMigrated, Step: StepCleanupMigrated},
 		{Name: EnsureMigratedDeleted, Step: StepCleanupMigrated},
 		{Name: UnQuiesceSrcApplications, Step: StepCleanupUnquiesce},
+		{Name: PostRollbackHooks, Step: PostRollbackHooks, all: HasPostRollbackHooks},
 		{Name: Completed, Step: StepCleanup},
 	},
 }
@@ -311,6 +341,39 @@ func (r Itinerary) progressReport(phaseName string) (string, int, int) {
 	return phaseName, n, total
 }
 
+// Get whether the itinerary includes a phase.
+func (r Itinerary) hasPhase(phaseName string) bool {
+	for _, phase := range r.Phases {
+		if phase.Name == phaseName {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMigrationPhase returns whether the phase is run by the stage, final or
+// rollback itinerary, the phases which may be given a deadline.
+func IsMigrationPhase(phaseName string) bool {
+	for _, itinerary := range []Itinerary{StageItinerary, FinalItinerary, RollbackItinerary} {
+		if itinerary.hasPhase(phaseName) {
+			return true
+		}
+	}
+	return false
+}
+
+// RunsDirectVolumeMigration returns whether the stage or final itinerary
+// waits for a direct volume migration. The post-direct-volume hooks and the
+// direct volume migration settings of the plan have no effect otherwise.
+func RunsDirectVolumeMigration() bool {
+	for _, itinerary := range []Itinerary{StageItinerary, FinalItinerary} {
+		if itinerary.hasPhase(WaitForDirectVolumeMigrationToComplete) {
+			return true
+		}
+	}
+	return false
+}
+
 // A Velero task that provides the complete backup & restore workflow.
 // Log - A controller's logger.
 // Client - A controller's (local) client.
@@ -335,6 +398,10 @@ type Task struct {
 	Itinerary       Itinerary
 	Errors          []string
 	Step            string
+	RollbackReport  *RollbackReport
+
+	// PVCs selected by the resource filters, keyed by namespace/name.
+	selectedClaims map[string]bool
 
 	Tracer        opentracing.Tracer
 	ReconcileSpan opentracing.Span
@@ -368,9 +435,37 @@ func (t *Task) Run(ctx context.Context) error {
 
 	defer t.updatePipeline()
 
+	// Hold at the phase boundary while paused.
+	if t.paused() {
+		return t.pause()
+	}
+	if err = t.resume(); err != nil {
+		return err
+	}
+
+	// Fail when the phase has run past its deadline.
+	if timedOut, elapsed, timeout := t.phaseTimedOut(); timedOut {
+		t.failPhaseTimedOut(elapsed, timeout)
+		return nil
+	}
+
+	// Report what the migration would do instead of running it.
+	if t.dryRun() && t.migrating() {
+		return t.runDryRun()
+	}
+
 	// Run the current phase.
 	switch t.Phase {
-	case Created, Started, Rollback:
+	case Created, Rollback:
+		if err = t.next(); err != nil {
+			return err
+		}
+	case Started:
+		if t.retry() {
+			if err = t.recordRetryPhase(); err != nil {
+				return err
+			}
+		}
 		if err = t.next(); err != nil {
 			return err
 		}
@@ -669,6 +764,20 @@ func (t *Task) Run(ctx context.Context) error {
 			t.Log.Info("Velero Pod(s) are unready on the source or target cluster. Waiting.")
 			t.Requeue = PollReQ
 		}
+	case PreQuiesceHooks:
+		status, err := t.runHooks(migapi.PreQuiesceHookPhase)
+		if err != nil {
+			t.fail(PreQuiesceHooksFailed, []string{err.Error()})
+			return err
+		}
+		if status {
+			if err = t.next(); err != nil {
+				return err
+			}
+		} else {
+			t.Log.Info("PreQuiesceHook(s) are incomplete. Waiting.")
+			t.Requeue = PollReQ
+		}
 	case QuiesceApplications:
 		err := t.quiesceApplications()
 		if err != nil {
@@ -746,9 +855,16 @@ func (t *Task) Run(ctx context.Context) error {
 		if completed {
 			step := t.Owner.Status.FindStep(t.Step)
 			step.MarkCompleted()
+			if t.setDirectVolumeVerificationFailed(dvm) {
+				t.fail(MigrationFailed, dvm.Status.Errors)
+				break
+			}
 			if len(reasons) > 0 {
 				t.setDirectVolumeMigrationFailureWarning(dvm)
 			}
+			if err = t.recordVolumeTransferHistory(dvm); err != nil {
+				return err
+			}
 			if err = t.next(); err != nil {
 				return err
 			}
@@ -831,6 +947,20 @@ func (t *Task) Run(ctx context.Context) error {
 				"backup", path.Join(backup.Namespace, backup.Name))
 			t.Requeue = PollReQ
 		}
+	case PostDirectVolumeHooks:
+		status, err := t.runHooks(migapi.PostDirectVolumeHookPhase)
+		if err != nil {
+			t.fail(PostDirectVolumeHooksFailed, []string{err.Error()})
+			return err
+		}
+		if status {
+			if err = t.next(); err != nil {
+				return err
+			}
+		} else {
+			t.Log.Info("PostDirectVolumeHook(s) are incomplete. Waiting.")
+			t.Requeue = PollReQ
+		}
 	case PostBackupHooks:
 		status, err := t.runHooks(migapi.PostBackupHookPhase)
 		if err != nil {
@@ -991,6 +1121,38 @@ func (t *Task) Run(ctx context.Context) error {
 				"restoreErrors", restore.Status.Errors)
 			t.Requeue = PollReQ
 		}
+	case CheckExistingResources:
+		reasons, err := t.checkExistingResources()
+		if err != nil {
+			return err
+		}
+		if len(reasons) > 0 {
+			t.fail(CheckExistingResourcesFailed, reasons)
+			break
+		}
+		if err = t.next(); err != nil {
+			return err
+		}
+	case DeleteUnselectedResources:
+		err := t.deleteUnselectedResources()
+		if err != nil {
+			return err
+		}
+		if err = t.next(); err != nil {
+			return err
+		}
+	case TransformRestoredResources:
+		failures, err := t.transformRestoredResources()
+		if err != nil {
+			return err
+		}
+		if len(failures) > 0 {
+			t.fail(TransformRestoredResourcesFailed, failures)
+			break
+		}
+		if err = t.next(); err != nil {
+			return err
+		}
 	case PostRestoreHooks:
 		status, err := t.runHooks(migapi.PostRestoreHookPhase)
 		if err != nil {
@@ -1041,6 +1203,34 @@ func (t *Task) Run(ctx context.Context) error {
 	case MigrationFailed:
 		t.Phase = Completed
 		t.Step = StepCleanup
+	case PreRollbackHooks:
+		status, err := t.runHooks(migapi.PreRollbackHookPhase)
+		if err != nil {
+			t.fail(PreRollbackHooksFailed, []string{err.Error()})
+			return err
+		}
+		if status {
+			if err = t.next(); err != nil {
+				return err
+			}
+		} else {
+			t.Log.Info("PreRollbackHook(s) are incomplete. Waiting.")
+			t.Requeue = PollReQ
+		}
+	case PostRollbackHooks:
+		status, err := t.runHooks(migapi.PostRollbackHookPhase)
+		if err != nil {
+			t.fail(PostRollbackHooksFailed, []string{err.Error()})
+			return err
+		}
+		if status {
+			if err = t.next(); err != nil {
+				return err
+			}
+		} else {
+			t.Log.Info("PostRollbackHook(s) are incomplete. Waiting.")
+			t.Requeue = PollReQ
+		}
 	case DeleteMigrated:
 		err := t.deleteMigrated()
 		if err != nil {
@@ -1248,6 +1438,9 @@ func (t *Task) updatePipeline() {
 		} else {
 			currentStep.Message = ""
 		}
+		if t.paused() {
+			currentStep.Message = "Paused"
+		}
 		if t.Phase == Completed {
 			currentStep.MarkCompleted()
 		}
@@ -1287,6 +1480,10 @@ func (t *Task) next() error {
 	}
 	for n := current + 1; n < len(t.Itinerary.Phases); n++ {
 		next := t.Itinerary.Phases[n]
+		// A retry skips to the failed step once the Prepare step completes.
+		if t.Itinerary.Phases[current].Step == StepPrepare && next.Step != StepPrepare && t.skipToRetryPhase() {
+			return nil
+		}
 		flag, err := t.allFlags(next)
 		if err != nil {
 			return err
@@ -1366,8 +1563,14 @@ func (t *Task) allFlags(phase Phase) (bool, error) {
 			return false, nil
 		}
 	}
-	if phase.all&DirectVolume != 0 && !t.directVolumeMigration() {
-		return false, nil
+	if phase.all&DirectVolume != 0 {
+		directVolumeMigration, err := t.directVolumeMigration()
+		if err != nil {
+			return false, err
+		}
+		if !directVolumeMigration {
+			return false, nil
+		}
 	}
 	if phase.all&IndirectVolume != 0 && !t.indirectVolumeMigration() {
 		return false, nil
@@ -1405,6 +1608,47 @@ func (t *Task) allFlags(phase Phase) (bool, error) {
 		return false, nil
 	}
 
+	if phase.all&HasPreQuiesceHooks != 0 && !t.hasHooks(migapi.PreQuiesceHookPhase) {
+		return false, nil
+	}
+
+	// The post-direct-volume hooks run only after a direct volume migration ran.
+	if phase.all&HasPostDirectVolumeHooks != 0 &&
+		(!t.hasHooks(migapi.PostDirectVolumeHookPhase) ||
+			!t.Itinerary.hasPhase(WaitForDirectVolumeMigrationToComplete)) {
+		return false, nil
+	}
+
+	if phase.all&HasPreRollbackHooks != 0 && !t.hasHooks(migapi.PreRollbackHookPhase) {
+		return false, nil
+	}
+
+	if phase.all&HasPostRollbackHooks != 0 && !t.hasHooks(migapi.PostRollbackHookPhase) {
+		return false, nil
+	}
+
+	if phase.all&HasTransforms != 0 && len(t.PlanResources.MigPlan.Spec.Transforms) == 0 {
+		return false, nil
+	}
+
+	if phase.all&HasUnselectedResources != 0 && !t.hasUnselectedResources() {
+		return false, nil
+	}
+
+	// The backups, restores and annotations are shared by all the namespaces of the plan.
+	if phase.all&UnscopedRollback != 0 && t.Owner.Spec.RollbackScope != nil {
+		return false, nil
+	}
+
+	if phase.all&FailOnExistingResources != 0 &&
+		t.PlanResources.MigPlan.Spec.GetExistingResourcePolicy() != migapi.ExistingResourceFail {
+		return false, nil
+	}
+	// The resources were checked by the retried migration which may have restored some of them.
+	if phase.all&FailOnExistingResources != 0 && t.Owner.Status.HasCondition(Retrying) {
+		return false, nil
+	}
+
 	return true, nil
 
 }
@@ -1458,8 +1702,14 @@ func (t *Task) anyFlags(phase Phase) (bool, error) {
 	//		return true, nil
 	//	}
 	//}
-	if phase.any&DirectVolume != 0 && t.directVolumeMigration() {
-		return true, nil
+	if phase.any&DirectVolume != 0 {
+		directVolumeMigration, err := t.directVolumeMigration()
+		if err != nil {
+			return false, err
+		}
+		if directVolumeMigration {
+			return true, nil
+		}
 	}
 	if phase.any&IndirectVolume != 0 && t.indirectVolumeMigration() {
 		return true, nil
@@ -1537,6 +1787,19 @@ func (t *Task) rollback() bool {
 	return t.Owner.Spec.Rollback
 }
 
+// Get whether the migration is running the stage, final or rollback
+// itinerary as opposed to cleaning up after a cancel or a failure.
+func (t *Task) migrating() bool {
+	if t.Phase == Completed {
+		return false
+	}
+	switch t.Itinerary.Name {
+	case StageItinerary.Name, FinalItinerary.Name, RollbackItinerary.Name:
+		return true
+	}
+	return false
+}
+
 // Get whether the migration is stage.
 func (t *Task) stage() bool {
 	return t.Owner.Spec.Stage
@@ -1661,15 +1924,15 @@ func (t *Task) hasPVs() (bool, bool) {
 }
 
 // Get whether the associated plan has PVs to be directly migrated
-func (t *Task) hasDirectVolumes() bool {
+func (t *Task) hasDirectVolumes() (bool, error) {
 	if t.PlanResources.MigPlan.Spec.IndirectVolumeMigration {
-		return false
+		return false, nil
 	}
-	pvcList := t.getDirectVolumeClaimList()
-	if pvcList != nil {
-		return true
+	pvcList, err := t.getDirectVolumeClaimList()
+	if err != nil {
+		return false, err
 	}
-	return false
+	return pvcList != nil, nil
 }
 
 // Get whether the associated plan has imagestreams to be migrated
@@ -1715,8 +1978,11 @@ func (t *Task) indirectVolumeMigration() bool {
 
 // Returns true if the IndirectVolumeMigration override on the plan is not set (plan is configured to do direct migration)
 // There must exist a set of direct volumes for this to return true
-func (t *Task) directVolumeMigration() bool {
-	return !t.indirectVolumeMigration() && t.hasDirectVolumes()
+func (t *Task) directVolumeMigration() (bool, error) {
+	if t.indirectVolumeMigration() {
+		return false, nil
+	}
+	return t.hasDirectVolumes()
 }
 
 // Returns true if the migration requires a stage backup
@@ -1836,6 +2102,17 @@ func (t *Task) hasPreRestoreHooks() bool {
 	}
 	return anyPreRestoreHooks
 }
+
+// Get whether the plan has hooks in the hook phase.
+func (t *Task) hasHooks(hookPhase string) bool {
+	for i := range t.PlanResources.MigPlan.Spec.Hooks {
+		if t.PlanResources.MigPlan.Spec.Hooks[i].Phase == hookPhase {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *Task) hasPostRestoreHooks() bool {
 	var anyPostRestoreHooks bool
 
@@ -1,10 +1,31 @@
 package migmigration
 
 import (
+	"context"
+	"errors"
+
 	"github.com/go-logr/logr"
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	fakecompat "github.com/konveyor/mig-controller/pkg/compat/fake"
+	"github.com/konveyor/mig-controller/pkg/existing"
+	"github.com/konveyor/mig-controller/pkg/transform"
+	velero "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/util/exec"
+	"k8s.io/utils/pointer"
 	"reflect"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestTask_getStagePVs(t1 *testing.T) {
@@ -189,3 +210,1934 @@ func TestTask_getStagePVs(t1 *testing.T) {
 		})
 	}
 }
+
+func TestTask_paused(t1 *testing.T) {
+	tests := []struct {
+		name      string
+		paused    bool
+		itinerary Itinerary
+		phase     string
+		want      bool
+	}{
+		{
+			name:      "not paused",
+			paused:    false,
+			itinerary: FinalItinerary,
+			phase:     EnsureFinalRestore,
+			want:      false,
+		},
+		{
+			name:      "paused final migration",
+			paused:    true,
+			itinerary: FinalItinerary,
+			phase:     EnsureFinalRestore,
+			want:      true,
+		},
+		{
+			name:      "paused stage migration",
+			paused:    true,
+			itinerary: StageItinerary,
+			phase:     EnsureStageBackup,
+			want:      true,
+		},
+		{
+			name:      "paused rollback migration",
+			paused:    true,
+			itinerary: RollbackItinerary,
+			phase:     DeleteMigrated,
+			want:      true,
+		},
+		{
+			name:      "paused canceled migration",
+			paused:    true,
+			itinerary: CancelItinerary,
+			phase:     DeleteBackups,
+			want:      false,
+		},
+		{
+			name:      "paused failed migration",
+			paused:    true,
+			itinerary: FailedItinerary,
+			phase:     MigrationFailed,
+			want:      false,
+		},
+		{
+			name:      "paused completed migration",
+			paused:    true,
+			itinerary: FinalItinerary,
+			phase:     Completed,
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{
+					Spec: migapi.MigMigrationSpec{Paused: tt.paused},
+				},
+				Itinerary: tt.itinerary,
+				Phase:     tt.phase,
+			}
+			if got := t.paused(); got != tt.want {
+				t1.Errorf("paused() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_phaseTimedOut(t1 *testing.T) {
+	started := func(ago time.Duration) *metav1.Time {
+		return &metav1.Time{Time: time.Now().Add(-ago)}
+	}
+	tests := []struct {
+		name      string
+		timeouts  map[string]metav1.Duration
+		itinerary Itinerary
+		phase     string
+		start     *metav1.Time
+		want      bool
+	}{
+		{
+			name:      "no deadline",
+			itinerary: FinalItinerary,
+			phase:     EnsureFinalRestore,
+			start:     started(time.Hour),
+			want:      false,
+		},
+		{
+			name:      "phase within its deadline",
+			timeouts:  map[string]metav1.Duration{EnsureFinalRestore: {Duration: time.Hour}},
+			itinerary: FinalItinerary,
+			phase:     EnsureFinalRestore,
+			start:     started(time.Minute),
+			want:      false,
+		},
+		{
+			name:      "phase past its deadline",
+			timeouts:  map[string]metav1.Duration{EnsureFinalRestore: {Duration: time.Minute}},
+			itinerary: FinalItinerary,
+			phase:     EnsureFinalRestore,
+			start:     started(time.Hour),
+			want:      true,
+		},
+		{
+			name:      "phase start not recorded",
+			timeouts:  map[string]metav1.Duration{EnsureFinalRestore: {Duration: time.Minute}},
+			itinerary: FinalItinerary,
+			phase:     EnsureFinalRestore,
+			want:      false,
+		},
+		{
+			name:      "failed migration cleanup",
+			timeouts:  map[string]metav1.Duration{MigrationFailed: {Duration: time.Minute}},
+			itinerary: FailedItinerary,
+			phase:     MigrationFailed,
+			start:     started(time.Hour),
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{
+					Status: migapi.MigMigrationStatus{PhaseStartTimestamp: tt.start},
+				},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{Spec: migapi.MigPlanSpec{PhaseTimeouts: tt.timeouts}},
+				},
+				Itinerary: tt.itinerary,
+				Phase:     tt.phase,
+			}
+			if got, _, _ := t.phaseTimedOut(); got != tt.want {
+				t1.Errorf("phaseTimedOut() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_getRetryPhase(t1 *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline []*migapi.Step
+		want     string
+	}{
+		{
+			name: "no failed step",
+			pipeline: []*migapi.Step{
+				{Name: StepPrepare},
+				{Name: StepBackup},
+			},
+			want: "",
+		},
+		{
+			name: "failed in prepare step",
+			pipeline: []*migapi.Step{
+				{Name: StepPrepare, Failed: true},
+				{Name: StepBackup},
+			},
+			want: "",
+		},
+		{
+			name: "failed in backup step",
+			pipeline: []*migapi.Step{
+				{Name: StepPrepare},
+				{Name: StepBackup, Failed: true},
+				{Name: StepRestore},
+			},
+			want: EnsureInitialBackup,
+		},
+		{
+			name: "failed in restore step",
+			pipeline: []*migapi.Step{
+				{Name: StepPrepare},
+				{Name: StepBackup},
+				{Name: StepRestore, Failed: true},
+			},
+			want: EnsureInitialBackupReplicated,
+		},
+		{
+			name: "failed in step not part of the itinerary",
+			pipeline: []*migapi.Step{
+				{Name: StepPrepare},
+				{Name: StepDirectVolume, Failed: true},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{},
+				},
+				Itinerary: FinalItinerary,
+			}
+			retried := &migapi.MigMigration{
+				Status: migapi.MigMigrationStatus{Pipeline: tt.pipeline},
+			}
+			got, err := t.getRetryPhase(retried)
+			if err != nil {
+				t1.Errorf("getRetryPhase() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t1.Errorf("getRetryPhase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_pvcMappingResources(t1 *testing.T) {
+	tests := []struct {
+		name     string
+		rollback bool
+		pvs      []migapi.PV
+		want     []string
+	}{
+		{
+			name: "no pvcs",
+			want: []string{},
+		},
+		{
+			name: "pvc mapped to itself",
+			pvs: []migapi.PV{
+				{PVC: migapi.PVC{Namespace: "ns-1", Name: "pvc-1"}},
+			},
+			want: []string{},
+		},
+		{
+			name: "pvc mapped to a new name",
+			pvs: []migapi.PV{
+				{PVC: migapi.PVC{Namespace: "ns-1", Name: "pvc-1:pvc-1-new"}},
+				{PVC: migapi.PVC{Namespace: "ns-1", Name: "pvc-2"}},
+			},
+			want: []string{
+				"PersistentVolumeClaim/ns-1/pvc-1 -> PersistentVolumeClaim/ns-1/pvc-1-new",
+			},
+		},
+		{
+			name:     "pvc mapped to a new name in rollback",
+			rollback: true,
+			pvs: []migapi.PV{
+				{PVC: migapi.PVC{Namespace: "ns-1", Name: "pvc-1:pvc-1-new"}},
+			},
+			want: []string{
+				"PersistentVolumeClaim/ns-1/pvc-1-new -> PersistentVolumeClaim/ns-1/pvc-1",
+			},
+		},
+		{
+			name: "skipped pvc",
+			pvs: []migapi.PV{
+				{
+					PVC:       migapi.PVC{Namespace: "ns-1", Name: "pvc-1:pvc-1-new"},
+					Selection: migapi.Selection{Action: migapi.PvSkipAction},
+				},
+			},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{
+					Spec: migapi.MigMigrationSpec{Rollback: tt.rollback},
+				},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{
+						Spec: migapi.MigPlanSpec{
+							PersistentVolumes: migapi.PersistentVolumes{List: tt.pvs},
+						},
+					},
+				},
+			}
+			if got := t.pvcMappingResources(); !reflect.DeepEqual(got, tt.want) {
+				t1.Errorf("pvcMappingResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newQuiesceFilter(t *testing.T) {
+	workload := func(name string, labels map[string]string) metav1.Object {
+		return &metav1.ObjectMeta{Namespace: "ns-1", Name: name, Labels: labels}
+	}
+	frontend := workload("web", map[string]string{"tier": "frontend"})
+	database := workload("db", map[string]string{"tier": "database"})
+	monitor := workload("monitor", map[string]string{"tier": "ops"})
+	policy := &migapi.QuiescePolicy{
+		ExcludeSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"tier": "ops"},
+		},
+		Exclude: []kapi.ObjectReference{
+			{Kind: DeploymentKind, Name: "db"},
+		},
+		Stages: []migapi.QuiesceStage{
+			{
+				Name:     "frontends",
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}},
+			},
+		},
+		Kinds: []migapi.QuiesceKind{
+			{Kind: DaemonSetKind, Skip: true},
+		},
+	}
+	tests := []struct {
+		name   string
+		policy *migapi.QuiescePolicy
+		stage  int
+		kind   string
+		object metav1.Object
+		want   bool
+	}{
+		{
+			name:   "no policy",
+			kind:   DeploymentKind,
+			object: monitor,
+			want:   true,
+		},
+		{
+			name:   "first stage",
+			policy: policy,
+			stage:  0,
+			kind:   StatefulSetKind,
+			object: frontend,
+			want:   true,
+		},
+		{
+			name:   "not in first stage",
+			policy: policy,
+			stage:  0,
+			kind:   StatefulSetKind,
+			object: database,
+			want:   false,
+		},
+		{
+			name:   "last stage",
+			policy: policy,
+			stage:  1,
+			kind:   StatefulSetKind,
+			object: database,
+			want:   true,
+		},
+		{
+			name:   "excluded by selector",
+			policy: policy,
+			stage:  1,
+			kind:   StatefulSetKind,
+			object: monitor,
+			want:   false,
+		},
+		{
+			name:   "excluded by reference",
+			policy: policy,
+			stage:  1,
+			kind:   DeploymentKind,
+			object: database,
+			want:   false,
+		},
+		{
+			name:   "skipped kind",
+			policy: policy,
+			stage:  0,
+			kind:   DaemonSetKind,
+			object: frontend,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newQuiesceFilter(tt.policy, tt.stage)
+			if err != nil {
+				t.Fatalf("newQuiesceFilter() error = %v", err)
+			}
+			if got := filter.selects(tt.kind, tt.object); got != tt.want {
+				t.Errorf("selects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_quiesceCustomResource(t *testing.T) {
+	replicasRule := &migapi.QuiesceCustomResource{
+		Group:        "kafka.strimzi.io",
+		Version:      "v1beta2",
+		Kind:         "KafkaConnect",
+		ReplicasPath: "{.spec.replicas}",
+	}
+	pauseRule := &migapi.QuiesceCustomResource{
+		Group:      "postgres-operator.crunchydata.com",
+		Version:    "v1beta1",
+		Kind:       "PostgresCluster",
+		PausePath:  ".spec.shutdown",
+		PauseValue: "true",
+	}
+	tests := []struct {
+		name        string
+		rule        *migapi.QuiesceCustomResource
+		spec        map[string]interface{}
+		want        bool
+		quiesced    map[string]interface{}
+		annotations map[string]string
+	}{
+		{
+			name:        "scale down replicas",
+			rule:        replicasRule,
+			spec:        map[string]interface{}{"replicas": int64(3)},
+			want:        true,
+			quiesced:    map[string]interface{}{"replicas": int64(0)},
+			annotations: map[string]string{migapi.ReplicasAnnotation: "3"},
+		},
+		{
+			name:     "already scaled down",
+			rule:     replicasRule,
+			spec:     map[string]interface{}{"replicas": int64(0)},
+			want:     false,
+			quiesced: map[string]interface{}{"replicas": int64(0)},
+		},
+		{
+			name:        "replicas not set",
+			rule:        replicasRule,
+			spec:        map[string]interface{}{},
+			want:        true,
+			quiesced:    map[string]interface{}{"replicas": int64(0)},
+			annotations: map[string]string{migapi.ReplicasAnnotation: ""},
+		},
+		{
+			name:        "pause",
+			rule:        pauseRule,
+			spec:        map[string]interface{}{"shutdown": false},
+			want:        true,
+			quiesced:    map[string]interface{}{"shutdown": true},
+			annotations: map[string]string{migapi.PausedAnnotation: "false"},
+		},
+		{
+			name:     "already paused",
+			rule:     pauseRule,
+			spec:     map[string]interface{}{"shutdown": true},
+			want:     false,
+			quiesced: map[string]interface{}{"shutdown": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": runtime.DeepCopyJSONValue(tt.spec),
+			}}
+			got, err := quiesceCustomResource(tt.rule, resource)
+			if err != nil {
+				t.Fatalf("quiesceCustomResource() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("quiesceCustomResource() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(resource.Object["spec"], tt.quiesced) {
+				t.Errorf("quiesceCustomResource() spec = %v, want %v", resource.Object["spec"], tt.quiesced)
+			}
+			if !reflect.DeepEqual(resource.GetAnnotations(), tt.annotations) {
+				t.Errorf("quiesceCustomResource() annotations = %v, want %v", resource.GetAnnotations(), tt.annotations)
+			}
+			_, err = unQuiesceCustomResource(tt.rule, resource)
+			if err != nil {
+				t.Fatalf("unQuiesceCustomResource() error = %v", err)
+			}
+			if !reflect.DeepEqual(resource.Object["spec"], tt.spec) {
+				t.Errorf("unQuiesceCustomResource() spec = %v, want %v", resource.Object["spec"], tt.spec)
+			}
+		})
+	}
+}
+
+func Test_podDeletionRequested(t *testing.T) {
+	deleted := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		gracePeriod *int64
+		want        time.Time
+	}{
+		{
+			name:        "given a grace period, the deletion was requested the grace period before the deletion timestamp",
+			gracePeriod: pointer.Int64(30),
+			want:        deleted.Add(-30 * time.Second),
+		},
+		{
+			name:        "given no grace period, the deletion was requested at the deletion timestamp",
+			gracePeriod: nil,
+			want:        deleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &kapi.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					DeletionTimestamp:          &metav1.Time{Time: deleted},
+					DeletionGracePeriodSeconds: tt.gracePeriod,
+				},
+			}
+			if got := podDeletionRequested(pod); !got.Equal(tt.want) {
+				t.Errorf("podDeletionRequested() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mergeQuiesceCustomResources(t *testing.T) {
+	kafka := migapi.QuiesceCustomResource{Group: "kafka.strimzi.io", Version: "v1beta2", Kind: "KafkaConnect", ReplicasPath: ".spec.replicas"}
+	kafkaPaused := migapi.QuiesceCustomResource{Group: "kafka.strimzi.io", Version: "v1beta2", Kind: "KafkaConnect", PausePath: ".spec.paused"}
+	postgres := migapi.QuiesceCustomResource{Group: "postgres-operator.crunchydata.com", Version: "v1beta1", Kind: "PostgresCluster", PausePath: ".spec.shutdown"}
+	tests := []struct {
+		name     string
+		policies []*migapi.QuiescePolicy
+		want     *migapi.QuiescePolicy
+	}{
+		{
+			name:     "no policy",
+			policies: []*migapi.QuiescePolicy{nil, nil},
+			want:     nil,
+		},
+		{
+			name:     "no scale rules",
+			policies: []*migapi.QuiescePolicy{{Stages: []migapi.QuiesceStage{{}}}},
+			want:     nil,
+		},
+		{
+			name: "rules of the rolled back migration are kept",
+			policies: []*migapi.QuiescePolicy{
+				nil,
+				{CustomResources: []migapi.QuiesceCustomResource{postgres}},
+				{CustomResources: []migapi.QuiesceCustomResource{kafka}},
+			},
+			want: &migapi.QuiescePolicy{CustomResources: []migapi.QuiesceCustomResource{postgres, kafka}},
+		},
+		{
+			name: "first rule of a kind is used",
+			policies: []*migapi.QuiescePolicy{
+				{CustomResources: []migapi.QuiesceCustomResource{kafka}},
+				{CustomResources: []migapi.QuiesceCustomResource{kafkaPaused, postgres}},
+			},
+			want: &migapi.QuiescePolicy{CustomResources: []migapi.QuiesceCustomResource{kafka, postgres}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeQuiesceCustomResources(tt.policies...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeQuiesceCustomResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_unQuiesceApplications(t *testing.T) {
+	newQuiesced := func() []k8sclient.Object {
+		return []k8sclient.Object{
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "ns-1",
+					Name:        "deployment",
+					Annotations: map[string]string{migapi.ReplicasAnnotation: "2"},
+				},
+				Spec: appsv1.DeploymentSpec{Replicas: pointer.Int32(0)},
+			},
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "ns-1",
+					Name:        "statefulset",
+					Annotations: map[string]string{migapi.ReplicasAnnotation: "3"},
+				},
+				Spec: appsv1.StatefulSetSpec{Replicas: pointer.Int32(0)},
+			},
+		}
+	}
+	tests := []struct {
+		name            string
+		scope           *migapi.RollbackScope
+		wantDeployment  int32
+		wantStatefulSet int32
+	}{
+		{
+			name:            "not scoped",
+			scope:           nil,
+			wantDeployment:  2,
+			wantStatefulSet: 3,
+		},
+		{
+			name:            "scoped to namespaces",
+			scope:           &migapi.RollbackScope{Namespaces: []string{"ns-1"}},
+			wantDeployment:  2,
+			wantStatefulSet: 3,
+		},
+		{
+			name: "scoped to deployments",
+			scope: &migapi.RollbackScope{
+				IncludedResources: []*metav1.GroupKind{{Group: "apps", Kind: DeploymentKind}},
+			},
+			wantDeployment:  2,
+			wantStatefulSet: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := fakecompat.NewFakeClient(newQuiesced()...)
+			if err != nil {
+				t.Fatalf("NewFakeClient() error = %v", err)
+			}
+			task := &Task{
+				Log:   log.WithName("test_unQuiesceApplications"),
+				Owner: &migapi.MigMigration{Spec: migapi.MigMigrationSpec{RollbackScope: tt.scope}},
+			}
+			err = task.unQuiesceApplications(client, []string{"ns-1"}, tt.scope)
+			if err != nil {
+				t.Fatalf("unQuiesceApplications() error = %v", err)
+			}
+			deployment := &appsv1.Deployment{}
+			err = client.Get(context.TODO(), k8sclient.ObjectKey{Namespace: "ns-1", Name: "deployment"}, deployment)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if *deployment.Spec.Replicas != tt.wantDeployment {
+				t.Errorf("deployment replicas = %v, want %v", *deployment.Spec.Replicas, tt.wantDeployment)
+			}
+			statefulSet := &appsv1.StatefulSet{}
+			err = client.Get(context.TODO(), k8sclient.ObjectKey{Namespace: "ns-1", Name: "statefulset"}, statefulSet)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if *statefulSet.Spec.Replicas != tt.wantStatefulSet {
+				t.Errorf("statefulset replicas = %v, want %v", *statefulSet.Spec.Replicas, tt.wantStatefulSet)
+			}
+		})
+	}
+}
+
+func TestTask_rollbackScope(t1 *testing.T) {
+	plan := &migapi.MigPlan{
+		ObjectMeta: metav1.ObjectMeta{UID: "plan-uid"},
+		Spec: migapi.MigPlanSpec{
+			Namespaces: []string{"app1:target1", "app2", "app3:target3"},
+		},
+	}
+	tests := []struct {
+		name         string
+		scope        *migapi.RollbackScope
+		wantSource   []string
+		wantDest     []string
+		wantSelector string
+	}{
+		{
+			name:         "not scoped",
+			wantSource:   []string{"app1", "app2", "app3"},
+			wantDest:     []string{"target1", "app2", "target3"},
+			wantSelector: migapi.MigPlanLabel + "=plan-uid",
+		},
+		{
+			name: "scoped namespaces",
+			scope: &migapi.RollbackScope{
+				Namespaces: []string{"app3", "app1"},
+			},
+			wantSource:   []string{"app1", "app3"},
+			wantDest:     []string{"target1", "target3"},
+			wantSelector: migapi.MigPlanLabel + "=plan-uid",
+		},
+		{
+			name: "scoped selector",
+			scope: &migapi.RollbackScope{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "frontend"},
+				},
+			},
+			wantSource:   []string{"app1", "app2", "app3"},
+			wantDest:     []string{"target1", "app2", "target3"},
+			wantSelector: "app=frontend," + migapi.MigPlanLabel + "=plan-uid",
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				PlanResources: &migapi.PlanResources{MigPlan: plan},
+				Owner: &migapi.MigMigration{
+					Spec: migapi.MigMigrationSpec{Rollback: true, RollbackScope: tt.scope},
+				},
+			}
+			if got := t.rollbackSourceNamespaces(); !reflect.DeepEqual(got, tt.wantSource) {
+				t1.Errorf("rollbackSourceNamespaces() = %v, want %v", got, tt.wantSource)
+			}
+			if got := t.rollbackDestinationNamespaces(); !reflect.DeepEqual(got, tt.wantDest) {
+				t1.Errorf("rollbackDestinationNamespaces() = %v, want %v", got, tt.wantDest)
+			}
+			selector, err := t.rollbackSelector()
+			if err != nil {
+				t1.Fatalf("rollbackSelector() error = %v", err)
+			}
+			if got := selector.String(); got != tt.wantSelector {
+				t1.Errorf("rollbackSelector() = %v, want %v", got, tt.wantSelector)
+			}
+		})
+	}
+}
+
+func TestRollbackReport_merge(t *testing.T) {
+	failed := RollbackReportEntry{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "app1", Name: "frontend", Error: "conflict"}
+	deleted := RollbackReportEntry{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "app1", Name: "frontend"}
+	service := RollbackReportEntry{APIVersion: "v1", Kind: "Service", Namespace: "app1", Name: "frontend"}
+	pv := RollbackReportEntry{APIVersion: "v1", Kind: "PersistentVolume", Name: "pv1"}
+	report := &RollbackReport{
+		Deleted: []RollbackReportEntry{failed},
+	}
+	report.merge(&RollbackReport{
+		Deleted:           []RollbackReportEntry{deleted, service},
+		PersistentVolumes: []RollbackReportEntry{pv},
+	})
+	want := &RollbackReport{
+		Deleted:           []RollbackReportEntry{deleted, service},
+		PersistentVolumes: []RollbackReportEntry{pv},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("merge() = %v, want %v", report, want)
+	}
+	if got := report.failed(); got != 0 {
+		t.Errorf("failed() = %v, want 0", got)
+	}
+}
+
+func Test_hookJobFailed(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *batchv1.Job
+		want bool
+	}{
+		{
+			name: "running",
+			job:  &batchv1.Job{Status: batchv1.JobStatus{Active: 1, Failed: 2}},
+			want: false,
+		},
+		{
+			name: "failed limit reached",
+			job:  &batchv1.Job{Status: batchv1.JobStatus{Failed: HookJobFailedLimit}},
+			want: true,
+		},
+		{
+			name: "backoff limit exceeded",
+			job: &batchv1.Job{Status: batchv1.JobStatus{
+				Failed: 1,
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobFailed, Reason: BackoffLimitExceededError},
+				},
+			}},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hookJobFailed(tt.job); got != tt.want {
+				t.Errorf("hookJobFailed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_rollbackHookPhases(t1 *testing.T) {
+	tests := []struct {
+		name  string
+		hooks []migapi.MigPlanHook
+		want  []string
+	}{
+		{
+			name: "no hooks",
+			want: []string{},
+		},
+		{
+			name: "pre-rollback hook",
+			hooks: []migapi.MigPlanHook{
+				{Phase: migapi.PreRollbackHookPhase},
+			},
+			want: []string{PreRollbackHooks},
+		},
+		{
+			name: "pre and post rollback hooks",
+			hooks: []migapi.MigPlanHook{
+				{Phase: migapi.PostRollbackHookPhase},
+				{Phase: migapi.PreRollbackHookPhase},
+				{Phase: migapi.PreBackupHookPhase},
+			},
+			want: []string{PreRollbackHooks, PostRollbackHooks},
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{
+						Spec: migapi.MigPlanSpec{Hooks: tt.hooks},
+					},
+				},
+			}
+			got := []string{}
+			for _, phase := range RollbackItinerary.Phases {
+				if phase.all&(HasPreRollbackHooks|HasPostRollbackHooks) == 0 {
+					continue
+				}
+				included, err := t.allFlags(phase)
+				if err != nil {
+					t1.Fatalf("allFlags() error = %v", err)
+				}
+				if included {
+					got = append(got, phase.Name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t1.Errorf("allFlags() phases = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_skipToRetryPhase(t1 *testing.T) {
+	retrying := migapi.Condition{Type: Retrying, Status: True, Reason: EnsureInitialBackupReplicated, Durable: true}
+	tests := []struct {
+		name      string
+		phase     string
+		retrying  bool
+		wantPhase string
+		wantStep  string
+	}{
+		{
+			name:      "retry runs the prepare step",
+			phase:     WaitForVeleroReady,
+			retrying:  true,
+			wantPhase: EnsureCloudSecretPropagated,
+			wantStep:  StepPrepare,
+		},
+		{
+			name:      "retry skips to the failed step after the prepare step",
+			phase:     EnsureCloudSecretPropagated,
+			retrying:  true,
+			wantPhase: EnsureInitialBackupReplicated,
+			wantStep:  StepRestore,
+		},
+		{
+			name:      "no retry",
+			phase:     EnsureCloudSecretPropagated,
+			wantPhase: EnsureInitialBackup,
+			wantStep:  StepBackup,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Log:   log.WithName("test_skipToRetryPhase"),
+				Owner: &migapi.MigMigration{},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{},
+				},
+				Itinerary: FinalItinerary,
+				Phase:     tt.phase,
+			}
+			if tt.retrying {
+				t.Owner.Status.SetCondition(retrying)
+			}
+			if err := t.next(); err != nil {
+				t1.Fatalf("next() error = %v", err)
+			}
+			if t.Phase != tt.wantPhase || t.Step != tt.wantStep {
+				t1.Errorf("next() = %v/%v, want %v/%v", t.Step, t.Phase, tt.wantStep, tt.wantPhase)
+			}
+		})
+	}
+}
+
+func TestTask_scopedRollbackPhases(t1 *testing.T) {
+	tests := []struct {
+		name  string
+		scope *migapi.RollbackScope
+		want  []string
+	}{
+		{
+			name: "rollback of the plan",
+			want: []string{DeleteBackups, DeleteRestores, EnsureAnnotationsDeleted},
+		},
+		{
+			name:  "rollback of a namespace",
+			scope: &migapi.RollbackScope{Namespaces: []string{"ns-1"}},
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{
+					Spec: migapi.MigMigrationSpec{Rollback: true, RollbackScope: tt.scope},
+				},
+				PlanResources: &migapi.PlanResources{MigPlan: &migapi.MigPlan{}},
+			}
+			got := []string{}
+			for _, phase := range RollbackItinerary.Phases {
+				if phase.all&UnscopedRollback == 0 {
+					continue
+				}
+				included, err := t.allFlags(phase)
+				if err != nil {
+					t1.Fatalf("allFlags() error = %v", err)
+				}
+				if included {
+					got = append(got, phase.Name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t1.Errorf("allFlags() phases = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_postDirectVolumeHookPhase(t1 *testing.T) {
+	// The direct volume migration phases are disabled in the itineraries.
+	if RunsDirectVolumeMigration() {
+		t1.Fatalf("RunsDirectVolumeMigration() = true, want false")
+	}
+	tests := []struct {
+		name      string
+		itinerary Itinerary
+		hooks     []migapi.MigPlanHook
+		want      bool
+	}{
+		{
+			name:      "stage itinerary not running a direct volume migration",
+			itinerary: StageItinerary,
+			hooks:     []migapi.MigPlanHook{{Phase: migapi.PostDirectVolumeHookPhase}},
+			want:      false,
+		},
+		{
+			name:      "final itinerary not running a direct volume migration",
+			itinerary: FinalItinerary,
+			hooks:     []migapi.MigPlanHook{{Phase: migapi.PostDirectVolumeHookPhase}},
+			want:      false,
+		},
+		{
+			name:      "final itinerary without hooks",
+			itinerary: FinalItinerary,
+			hooks:     []migapi.MigPlanHook{{Phase: migapi.PreBackupHookPhase}},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner:     &migapi.MigMigration{},
+				Itinerary: tt.itinerary,
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{
+						Spec: migapi.MigPlanSpec{
+							Hooks: tt.hooks,
+							PersistentVolumes: migapi.PersistentVolumes{
+								List: []migapi.PV{
+									{
+										PVC: migapi.PVC{Namespace: "ns-1", Name: "pvc-1"},
+										Selection: migapi.Selection{
+											Action:     migapi.PvCopyAction,
+											CopyMethod: migapi.PvFilesystemCopyMethod,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			found := false
+			for _, phase := range tt.itinerary.Phases {
+				if phase.Name != PostDirectVolumeHooks {
+					continue
+				}
+				found = true
+				got, err := t.allFlags(phase)
+				if err != nil {
+					t1.Fatalf("allFlags() error = %v", err)
+				}
+				if got != tt.want {
+					t1.Errorf("allFlags() = %v, want %v", got, tt.want)
+				}
+			}
+			if !found {
+				t1.Errorf("%s itinerary has no %s phase", tt.itinerary.Name, PostDirectVolumeHooks)
+			}
+		})
+	}
+}
+
+func Test_hookExecResults(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		output    string
+		want      migapi.HookExecResult
+		succeeded bool
+	}{
+		{
+			name:      "succeeded",
+			output:    "done",
+			want:      migapi.HookExecResult{Stdout: "done"},
+			succeeded: true,
+		},
+		{
+			name:   "non-zero exit code",
+			err:    exec.CodeExitError{Err: errors.New("command terminated with exit code 3"), Code: 3},
+			output: strings.Repeat("x", HookExecOutputLimit+10),
+			want:   migapi.HookExecResult{ExitCode: 3, Stdout: "..." + strings.Repeat("x", HookExecOutputLimit)},
+		},
+		{
+			name: "timed out",
+			err:  errors.New("command timed out after 1m0s"),
+			want: migapi.HookExecResult{Error: "command timed out after 1m0s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := migapi.HookExecResult{Stdout: truncateHookOutput(tt.output)}
+			setHookExecError(&result, tt.err)
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("result = %v, want %v", result, tt.want)
+			}
+			if got := hookExecSucceeded([]migapi.HookExecResult{result}); got != tt.succeeded {
+				t.Errorf("hookExecSucceeded() = %v, want %v", got, tt.succeeded)
+			}
+		})
+	}
+}
+
+func Test_hookExecutionMap(t *testing.T) {
+	migration := &migapi.MigMigration{ObjectMeta: metav1.ObjectMeta{UID: "uid-1"}}
+	key := hookExecKey(migration, migapi.PreBackupHookPhase, 0, 1)
+	running := []migapi.HookExecResult{
+		{Pod: "ns/pod-1", Attempt: 1, Running: true},
+		{Pod: "ns/pod-2", Attempt: 1, Running: true},
+	}
+	executions := &hookExecutionMap{executions: map[string]*hookExecution{}}
+
+	// Not started, or started by a controller which restarted
+	if _, found := executions.take(key); found {
+		t.Errorf("take() found an attempt never started")
+	}
+	interrupted := interruptHookExec(running)
+	if hookExecRunning(interrupted) || hookExecSucceeded(interrupted) {
+		t.Errorf("interruptHookExec() = %v, want failed results", interrupted)
+	}
+	if !hookExecRunning(running) {
+		t.Errorf("interruptHookExec() modified the running results")
+	}
+
+	// Running
+	executions.executions[key] = &hookExecution{
+		results: append([]migapi.HookExecResult{}, running...),
+		running: 1,
+	}
+	if results, found := executions.take(key); !found || results != nil {
+		t.Errorf("take() = %v, %v, want no results while running", results, found)
+	}
+
+	// Completed
+	executions.executions[key].running = 0
+	executions.executions[key].results = []migapi.HookExecResult{
+		{Pod: "ns/pod-1", Attempt: 1},
+		{Pod: "ns/pod-2", Attempt: 1},
+	}
+	results, found := executions.take(key)
+	if !found || !hookExecSucceeded(results) {
+		t.Errorf("take() = %v, %v, want succeeded results", results, found)
+	}
+	if _, found := executions.take(key); found {
+		t.Errorf("take() found an attempt already taken")
+	}
+}
+
+func TestTask_prepareJob(t *testing.T) {
+	migHook := migapi.MigHook{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-migration", Name: "hook", UID: "hook-uid"},
+		Spec: migapi.MigHookSpec{
+			Image:    "hook-runner",
+			Playbook: "LSBob3N0czogbG9jYWxob3N0Cg==",
+		},
+	}
+	hook := migapi.MigPlanHook{
+		ExecutionNamespace: "openshift-migration",
+		ServiceAccount:     "migration-controller",
+		Phase:              migapi.PreBackupHookPhase,
+	}
+	task := &Task{
+		Owner: &migapi.MigMigration{
+			ObjectMeta: metav1.ObjectMeta{Name: "migration", UID: "uid"},
+		},
+		PlanResources: &migapi.PlanResources{
+			MigPlan:        &migapi.MigPlan{ObjectMeta: metav1.ObjectMeta{Name: "plan"}},
+			SrcMigCluster:  &migapi.MigCluster{ObjectMeta: metav1.ObjectMeta{Name: "src"}},
+			DestMigCluster: &migapi.MigCluster{ObjectMeta: metav1.ObjectMeta{Name: "dest"}},
+		},
+		Itinerary: FinalItinerary,
+	}
+	existing, err := task.configMapTemplate(hook, migHook)
+	if err != nil {
+		t.Fatalf("configMapTemplate() error = %v", err)
+	}
+	existing.Name = "plan-prebackup-abcde"
+	tests := []struct {
+		name    string
+		objects []k8sclient.Object
+		want    string
+	}{
+		{
+			name: "first attempt creates the ConfigMap",
+		},
+		{
+			name:    "retry uses the ConfigMap of the first attempt",
+			objects: []k8sclient.Object{existing},
+			want:    existing.Name,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := fakecompat.NewFakeClient(tt.objects...)
+			if err != nil {
+				t.Fatalf("NewFakeClient() error = %v", err)
+			}
+			job, err := task.prepareJob(hook, migHook, client)
+			if err != nil {
+				t.Fatalf("prepareJob() error = %v", err)
+			}
+			list := kapi.ConfigMapList{}
+			err = client.List(context.TODO(), &list)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(list.Items) != 1 {
+				t.Fatalf("prepareJob() created %d ConfigMaps, want 1", len(list.Items))
+			}
+			want := tt.want
+			if want == "" {
+				want = list.Items[0].Name
+			}
+			got := job.Spec.Template.Spec.Volumes[0].ConfigMap.Name
+			if got == "" || got != want {
+				t.Errorf("prepareJob() ConfigMap = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTask_hookContextEnv(t1 *testing.T) {
+	plan := &migapi.MigPlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan"},
+		Spec: migapi.MigPlanSpec{
+			Namespaces: []string{"ns-1:ns-2", "ns-3"},
+			PersistentVolumes: migapi.PersistentVolumes{
+				List: []migapi.PV{
+					{
+						PVC:       migapi.PVC{Namespace: "ns-1", Name: "pvc-1:pvc-2"},
+						Selection: migapi.Selection{Action: migapi.PvCopyAction},
+					},
+					{
+						PVC:       migapi.PVC{Namespace: "ns-3", Name: "pvc-3"},
+						Selection: migapi.Selection{Action: migapi.PvSkipAction},
+					},
+				},
+			},
+		},
+	}
+	t := &Task{
+		Owner: &migapi.MigMigration{
+			ObjectMeta: metav1.ObjectMeta{Name: "migration", UID: "uid"},
+			Spec:       migapi.MigMigrationSpec{Stage: true},
+		},
+		PlanResources: &migapi.PlanResources{
+			MigPlan:        plan,
+			SrcMigCluster:  &migapi.MigCluster{ObjectMeta: metav1.ObjectMeta{Name: "src"}},
+			DestMigCluster: &migapi.MigCluster{ObjectMeta: metav1.ObjectMeta{Name: "dest"}},
+		},
+		Itinerary: StageItinerary,
+	}
+	want := map[string]string{
+		migapi.HookEnvNamespaces:         "ns-1:ns-2,ns-3",
+		migapi.HookEnvPlanName:           "plan",
+		migapi.HookEnvMigrationName:      "migration",
+		migapi.HookEnvMigrationUID:       "uid",
+		migapi.HookEnvItinerary:          "Stage",
+		migapi.HookEnvType:               "Stage",
+		migapi.HookEnvPhase:              migapi.PreQuiesceHookPhase,
+		migapi.HookEnvSourceCluster:      "src",
+		migapi.HookEnvDestinationCluster: "dest",
+		migapi.HookEnvNamespaceMapping:   "ns-1:ns-2,ns-3:ns-3",
+		migapi.HookEnvPVCs:               "ns-1/pvc-1",
+	}
+	got := map[string]string{}
+	for _, env := range t.hookContextEnv(migapi.MigPlanHook{Phase: migapi.PreQuiesceHookPhase}) {
+		got[env.Name] = env.Value
+	}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("hookContextEnv() = %v, want %v", got, want)
+	}
+}
+
+func Test_hookJobFailureReason(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *batchv1.Job
+		want string
+	}{
+		{
+			name: "running",
+			job:  &batchv1.Job{Status: batchv1.JobStatus{Active: 1}},
+			want: "",
+		},
+		{
+			name: "backoff limit exceeded",
+			job: &batchv1.Job{Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobSuspended, Status: kapi.ConditionFalse},
+					{
+						Type:    batchv1.JobFailed,
+						Status:  kapi.ConditionTrue,
+						Reason:  BackoffLimitExceededError,
+						Message: "Job has reached the specified backoff limit",
+					},
+				},
+			}},
+			want: "BackoffLimitExceeded: Job has reached the specified backoff limit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hookJobFailureReason(tt.job); got != tt.want {
+				t.Errorf("hookJobFailureReason() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_resourceFilters(t1 *testing.T) {
+	plan := &migapi.MigPlan{
+		Spec: migapi.MigPlanSpec{
+			ResourceFilters: migapi.ResourceFilters{
+				{Exclude: true, Namespaces: []string{"ns-3"}},
+				{LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "db"},
+				}},
+			},
+		},
+	}
+	override := migapi.ResourceFilters{
+		{Kinds: []metav1.GroupKind{{Kind: "ConfigMap"}, {Kind: "Secret"}}},
+		{Exclude: true, LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"skip": "true"},
+		}},
+	}
+	scoped := migapi.ResourceFilters{
+		{Namespaces: []string{"ns-1"}, Kinds: []metav1.GroupKind{{Group: "apps", Kind: "Deployment"}}, NameRegex: "^web-"},
+		{Exclude: true, Annotations: map[string]string{"skip": ""}},
+	}
+	configMap := schema.GroupKind{Kind: "ConfigMap"}
+	deployment := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	tests := []struct {
+		name    string
+		filters migapi.ResourceFilters
+		kind    schema.GroupKind
+		object  metav1.ObjectMeta
+		want    bool
+	}{
+		{
+			name:   "plan, not matching the include filter",
+			kind:   deployment,
+			object: metav1.ObjectMeta{Namespace: "ns-1", Name: "web"},
+			want:   false,
+		},
+		{
+			name: "plan, matching the include filter",
+			kind: deployment,
+			object: metav1.ObjectMeta{Namespace: "ns-1", Name: "db",
+				Labels: map[string]string{"app": "db"}},
+			want: true,
+		},
+		{
+			name: "plan, excluded namespace",
+			kind: deployment,
+			object: metav1.ObjectMeta{Namespace: "ns-3", Name: "db",
+				Labels: map[string]string{"app": "db"}},
+			want: false,
+		},
+		{
+			name:    "migration, included kind",
+			filters: override,
+			kind:    configMap,
+			object:  metav1.ObjectMeta{Namespace: "ns-3", Name: "data"},
+			want:    true,
+		},
+		{
+			name:    "migration, other kind",
+			filters: override,
+			kind:    deployment,
+			object:  metav1.ObjectMeta{Namespace: "ns-1", Name: "app"},
+			want:    false,
+		},
+		{
+			name:    "migration, excluded by label",
+			filters: override,
+			kind:    configMap,
+			object: metav1.ObjectMeta{Namespace: "ns-1", Name: "data",
+				Labels: map[string]string{"skip": "true"}},
+			want: false,
+		},
+		{
+			name:    "scoped, matching kind and name",
+			filters: scoped,
+			kind:    deployment,
+			object:  metav1.ObjectMeta{Namespace: "ns-1", Name: "web-1"},
+			want:    true,
+		},
+		{
+			name:    "scoped, not matching the name",
+			filters: scoped,
+			kind:    deployment,
+			object:  metav1.ObjectMeta{Namespace: "ns-1", Name: "db-1"},
+			want:    false,
+		},
+		{
+			name:    "scoped, other namespace",
+			filters: scoped,
+			kind:    configMap,
+			object:  metav1.ObjectMeta{Namespace: "ns-2", Name: "data"},
+			want:    true,
+		},
+		{
+			name:    "scoped, excluded by annotation",
+			filters: scoped,
+			kind:    configMap,
+			object: metav1.ObjectMeta{Namespace: "ns-2", Name: "data",
+				Annotations: map[string]string{"skip": "any"}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{
+					Spec: migapi.MigMigrationSpec{ResourceFilters: tt.filters},
+				},
+				PlanResources: &migapi.PlanResources{MigPlan: plan},
+			}
+			got, err := t.resourceFilters().Selects(tt.kind, &tt.object)
+			if err != nil {
+				t1.Errorf("Selects() error = %v", err)
+			}
+			if got != tt.want {
+				t1.Errorf("Selects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_filterBackup(t1 *testing.T) {
+	base := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "backend"}}
+	tests := []struct {
+		name       string
+		filters    migapi.ResourceFilters
+		want       velero.BackupSpec
+		unselected bool
+	}{
+		{
+			name: "no filters",
+			want: velero.BackupSpec{LabelSelector: base},
+		},
+		{
+			name: "excluded namespace",
+			filters: migapi.ResourceFilters{
+				{Exclude: true, Namespaces: []string{"ns-2"}},
+			},
+			want: velero.BackupSpec{
+				LabelSelector:      base,
+				ExcludedNamespaces: []string{"ns-2"},
+			},
+		},
+		{
+			name: "included label",
+			filters: migapi.ResourceFilters{
+				{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+			},
+			want: velero.BackupSpec{
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"backend"}},
+						{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"db"}},
+					},
+				},
+			},
+		},
+		{
+			name: "included labels and excluded label",
+			filters: migapi.ResourceFilters{
+				{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+				{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+				{Exclude: true, LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "skip", Operator: metav1.LabelSelectorOpExists},
+					},
+				}},
+			},
+			want: velero.BackupSpec{
+				OrLabelSelectors: []*metav1.LabelSelector{
+					{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"backend"}},
+							{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"db"}},
+							{Key: "skip", Operator: metav1.LabelSelectorOpDoesNotExist},
+						},
+					},
+					{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"backend"}},
+							{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"web"}},
+							{Key: "skip", Operator: metav1.LabelSelectorOpDoesNotExist},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "included label in one of the namespaces",
+			filters: migapi.ResourceFilters{
+				{Namespaces: []string{"ns-1"}, LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+			},
+			want:       velero.BackupSpec{LabelSelector: base},
+			unselected: true,
+		},
+		{
+			name: "included labels in each of the namespaces",
+			filters: migapi.ResourceFilters{
+				{Namespaces: []string{"ns-1"}, LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+				{Namespaces: []string{"ns-2"}, LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			},
+			want: velero.BackupSpec{
+				OrLabelSelectors: []*metav1.LabelSelector{
+					{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"backend"}},
+							{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"db"}},
+						},
+					},
+					{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"backend"}},
+							{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"web"}},
+						},
+					},
+				},
+			},
+			unselected: true,
+		},
+		{
+			name: "included name and annotation",
+			filters: migapi.ResourceFilters{
+				{NameRegex: "^web-"},
+				{Exclude: true, Namespaces: []string{"ns-2"}},
+				{Exclude: true, Annotations: map[string]string{"skip": ""}},
+			},
+			want: velero.BackupSpec{
+				LabelSelector:      base,
+				ExcludedNamespaces: []string{"ns-2"},
+			},
+			unselected: true,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{
+					Spec: migapi.MigMigrationSpec{ResourceFilters: tt.filters},
+				},
+				PlanResources: &migapi.PlanResources{
+					MigPlan: &migapi.MigPlan{
+						Spec: migapi.MigPlanSpec{Namespaces: []string{"ns-1", "ns-2"}},
+					},
+				},
+			}
+			backup := &velero.Backup{}
+			t.filterBackup(nil, backup, base, false)
+			if !reflect.DeepEqual(backup.Spec, tt.want) {
+				t1.Errorf("filterBackup() = %+v, want %+v", backup.Spec, tt.want)
+			}
+			if got := t.hasUnselectedResources(); got != tt.unselected {
+				t1.Errorf("hasUnselectedResources() = %v, want %v", got, tt.unselected)
+			}
+		})
+	}
+}
+
+func Test_transformApply(t *testing.T) {
+	deployment := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "web",
+				"namespace": "ns-1",
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"nodeSelector": map[string]interface{}{"zone": "a"},
+						"containers": []interface{}{
+							map[string]interface{}{"name": "web", "image": "src.io/web:1"},
+						},
+					},
+				},
+			},
+		}}
+	}
+	tests := []struct {
+		name      string
+		transform migapi.RestoreTransform
+		object    *unstructured.Unstructured
+		path      []string
+		want      interface{}
+		applied   string
+	}{
+		{
+			name: "merge patch",
+			transform: migapi.RestoreTransform{Name: "no-selector", Group: "apps", Kind: "Deployment",
+				MergePatch: "spec:\n  template:\n    spec:\n      nodeSelector: null\n"},
+			object:  deployment(),
+			path:    []string{"spec", "template", "spec", "nodeSelector"},
+			want:    nil,
+			applied: "no-selector",
+		},
+		{
+			name: "json patch",
+			transform: migapi.RestoreTransform{Name: "registry", Group: "apps", Kind: "Deployment",
+				JSONPatch: `[{"op": "replace", "path": "/spec/template/spec/containers/0/image", "value": "dest.io/web:1"}]`},
+			object:  deployment(),
+			path:    []string{"spec", "template", "spec", "containers"},
+			want:    []interface{}{map[string]interface{}{"name": "web", "image": "dest.io/web:1"}},
+			applied: "registry",
+		},
+		{
+			name: "merge patch of custom resource, previously transformed",
+			transform: migapi.RestoreTransform{Name: "size", Group: "example.com", Kind: "Database",
+				MergePatch: `{"spec": {"size": 3}}`},
+			object: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Database",
+				"metadata": map[string]interface{}{
+					"name":        "db",
+					"annotations": map[string]interface{}{migapi.TransformsAnnotation: "registry"},
+				},
+				"spec": map[string]interface{}{"size": int64(1)},
+			}},
+			path:    []string{"spec", "size"},
+			want:    int64(3),
+			applied: "registry,size",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if transform.Applied(&tt.transform, tt.object) {
+				t.Errorf("Applied() = true before the transform")
+			}
+			got, err := transform.Apply(&tt.transform, tt.object)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			value, _, _ := unstructured.NestedFieldNoCopy(got.Object, tt.path...)
+			if !reflect.DeepEqual(value, tt.want) {
+				t.Errorf("Apply() %s = %v, want %v", strings.Join(tt.path, "."), value, tt.want)
+			}
+			if applied := got.GetAnnotations()[migapi.TransformsAnnotation]; applied != tt.applied {
+				t.Errorf("Apply() applied = %v, want %v", applied, tt.applied)
+			}
+			if !transform.Applied(&tt.transform, got) {
+				t.Errorf("Applied() = false after the transform")
+			}
+		})
+	}
+}
+
+func TestTask_applyTransform(t1 *testing.T) {
+	deployment := func(namespace, name string, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Template: kapi.PodTemplateSpec{
+					Spec: kapi.PodSpec{
+						Containers: []kapi.Container{{Name: "web", Image: "src.io/web:1"}},
+					},
+				},
+			},
+		}
+	}
+	restored := map[string]string{velero.RestoreNameLabel: "final-1", "app": "web"}
+	client, err := fakecompat.NewFakeClient(
+		deployment("ns-2", "web", restored),
+		deployment("ns-2", "not-selected", map[string]string{velero.RestoreNameLabel: "final-1"}),
+		deployment("ns-2", "not-restored", map[string]string{"app": "web"}),
+		deployment("ns-1", "source", restored),
+	)
+	if err != nil {
+		t1.Fatalf("NewFakeClient() error = %v", err)
+	}
+	rule := &migapi.RestoreTransform{
+		Name:          "registry",
+		Group:         "apps",
+		Kind:          "Deployment",
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		JSONPatch:     `[{"op": "replace", "path": "/spec/template/spec/containers/0/image", "value": "dest.io/web:1"}]`,
+	}
+	t := &Task{
+		Log: log.WithName("test_applyTransform"),
+		PlanResources: &migapi.PlanResources{
+			MigPlan: &migapi.MigPlan{Spec: migapi.MigPlanSpec{Namespaces: []string{"ns-1:ns-2"}}},
+		},
+	}
+	gvk := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	for i := 0; i < 2; i++ {
+		failures, err := t.applyTransform(client, "final-1", rule, gvk)
+		if err != nil {
+			t1.Fatalf("applyTransform() error = %v", err)
+		}
+		if len(failures) > 0 {
+			t1.Fatalf("applyTransform() failures = %v", failures)
+		}
+	}
+	want := map[string]string{
+		"ns-2/web":          "dest.io/web:1",
+		"ns-2/not-selected": "src.io/web:1",
+		"ns-2/not-restored": "src.io/web:1",
+		"ns-1/source":       "src.io/web:1",
+	}
+	for key, image := range want {
+		parts := strings.Split(key, "/")
+		got := &appsv1.Deployment{}
+		err := client.Get(context.TODO(), k8sclient.ObjectKey{Namespace: parts[0], Name: parts[1]}, got)
+		if err != nil {
+			t1.Fatalf("Get() error = %v", err)
+		}
+		if got.Spec.Template.Spec.Containers[0].Image != image {
+			t1.Errorf("applyTransform() %s image = %v, want %v", key, got.Spec.Template.Spec.Containers[0].Image, image)
+		}
+		applied := got.Annotations[migapi.TransformsAnnotation]
+		if image == "dest.io/web:1" && applied != "registry" {
+			t1.Errorf("applyTransform() %s applied = %v, want registry", key, applied)
+		}
+	}
+}
+
+func Test_existingFinder(t *testing.T) {
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	serviceAccounts := schema.GroupVersionResource{Version: "v1", Resource: "serviceaccounts"}
+	secrets := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	listKinds := map[schema.GroupVersionResource]string{
+		configMaps:      "ConfigMapList",
+		serviceAccounts: "ServiceAccountList",
+		secrets:         "SecretList",
+	}
+	object := func(kind, namespace, name string, labels map[string]string) runtime.Object {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind(kind)
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		u.SetLabels(labels)
+		return u
+	}
+	plan := &migapi.MigPlan{
+		ObjectMeta: metav1.ObjectMeta{UID: "plan-uid"},
+		Spec: migapi.MigPlanSpec{
+			Namespaces: []string{"src:dest"},
+			ResourceFilters: migapi.ResourceFilters{
+				{Exclude: true, Kinds: []metav1.GroupKind{{Kind: "Secret"}}},
+			},
+		},
+	}
+	source := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		object("ConfigMap", "src", "config", nil),
+		object("ConfigMap", "src", "restored", nil),
+		object("ConfigMap", "src", "new", nil),
+		object("ServiceAccount", "src", "default", nil),
+		object("Secret", "src", "db", nil))
+	destination := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		object("ConfigMap", "dest", "config", nil),
+		object("ConfigMap", "dest", "restored", map[string]string{migapi.MigPlanLabel: "plan-uid"}),
+		object("ConfigMap", "dest", "other", nil),
+		object("ServiceAccount", "dest", "default", nil),
+		object("Secret", "dest", "db", nil))
+	finder := existing.Finder{
+		Source:      source,
+		Destination: destination,
+		GVRs:        []schema.GroupVersionResource{configMaps, serviceAccounts, secrets},
+		Plan:        plan,
+		Filters:     plan.Spec.ResourceFilters,
+	}
+	found, err := finder.Find()
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	got := map[string]bool{}
+	for _, resource := range found {
+		got[resource.String()] = resource.Migrated
+	}
+	want := map[string]bool{
+		"configmaps dest/config":   false,
+		"configmaps dest/restored": true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Find() = %v, want %v", got, want)
+	}
+	conflicts := existing.Conflicts(found)
+	if len(conflicts) != 1 || conflicts[0].Name != "config" {
+		t.Errorf("Conflicts() = %v, want dest/config", conflicts)
+	}
+}
+
+func TestTask_deleteUnselected(t1 *testing.T) {
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	listKinds := map[schema.GroupVersionResource]string{
+		configMaps: "ConfigMapList",
+	}
+	restored := map[string]string{velero.RestoreNameLabel: "final"}
+	object := func(namespace, name string, labels, annotations map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind("ConfigMap")
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		u.SetLabels(labels)
+		u.SetAnnotations(annotations)
+		return u
+	}
+	owned := object("dest", "drop-owned", restored, nil)
+	owned.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "keep-1"}})
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		object("dest", "keep-1", restored, nil),
+		object("dest", "keep-2", restored, map[string]string{"skip": "true"}),
+		object("dest", "drop-1", restored, nil),
+		object("dest", "drop-existing", nil, nil),
+		object("other", "drop-other", restored, nil),
+		owned)
+	t := &Task{
+		Log: log.WithName("test_deleteUnselected"),
+		Owner: &migapi.MigMigration{
+			Spec: migapi.MigMigrationSpec{
+				ResourceFilters: migapi.ResourceFilters{
+					{Namespaces: []string{"src"}, NameRegex: "^keep-"},
+					{Exclude: true, Annotations: map[string]string{"skip": ""}},
+				},
+			},
+		},
+		PlanResources: &migapi.PlanResources{
+			MigPlan: &migapi.MigPlan{
+				Spec: migapi.MigPlanSpec{Namespaces: []string{"src:dest"}},
+			},
+		},
+	}
+	if !t.hasUnselectedResources() {
+		t1.Fatalf("hasUnselectedResources() = false, want true")
+	}
+	err := t.deleteUnselected(client, []schema.GroupVersionResource{configMaps}, "final")
+	if err != nil {
+		t1.Fatalf("deleteUnselected() error = %v", err)
+	}
+	got := []string{}
+	for _, ns := range []string{"dest", "other"} {
+		list, err := client.Resource(configMaps).Namespace(ns).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t1.Fatalf("List() error = %v", err)
+		}
+		for _, item := range list.Items {
+			got = append(got, item.GetNamespace()+"/"+item.GetName())
+		}
+	}
+	sort.Strings(got)
+	want := []string{"dest/drop-existing", "dest/drop-owned", "dest/keep-1", "other/drop-other"}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("deleteUnselected() = %v, want %v", got, want)
+	}
+}
+
+func TestTask_reportResult(t1 *testing.T) {
+	tests := []struct {
+		name   string
+		status migapi.MigMigrationStatus
+		spec   migapi.MigMigrationSpec
+		want   string
+	}{
+		{
+			name: "succeeded",
+			want: migapi.Succeeded,
+		},
+		{
+			name: "succeeded with warnings",
+			status: migapi.MigMigrationStatus{
+				Conditions: migapi.Conditions{List: []migapi.Condition{
+					{Type: "PVWarning", Status: True, Category: migapi.Warn},
+				}},
+			},
+			want: SucceededWithWarnings,
+		},
+		{
+			name:   "failed",
+			status: migapi.MigMigrationStatus{Errors: []string{"backup failed"}},
+			want:   migapi.Failed,
+		},
+		{
+			name:   "canceled",
+			spec:   migapi.MigMigrationSpec{Canceled: true},
+			status: migapi.MigMigrationStatus{Errors: []string{"backup failed"}},
+			want:   Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &Task{
+				Owner: &migapi.MigMigration{Spec: tt.spec, Status: tt.status},
+			}
+			if got := t.reportResult(); got != tt.want {
+				t1.Errorf("reportResult() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_reportDirectVolumes(t *testing.T) {
+	owner := &migapi.MigMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-migration", Name: "migration", UID: "uid"},
+	}
+	labels := owner.GetCorrelationLabels()
+	labels[migapi.DirectVolumeMigrationLabel] = string(owner.UID)
+	pvcRef := func(name string) *kapi.ObjectReference {
+		return &kapi.ObjectReference{Namespace: "ns", Name: name}
+	}
+	dvm := &migapi.DirectVolumeMigration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-migration", Name: "dvm", Labels: labels},
+		Status: migapi.DirectVolumeMigrationStatus{
+			SuccessfulPods: []*migapi.PodProgress{
+				{PVCReference: pvcRef("data"), LastObservedProgressPercent: "100%"},
+			},
+			FailedPods: []*migapi.PodProgress{
+				{PVCReference: pvcRef("logs"), LastObservedProgressPercent: "40%"},
+			},
+			RsyncOperations: []*migapi.RsyncOperation{
+				{PVCReference: pvcRef("data"), Succeeded: true,
+					Stats: &migapi.RsyncTransferStats{Bytes: 4096, BytesTransferred: 1024}},
+				{PVCReference: pvcRef("logs"), Failed: true},
+			},
+		},
+	}
+	client, err := fakecompat.NewFakeClient(dvm)
+	if err != nil {
+		t.Fatalf("NewFakeClient() error = %v", err)
+	}
+	task := &Task{
+		Client: client,
+		Owner:  owner,
+		PlanResources: &migapi.PlanResources{
+			MigPlan: &migapi.MigPlan{ObjectMeta: metav1.ObjectMeta{Name: "plan"}},
+		},
+	}
+	report := &MigrationReport{}
+	err = task.reportDirectVolumes(report)
+	if err != nil {
+		t.Fatalf("reportDirectVolumes() error = %v", err)
+	}
+	want := []ReportVolume{
+		{Method: "rsync", Namespace: "ns", Name: "data", Phase: "Succeeded", Progress: "100%", BytesDone: 1024},
+		{Method: "rsync", Namespace: "ns", Name: "logs", Phase: "Failed", Progress: "40%"},
+	}
+	if !reflect.DeepEqual(report.Volumes, want) {
+		t.Errorf("reportDirectVolumes() volumes = %+v, want %+v", report.Volumes, want)
+	}
+	if report.BytesTransferred != 1024 {
+		t.Errorf("reportDirectVolumes() bytesTransferred = %d, want 1024", report.BytesTransferred)
+	}
+}
+
+func TestMigrationReport_Markdown(t *testing.T) {
+	started := metav1.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	report := &MigrationReport{
+		Migration:        "cutover",
+		Namespace:        "openshift-migration",
+		Plan:             "plan",
+		PlanDigest:       "abc",
+		Type:             "Final",
+		Result:           migapi.Failed,
+		Started:          &started,
+		Duration:         "5m0s",
+		BytesTransferred: 2048,
+		Steps: []ReportStep{
+			{Name: "Backup", Message: "Completed", Duration: "1m0s"},
+			{Name: "PreRestoreHooks", Message: "Completed", Progress: []string{"Hook ns/db (1/1): Succeeded"}},
+		},
+		Velero: []ReportVelero{
+			{Kind: "Backup", Name: "cutover-initial", Phase: "Completed", Items: 10, TotalItems: 10},
+		},
+		Volumes: []ReportVolume{
+			{Method: "restic", Namespace: "ns", Name: "data", Phase: "Completed", BytesDone: 2048, TotalBytes: 2048},
+		},
+		Errors: []string{"restore failed"},
+		Unhealthy: []migapi.UnhealthyNamespace{
+			{Name: "ns", Workloads: []migapi.Workload{{Name: "Deployment/web", Resources: []string{"Pod/web-1"}}}},
+		},
+	}
+	md := report.Markdown()
+	for _, want := range []string{
+		"# Migration openshift-migration/cutover",
+		"| Result | Failed |",
+		"| Started | 2024-05-01T10:00:00Z |",
+		"| Backup | Completed | 1m0s |",
+		"| Backup | cutover-initial | Completed | 10/10 | 0 | 0 |",
+		"| restic | ns/data | Completed | 2048/2048 |  |  |",
+		"- PreRestoreHooks: Hook ns/db (1/1): Succeeded",
+		"## Errors\n\n- restore failed",
+		"- ns/Deployment/web: Pod/web-1",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q in:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "## Warnings") {
+		t.Errorf("Markdown() has an empty Warnings section")
+	}
+}
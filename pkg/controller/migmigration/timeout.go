@@ -0,0 +1,50 @@
+package migmigration
+
+import (
+	"fmt"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Get whether the current phase has run past the deadline configured
+// on the plan or the controller settings.
+// Returns: timed out, elapsed, timeout.
+func (t *Task) phaseTimedOut() (bool, time.Duration, time.Duration) {
+	if !t.migrating() {
+		return false, 0, 0
+	}
+	timeout := t.PlanResources.MigPlan.GetPhaseTimeout(t.Phase)
+	if timeout <= 0 {
+		return false, 0, 0
+	}
+	start := t.Owner.Status.PhaseStartTimestamp
+	if start == nil {
+		return false, 0, 0
+	}
+	elapsed := time.Since(start.Time)
+	return elapsed > timeout, elapsed, timeout
+}
+
+// Fail the migration because the current phase has exceeded its deadline.
+func (t *Task) failPhaseTimedOut(elapsed, timeout time.Duration) {
+	message := fmt.Sprintf(
+		"Phase %s did not complete within the %s deadline, elapsed %s.",
+		t.Phase,
+		timeout,
+		elapsed.Round(time.Second))
+	t.Log.Info("Phase exceeded its deadline.",
+		"phaseTimeout", timeout,
+		"phaseElapsed", elapsed)
+	phaseTimeoutCounter.With(prometheus.Labels{"phase": t.Phase}).Inc()
+	t.Owner.Status.SetCondition(migapi.Condition{
+		Type:     PhaseTimedOut,
+		Status:   True,
+		Reason:   t.Phase,
+		Category: Advisory,
+		Message:  message,
+		Durable:  true,
+	})
+	t.fail(MigrationFailed, []string{message})
+}
@@ -0,0 +1,110 @@
+package migmigration
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/konveyor/mig-controller/pkg/transform"
+	"github.com/pkg/errors"
+	velero "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Apply the transforms of the plan to the resources restored by the final
+// restore on the destination cluster. The destination applications are not
+// unquiesced yet. The transforms applied to a resource are recorded in its
+// annotations so that a transform is applied once.
+// Returns: the resources that could not be transformed.
+func (t *Task) transformRestoredResources() ([]string, error) {
+	restore, err := t.getFinalRestore()
+	if err != nil {
+		return nil, err
+	}
+	if restore == nil {
+		return nil, errors.New("Restore not found")
+	}
+	client, err := t.getDestinationClient()
+	if err != nil {
+		return nil, err
+	}
+	failures := []string{}
+	transforms := t.PlanResources.MigPlan.Spec.Transforms
+	for i := range transforms {
+		rule := &transforms[i]
+		restMapping, err := client.RESTMapper().RESTMapping(rule.GroupKind())
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		ruleFailures, err := t.applyTransform(client, restore.Name, rule, restMapping.GroupVersionKind)
+		if err != nil {
+			return nil, err
+		}
+		failures = append(failures, ruleFailures...)
+	}
+
+	return failures, nil
+}
+
+// Apply a transform to the resources of a kind restored by a restore.
+// Returns: the resources that could not be transformed.
+func (t *Task) applyTransform(
+	client k8sclient.Client,
+	restoreName string,
+	rule *migapi.RestoreTransform,
+	gvk schema.GroupVersionKind) ([]string, error) {
+	mapping := t.PlanResources.MigPlan.GetNamespaceMapping()
+	failures := []string{}
+	for _, ns := range t.sourceNamespaces() {
+		if !rule.AppliesTo(ns) {
+			continue
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err := client.List(
+			context.TODO(),
+			list,
+			k8sclient.InNamespace(mapping[ns]),
+			k8sclient.MatchingLabels{velero.RestoreNameLabel: restoreName})
+		if err != nil {
+			return nil, err
+		}
+		for j := range list.Items {
+			r := &list.Items[j]
+			selected, err := transform.Selects(rule, ns, r)
+			if err != nil {
+				return nil, err
+			}
+			if !selected || transform.Applied(rule, r) {
+				continue
+			}
+			transformed, err := transform.Apply(rule, r)
+			if err == nil {
+				err = client.Update(context.TODO(), transformed)
+				if k8serror.IsConflict(err) {
+					return nil, err
+				}
+			}
+			if err != nil {
+				failures = append(failures,
+					fmt.Sprintf("Transform %s of %s %s failed: %s",
+						rule.Name, rule.Kind, path.Join(r.GetNamespace(), r.GetName()), err.Error()))
+				continue
+			}
+			t.Log.Info("Transformed restored resource.",
+				"transform", rule.Name,
+				"kind", rule.Kind,
+				"resource", path.Join(r.GetNamespace(), r.GetName()))
+		}
+	}
+
+	return failures, nil
+}
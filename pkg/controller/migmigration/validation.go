@@ -13,6 +13,7 @@ import (
 	migref "github.com/konveyor/mig-controller/pkg/reference"
 	"github.com/opentracing/opentracing-go"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -40,8 +41,20 @@ const (
 	StaleDestVeleroCRsDeleted          = "StaleDestVeleroCRsDeleted"
 	StaleResticCRsDeleted              = "StaleResticCRsDeleted"
 	DirectVolumeMigrationBlocked       = "DirectVolumeMigrationBlocked"
+	DirectVolumeVerificationFailed     = "DirectVolumeVerificationFailed"
 	InvalidSpec                        = "InvalidSpec"
 	ConflictingPVCMappings             = "ConflictingPVCMappings"
+	Paused                             = "Paused"
+	PhaseTimedOut                      = "PhaseTimedOut"
+	InvalidRetryMigrationRef           = "InvalidRetryMigrationRef"
+	Retrying                           = "Retrying"
+	DryRun                             = "DryRun"
+	InvalidQuiescePolicy               = "InvalidQuiescePolicy"
+	QuiescedPodsRunning                = "QuiescedPodsRunning"
+	InvalidRollbackScope               = "InvalidRollbackScope"
+	RollbackReported                   = "RollbackReported"
+	MigrationReported                  = "MigrationReported"
+	InvalidResourceFilters             = "InvalidResourceFilters"
 )
 
 // Categories
@@ -98,6 +111,22 @@ func (r ReconcileMigMigration) validate(ctx context.Context, migration *migapi.M
 
 	}
 
+	// Retried migration.
+	err = r.validateRetryMigration(ctx, plan, migration)
+	if err != nil {
+		log.V(4).Error(err, "Validation check for retried migration failed")
+		return err
+	}
+
+	// Quiesce policy.
+	r.validateQuiescePolicy(migration)
+
+	// Rollback scope.
+	r.validateRollbackScope(plan, migration)
+
+	// Resource filters.
+	r.validateResourceFilters(plan, migration)
+
 	// Validate registries running.
 	err = r.validateRegistriesRunning(ctx, migration)
 	if err != nil {
@@ -108,6 +137,92 @@ func (r ReconcileMigMigration) validate(ctx context.Context, migration *migapi.M
 	return nil
 }
 
+// validateQuiescePolicy validates the selectors and kind settings of the quiesce policy
+func (r ReconcileMigMigration) validateQuiescePolicy(migration *migapi.MigMigration) {
+	err := migration.Spec.QuiescePolicy.Validate()
+	if err != nil {
+		migration.Status.SetCondition(migapi.Condition{
+			Type:     InvalidQuiescePolicy,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  fmt.Sprintf("The `quiescePolicy` is not valid: %s.", err),
+		})
+	}
+}
+
+// validateResourceFilters validates the expressions and namespaces of the resource filters
+func (r ReconcileMigMigration) validateResourceFilters(plan *migapi.MigPlan, migration *migapi.MigMigration) {
+	if len(migration.Spec.ResourceFilters) == 0 || plan == nil {
+		return
+	}
+	problems := migration.Spec.ResourceFilters.Validate(plan.GetSourceNamespaces())
+	if len(problems) > 0 {
+		migration.Status.SetCondition(migapi.Condition{
+			Type:     InvalidResourceFilters,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  "The `resourceFilters` are not valid: [].",
+			Items:    problems,
+		})
+	}
+}
+
+// validateRollbackScope validates the namespaces and selector of the rollback scope
+func (r ReconcileMigMigration) validateRollbackScope(plan *migapi.MigPlan, migration *migapi.MigMigration) {
+	scope := migration.Spec.RollbackScope
+	if scope == nil {
+		return
+	}
+	if !migration.Spec.Rollback {
+		migration.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRollbackScope,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  "The `rollbackScope` is only supported by rollback migrations.",
+		})
+		return
+	}
+	if scope.LabelSelector != nil {
+		_, err := metav1.LabelSelectorAsSelector(scope.LabelSelector)
+		if err != nil {
+			migration.Status.SetCondition(migapi.Condition{
+				Type:     InvalidRollbackScope,
+				Status:   True,
+				Reason:   NotSupported,
+				Category: Critical,
+				Message:  fmt.Sprintf("The `rollbackScope` label selector is not valid: %s.", err),
+			})
+			return
+		}
+	}
+	if plan == nil {
+		return
+	}
+	planNamespaces := map[string]bool{}
+	for _, ns := range plan.GetSourceNamespaces() {
+		planNamespaces[ns] = true
+	}
+	notFound := []string{}
+	for _, ns := range scope.Namespaces {
+		if !planNamespaces[ns] {
+			notFound = append(notFound, ns)
+		}
+	}
+	if len(notFound) > 0 {
+		migration.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRollbackScope,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message:  "The `rollbackScope` namespaces [] are not migrated by the plan.",
+			Items:    notFound,
+		})
+	}
+}
+
 // validateMigrationType validates migration spec fields and input based on type of migration being performed
 func (r ReconcileMigMigration) validateMigrationType(ctx context.Context, plan *migapi.MigPlan, migration *migapi.MigMigration) error {
 	if opentracing.SpanFromContext(ctx) != nil {
@@ -315,8 +430,8 @@ func (r ReconcileMigMigration) validateFinalMigration(ctx context.Context, plan
 
 	hasCondition := false
 	for _, m := range migrations {
-		// Ignore self, stage migrations, state migrations, canceled migrations
-		if m.UID == migration.UID || m.Spec.Stage || m.Spec.MigrateState || m.Spec.Canceled {
+		// Ignore self, stage migrations, state migrations, canceled migrations, dry runs
+		if m.UID == migration.UID || m.Spec.Stage || m.Spec.MigrateState || m.Spec.Canceled || m.Spec.DryRun {
 			continue
 		}
 
@@ -357,6 +472,100 @@ func (r ReconcileMigMigration) validateFinalMigration(ctx context.Context, plan
 	return nil
 }
 
+// Validate the migration referenced by `retryMigrationRef`.
+// An error condition is added when the referenced migration:
+//
+//	Cannot be found.
+//	Belongs to another plan.
+//	Is not a final migration or the retry is not a final migration.
+//	Has not failed or is still running.
+func (r ReconcileMigMigration) validateRetryMigration(ctx context.Context, plan *migapi.MigPlan,
+	migration *migapi.MigMigration) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRetryMigration")
+		defer span.Finish()
+	}
+	ref := migration.Spec.RetryMigrationRef
+	if plan == nil || ref == nil {
+		return nil
+	}
+
+	// NotSet
+	if !migref.RefSet(ref) {
+		migration.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRetryMigrationRef,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "The `retryMigrationRef` must reference a valid `migmigration`.",
+		})
+		return nil
+	}
+
+	retried, err := migapi.GetMigration(r, ref)
+	if err != nil {
+		return err
+	}
+
+	// NotFound
+	if retried == nil {
+		migration.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRetryMigrationRef,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message: fmt.Sprintf("The `retryMigrationRef` must reference a valid `migmigration`, subject: %s.",
+				path.Join(ref.Namespace, ref.Name)),
+		})
+		return nil
+	}
+
+	// Plan
+	if !migref.RefEquals(retried.Spec.MigPlanRef, migration.Spec.MigPlanRef) {
+		migration.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRetryMigrationRef,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: fmt.Sprintf("The `retryMigrationRef` must reference a migration of the same plan, subject: %s.",
+				path.Join(ref.Namespace, ref.Name)),
+		})
+		return nil
+	}
+
+	// Final
+	if isNotFinal(migration) || isNotFinal(retried) {
+		migration.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRetryMigrationRef,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: fmt.Sprintf("Only a final migration can retry a failed final migration, subject: %s.",
+				path.Join(ref.Namespace, ref.Name)),
+		})
+		return nil
+	}
+
+	// Failed
+	if !retried.Status.HasCondition(migapi.Failed) || retried.Status.Phase != Completed {
+		migration.Status.SetCondition(migapi.Condition{
+			Type:     InvalidRetryMigrationRef,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: fmt.Sprintf("The `retryMigrationRef` must reference a migration that has failed, subject: %s.",
+				path.Join(ref.Namespace, ref.Name)),
+		})
+	}
+
+	return nil
+}
+
+// isNotFinal checks whether the migration is a stage, state, rollback or canceled migration
+func isNotFinal(migration *migapi.MigMigration) bool {
+	return migration.Spec.Stage || migration.Spec.MigrateState || migration.Spec.Rollback || migration.Spec.Canceled
+}
+
 func (r ReconcileMigMigration) validateRegistriesRunning(ctx context.Context, migration *migapi.MigMigration) error {
 	if opentracing.SpanFromContext(ctx) != nil {
 		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateRegistriesRunning")
@@ -434,6 +434,10 @@ func (r *ReconcileMigPlan) planSuspended(ctx context.Context, plan *migapi.MigPl
 	})
 
 	for _, m := range migrations {
+		// Dry runs leave the clusters untouched
+		if m.Spec.DryRun {
+			continue
+		}
 		// If a migration is running, plan should be suspended
 		if m.Status.HasCondition(migapi.Running) {
 			suspended = true
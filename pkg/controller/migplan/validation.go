@@ -13,16 +13,20 @@ import (
 
 	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
 	"github.com/konveyor/mig-controller/pkg/controller/migcluster"
+	"github.com/konveyor/mig-controller/pkg/controller/migmigration"
 	"github.com/konveyor/mig-controller/pkg/health"
 	"github.com/konveyor/mig-controller/pkg/pods"
 	migref "github.com/konveyor/mig-controller/pkg/reference"
 	"github.com/konveyor/mig-controller/pkg/settings"
+	"github.com/konveyor/mig-controller/pkg/transform"
 	_ "github.com/opentracing/opentracing-go"
 	kapi "k8s.io/api/core/v1"
 	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	k8sLabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/rest"
@@ -87,8 +91,15 @@ const (
 	InvalidHookNSName                          = "InvalidHookNSName"
 	InvalidHookSAName                          = "InvalidHookSAName"
 	HookPhaseUnknown                           = "HookPhaseUnknown"
-	HookPhaseDuplicate                         = "HookPhaseDuplicate"
+	HookPhaseNotRun                            = "HookPhaseNotRun"
+	InvalidHookFailurePolicy                   = "InvalidHookFailurePolicy"
 	IntraClusterMigration                      = "IntraClusterMigration"
+	InvalidPhaseTimeout                        = "InvalidPhaseTimeout"
+	InvalidQuiescePolicy                       = "InvalidQuiescePolicy"
+	InvalidResourceFilters                     = "InvalidResourceFilters"
+	InvalidTransforms                          = "InvalidTransforms"
+	InvalidRsyncOptions                        = "InvalidRsyncOptions"
+	DirectVolumeSettingsNotUsed                = "DirectVolumeSettingsNotUsed"
 )
 
 // Categories
@@ -116,6 +127,8 @@ const (
 	DuplicateNs            = "DuplicateNamespaces"
 	ConflictingNamespaces  = "ConflictingNamespaces"
 	ConflictingPermissions = "ConflictingPermissions"
+	NotValid               = "NotValid"
+	NotSupported           = "NotSupported"
 )
 
 // Statuses
@@ -214,6 +227,27 @@ func (r ReconcileMigPlan) validate(ctx context.Context, plan *migapi.MigPlan) er
 		return err
 	}
 
+	// Resource filters
+	err = r.validateResourceFilters(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	// Restore transforms
+	r.validateTransforms(plan)
+
+	// Rsync options
+	r.validateRsyncOptions(plan)
+
+	// Direct volume migration settings
+	r.validateDirectVolumeSettings(plan)
+
+	// Phase timeouts
+	r.validatePhaseTimeouts(plan)
+
+	// Quiesce policy
+	r.validateQuiescePolicy(plan)
+
 	// GVK
 	err = r.compareGVK(ctx, plan)
 	if err != nil {
@@ -262,6 +296,218 @@ func (r ReconcileMigPlan) validateIncludedResources(ctx context.Context, plan *m
 	return nil
 }
 
+// validateResourceFilters checks spec.ResourceFilters field of the plan for invalid
+// expressions, namespaces and Group/Kinds, raises critical condition if one or more problems found
+func (r ReconcileMigPlan) validateResourceFilters(ctx context.Context, plan *migapi.MigPlan) error {
+	if len(plan.Spec.ResourceFilters) == 0 {
+		return nil
+	}
+	problems := plan.Spec.ResourceFilters.Validate(plan.GetSourceNamespaces())
+	srcCluster, err := plan.GetSourceCluster(r)
+	if err != nil {
+		return err
+	}
+	if srcCluster != nil && srcCluster.Status.IsReady() {
+		srcClient, err := srcCluster.GetClient(r)
+		if err != nil {
+			return err
+		}
+		for i, filter := range plan.Spec.ResourceFilters {
+			for _, kind := range filter.Kinds {
+				_, err := srcClient.RESTMapper().RESTMapping(schema.GroupKind{Group: kind.Group, Kind: kind.Kind})
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("[%d] kinds: %s not found", i, kind.String()))
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		plan.Status.SetCondition(
+			migapi.Condition{
+				Category: Critical,
+				Status:   True,
+				Type:     InvalidResourceFilters,
+				Reason:   NotValid,
+				Message:  "The spec.resourceFilters are not valid: [].",
+				Items:    problems,
+			},
+		)
+	}
+	return nil
+}
+
+// validateTransforms checks spec.Transforms field of the plan for invalid
+// transforms, raises critical condition if one or more invalid transforms found
+func (r ReconcileMigPlan) validateTransforms(plan *migapi.MigPlan) {
+	problems := []string{}
+	known := map[string]bool{}
+	for _, ns := range plan.GetSourceNamespaces() {
+		known[ns] = true
+	}
+	names := map[string]bool{}
+	for i := range plan.Spec.Transforms {
+		rule := &plan.Spec.Transforms[i]
+		err := transform.Validate(rule)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("[%d] %s", i, err.Error()))
+		}
+		if names[rule.Name] {
+			problems = append(problems, fmt.Sprintf("[%d] name: %s not unique", i, rule.Name))
+		}
+		names[rule.Name] = true
+		for _, ns := range rule.Namespaces {
+			if !known[ns] {
+				problems = append(problems, fmt.Sprintf("[%d] namespaces: %s not migrated by the plan", i, ns))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		plan.Status.SetCondition(
+			migapi.Condition{
+				Category: Critical,
+				Status:   True,
+				Type:     InvalidTransforms,
+				Reason:   NotValid,
+				Message:  "The spec.transforms are not valid: [].",
+				Items:    problems,
+			},
+		)
+	}
+}
+
+// validateRsyncOptions checks the rsync options and transfer limits of the plan
+// and the rsync options of the PVs for unsafe options, raises critical condition if one or more invalid options found
+func (r ReconcileMigPlan) validateRsyncOptions(plan *migapi.MigPlan) {
+	problems := []string{}
+	if plan.Spec.RsyncOptions != nil {
+		err := plan.Spec.RsyncOptions.Validate()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("rsyncOptions: %s", err.Error()))
+		}
+	}
+	if plan.Spec.RsyncTransferLimits != nil {
+		err := plan.Spec.RsyncTransferLimits.Validate()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("rsyncTransferLimits: %s", err.Error()))
+		}
+	}
+	for _, pv := range plan.Spec.PersistentVolumes.List {
+		if pv.Selection.RsyncOptions == nil {
+			continue
+		}
+		err := pv.Selection.RsyncOptions.Validate()
+		if err != nil {
+			problems = append(problems,
+				fmt.Sprintf("%s/%s: %s", pv.PVC.Namespace, pv.PVC.Name, err.Error()))
+		}
+	}
+	if len(problems) > 0 {
+		plan.Status.SetCondition(
+			migapi.Condition{
+				Category: Critical,
+				Status:   True,
+				Type:     InvalidRsyncOptions,
+				Reason:   NotValid,
+				Message:  "The rsync options are not valid: [].",
+				Items:    problems,
+			},
+		)
+	}
+}
+
+// validateDirectVolumeSettings checks whether the direct volume migration settings
+// of the plan are used by the migrations, raises a warning condition listing the
+// settings when direct volume migration is disabled in the migrations
+func (r ReconcileMigPlan) validateDirectVolumeSettings(plan *migapi.MigPlan) {
+	if migmigration.RunsDirectVolumeMigration() {
+		return
+	}
+	settings := []string{}
+	if plan.Spec.RsyncOptions != nil {
+		settings = append(settings, "rsyncOptions")
+	}
+	if plan.Spec.RsyncTransferLimits != nil {
+		settings = append(settings, "rsyncTransferLimits")
+	}
+	if plan.Spec.IncrementalVolumeMigration {
+		settings = append(settings, "incrementalVolumeMigration")
+	}
+	for _, pv := range plan.Spec.PersistentVolumes.List {
+		if pv.Selection.RsyncOptions != nil {
+			settings = append(settings,
+				fmt.Sprintf("%s/%s: rsyncOptions", pv.PVC.Namespace, pv.PVC.Name))
+		}
+	}
+	if len(settings) > 0 {
+		plan.Status.SetCondition(
+			migapi.Condition{
+				Category: Warn,
+				Status:   True,
+				Type:     DirectVolumeSettingsNotUsed,
+				Reason:   NotSupported,
+				Message: "The direct volume migration settings [] have no effect while direct volume" +
+					" migration is disabled in the migrations.",
+				Items: settings,
+			},
+		)
+	}
+}
+
+// validatePhaseTimeouts checks spec.PhaseTimeouts field of the plan for negative
+// durations and unknown phases, raises critical condition if one or more invalid
+// timeouts found
+func (r ReconcileMigPlan) validatePhaseTimeouts(plan *migapi.MigPlan) {
+	problems := getPhaseTimeoutProblems(plan.Spec.PhaseTimeouts)
+	if len(problems) > 0 {
+		plan.Status.SetCondition(
+			migapi.Condition{
+				Category: Critical,
+				Status:   True,
+				Type:     InvalidPhaseTimeout,
+				Reason:   NotValid,
+				Message:  "The timeouts specified in spec.phaseTimeouts are not valid: [].",
+				Items:    problems,
+			},
+		)
+	}
+}
+
+// getPhaseTimeoutProblems returns the problems of the phase timeouts sorted by phase.
+func getPhaseTimeoutProblems(timeouts map[string]metav1.Duration) []string {
+	phases := []string{}
+	for phase := range timeouts {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+	problems := []string{}
+	for _, phase := range phases {
+		if !migmigration.IsMigrationPhase(phase) {
+			problems = append(problems, fmt.Sprintf("%s: not a phase of the stage, final or rollback migrations", phase))
+		}
+		if timeouts[phase].Duration < 0 {
+			problems = append(problems, fmt.Sprintf("%s: must not be negative", phase))
+		}
+	}
+	return problems
+}
+
+// validateQuiescePolicy checks the selectors and kind settings of spec.QuiescePolicy,
+// raises critical condition when the policy cannot be applied
+func (r ReconcileMigPlan) validateQuiescePolicy(plan *migapi.MigPlan) {
+	err := plan.Spec.QuiescePolicy.Validate()
+	if err != nil {
+		plan.Status.SetCondition(
+			migapi.Condition{
+				Category: Critical,
+				Status:   True,
+				Type:     InvalidQuiescePolicy,
+				Reason:   NotValid,
+				Message:  fmt.Sprintf("The spec.quiescePolicy is not valid: %s.", err),
+			},
+		)
+	}
+}
+
 // setMigrationType given a migration type and a message, sets MigrationTypeIdentified condition
 func setMigrationType(plan *migapi.MigPlan, migrationType migapi.MigrationType, message string, durable bool) {
 	plan.Status.SetCondition(migapi.Condition{
@@ -291,10 +537,17 @@ func (r ReconcileMigPlan) validatePossibleMigrationTypes(ctx context.Context, pl
 	if err != nil {
 		return err
 	}
-	migrations, err := plan.ListMigrations(r)
+	allMigrations, err := plan.ListMigrations(r)
 	if err != nil {
 		return err
 	}
+	// dry runs leave the clusters untouched and do not determine the type of the plan
+	migrations := []*migapi.MigMigration{}
+	for _, migration := range allMigrations {
+		if !migration.Spec.DryRun {
+			migrations = append(migrations, migration)
+		}
+	}
 	// find out whether any of the source namespaces are mapped to destination namespaces
 	mappedNamespaces := 0
 	for srcNs, destNs := range plan.GetNamespaceMapping() {
@@ -1550,8 +1803,6 @@ func (r ReconcileMigPlan) validateHooks(ctx context.Context, plan *migapi.MigPla
 		defer span.Finish()
 	}
 
-	var preBackupCount, postBackupCount, preRestoreCount, postRestoreCount int = 0, 0, 0, 0
-
 	for _, hook := range plan.Spec.Hooks {
 		migHook := migapi.MigHook{}
 		err := r.Get(
@@ -1577,7 +1828,8 @@ func (r ReconcileMigPlan) validateHooks(ctx context.Context, plan *migapi.MigPla
 		}
 
 		// InvalidHookSA
-		if errs := validation.IsDNS1123Subdomain(hook.ServiceAccount); len(errs) != 0 {
+		// Exec hooks run in the application pods and have no executor.
+		if errs := validation.IsDNS1123Subdomain(hook.ServiceAccount); len(errs) != 0 && migHook.Spec.Exec == nil {
 			plan.Status.SetCondition(migapi.Condition{
 				Type:     InvalidHookSAName,
 				Status:   True,
@@ -1611,15 +1863,28 @@ func (r ReconcileMigPlan) validateHooks(ctx context.Context, plan *migapi.MigPla
 			return nil
 		}
 
+		// InvalidHookFailurePolicy
+		switch hook.GetFailurePolicy() {
+		case migapi.HookFail, migapi.HookIgnore, migapi.HookRetry:
+		default:
+			plan.Status.SetCondition(migapi.Condition{
+				Type:     InvalidHookFailurePolicy,
+				Status:   True,
+				Reason:   NotValid,
+				Category: Critical,
+				Message:  "The failurePolicy specified is invalid, acceptable values are: Fail, Ignore and Retry.",
+			})
+		}
+
 		switch hook.Phase {
-		case migapi.PreRestoreHookPhase:
-			preRestoreCount++
-		case migapi.PostRestoreHookPhase:
-			postRestoreCount++
-		case migapi.PreBackupHookPhase:
-			preBackupCount++
-		case migapi.PostBackupHookPhase:
-			postBackupCount++
+		case migapi.PreQuiesceHookPhase,
+			migapi.PreRestoreHookPhase,
+			migapi.PostRestoreHookPhase,
+			migapi.PreBackupHookPhase,
+			migapi.PostBackupHookPhase,
+			migapi.PostDirectVolumeHookPhase,
+			migapi.PreRollbackHookPhase,
+			migapi.PostRollbackHookPhase:
 		default:
 			plan.Status.SetCondition(migapi.Condition{
 				Type:     HookPhaseUnknown,
@@ -1629,19 +1894,18 @@ func (r ReconcileMigPlan) validateHooks(ctx context.Context, plan *migapi.MigPla
 			})
 			return nil
 		}
-	}
 
-	if preRestoreCount > 1 ||
-		postRestoreCount > 1 ||
-		preBackupCount > 1 ||
-		postBackupCount > 1 {
-		plan.Status.SetCondition(migapi.Condition{
-			Type:     HookPhaseDuplicate,
-			Status:   True,
-			Category: Critical,
-			Message:  "Only one hook may be specified per phase.",
-		})
-		return nil
+		// HookPhaseNotRun
+		if hook.Phase == migapi.PostDirectVolumeHookPhase && !migmigration.RunsDirectVolumeMigration() {
+			plan.Status.SetCondition(migapi.Condition{
+				Type:     HookPhaseNotRun,
+				Status:   True,
+				Reason:   NotSupported,
+				Category: Warn,
+				Message: "One or more referenced hooks are in the PostDirectVolume phase, which is not run" +
+					" while direct volume migration is disabled in the migrations.",
+			})
+		}
 	}
 
 	return nil
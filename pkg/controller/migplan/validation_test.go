@@ -2,6 +2,7 @@ package migplan
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
@@ -254,3 +255,144 @@ func TestReconcileMigPlan_validatePossibleMigrationTypes(t *testing.T) {
 		})
 	}
 }
+
+func Test_getPhaseTimeoutProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts map[string]metav1.Duration
+		want     []string
+	}{
+		{
+			name:     "given no timeouts, no problems are returned",
+			timeouts: nil,
+			want:     []string{},
+		},
+		{
+			name: "given timeouts of migration phases, no problems are returned",
+			timeouts: map[string]metav1.Duration{
+				"EnsureFinalRestore": {Duration: time.Hour},
+				"DeleteMigrated":     {Duration: 0},
+			},
+			want: []string{},
+		},
+		{
+			name: "given negative and unknown phases, the problems are returned sorted by phase",
+			timeouts: map[string]metav1.Duration{
+				"QuiesceApplications": {Duration: -time.Minute},
+				"EnsureFinalRestor":   {Duration: time.Hour},
+				"MigrationFailed":     {Duration: time.Hour},
+			},
+			want: []string{
+				"EnsureFinalRestor: not a phase of the stage, final or rollback migrations",
+				"MigrationFailed: not a phase of the stage, final or rollback migrations",
+				"QuiesceApplications: must not be negative",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getPhaseTimeoutProblems(tt.timeouts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getPhaseTimeoutProblems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileMigPlan_validateHooks(t *testing.T) {
+	hook := &migapi.MigHook{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: migapi.OpenshiftMigrationNamespace},
+		Status: migapi.MigHookStatus{
+			Conditions: migapi.Conditions{
+				List: []migapi.Condition{{Type: migapi.Ready, Status: True}},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		phase   string
+		wantRun bool
+	}{
+		{
+			name:    "post-direct-volume hook not run",
+			phase:   migapi.PostDirectVolumeHookPhase,
+			wantRun: false,
+		},
+		{
+			name:    "post-restore hook run",
+			phase:   migapi.PostRestoreHookPhase,
+			wantRun: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := fakecompat.NewFakeClient(hook.DeepCopy())
+			r := ReconcileMigPlan{Client: client, tracer: mocktracer.New()}
+			plan := &migapi.MigPlan{
+				Spec: migapi.MigPlanSpec{
+					Hooks: []migapi.MigPlanHook{
+						{
+							Reference:          &v1.ObjectReference{Name: hook.Name, Namespace: hook.Namespace},
+							Phase:              tt.phase,
+							ServiceAccount:     "migration-controller",
+							ExecutionNamespace: migapi.OpenshiftMigrationNamespace,
+						},
+					},
+				},
+			}
+			if err := r.validateHooks(context.TODO(), plan); err != nil {
+				t.Fatalf("validateHooks() error = %v", err)
+			}
+			if plan.Status.HasCriticalCondition() {
+				t.Errorf("validateHooks() critical conditions = %v", plan.Status.Conditions.List)
+			}
+			if got := !plan.Status.HasCondition(HookPhaseNotRun); got != tt.wantRun {
+				t.Errorf("validateHooks() run = %v, want %v", got, tt.wantRun)
+			}
+		})
+	}
+}
+
+func TestReconcileMigPlan_validateDirectVolumeSettings(t *testing.T) {
+	bwLimit := 512
+	tests := []struct {
+		name      string
+		spec      migapi.MigPlanSpec
+		wantItems []string
+	}{
+		{
+			name: "no direct volume migration settings",
+		},
+		{
+			name: "direct volume migration settings not used",
+			spec: migapi.MigPlanSpec{
+				RsyncOptions:               &migapi.RsyncOptions{BwLimit: &bwLimit},
+				RsyncTransferLimits:        &migapi.RsyncTransferLimits{},
+				IncrementalVolumeMigration: true,
+				PersistentVolumes: migapi.PersistentVolumes{
+					List: []migapi.PV{
+						{PVC: migapi.PVC{Namespace: "ns", Name: "data"}, Selection: migapi.Selection{RsyncOptions: &migapi.RsyncOptions{}}},
+						{PVC: migapi.PVC{Namespace: "ns", Name: "logs"}},
+					},
+				},
+			},
+			wantItems: []string{"rsyncOptions", "rsyncTransferLimits", "incrementalVolumeMigration", "ns/data: rsyncOptions"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ReconcileMigPlan{}
+			plan := &migapi.MigPlan{Spec: tt.spec}
+			r.validateDirectVolumeSettings(plan)
+			var got []string
+			if condition := plan.Status.FindCondition(DirectVolumeSettingsNotUsed); condition != nil {
+				if condition.Category != Warn {
+					t.Errorf("validateDirectVolumeSettings() category = %v, want %v", condition.Category, Warn)
+				}
+				got = condition.Items
+			}
+			if !reflect.DeepEqual(got, tt.wantItems) {
+				t.Errorf("validateDirectVolumeSettings() items = %v, want %v", got, tt.wantItems)
+			}
+		})
+	}
+}
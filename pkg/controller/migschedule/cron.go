@@ -0,0 +1,193 @@
+package migschedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedules are not searched past this horizon.
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// Cron macros.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Month names.
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// Day of week names.
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// A parsed five field cron schedule.
+// Each field is a bit set of the matching values.
+// The day of month and day of week fields match
+// when either matches, unless one of them is `*`.
+type cronSchedule struct {
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+}
+
+// A cron field definition.
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: monthNames}
+	dowField    = cronField{name: "day of week", min: 0, max: 7, names: dayNames}
+)
+
+// Parse a standard five field cron expression or macro.
+func parseCron(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, found := cronMacros[strings.ToLower(spec)]; found {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, found %d in %q", len(fields), spec)
+	}
+	var err error
+	schedule := &cronSchedule{
+		domStar: strings.HasPrefix(fields[2], "*") || fields[2] == "?",
+		dowStar: strings.HasPrefix(fields[4], "*") || fields[4] == "?",
+	}
+	if schedule.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	// Sunday is either 0 or 7.
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	return schedule, nil
+}
+
+// Parse a comma separated list of values, ranges and steps.
+func (f cronField) parse(str string) (uint64, error) {
+	bits := uint64(0)
+	for _, item := range strings.Split(str, ",") {
+		step := 1
+		if parts := strings.SplitN(item, "/", 2); len(parts) == 2 {
+			n, err := strconv.Atoi(parts[1])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", parts[1], f.name)
+			}
+			item, step = parts[0], n
+		}
+		first, last := f.min, f.max
+		switch {
+		case item == "*" || item == "?":
+		case strings.Contains(item, "-"):
+			bounds := strings.SplitN(item, "-", 2)
+			var err error
+			if first, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if last, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+			if first > last {
+				return 0, fmt.Errorf("invalid range %q in %s field", item, f.name)
+			}
+		default:
+			n, err := f.value(item)
+			if err != nil {
+				return 0, err
+			}
+			first = n
+			if step == 1 {
+				last = n
+			}
+		}
+		for n := first; n <= last; n += step {
+			bits |= 1 << uint(n)
+		}
+	}
+	return bits, nil
+}
+
+// Parse a single value, by number or by name.
+func (f cronField) value(str string) (int, error) {
+	if n, found := f.names[strings.ToLower(str)]; found {
+		return n, nil
+	}
+	n, err := strconv.Atoi(str)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field, expected %d-%d", str, f.name, f.min, f.max)
+	}
+	return n, nil
+}
+
+// Get the first time matching the schedule strictly after the specified time.
+// Returns the zero time when there is no match within the search horizon.
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronHorizon)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Get whether the day of month and day of week fields match.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,87 @@
+package migschedule
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseCron(t *testing.T) {
+	after := time.Date(2021, time.March, 10, 10, 30, 15, 0, time.UTC) // Wednesday
+	tests := []struct {
+		name    string
+		spec    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "every 15 minutes",
+			spec: "*/15 * * * *",
+			want: time.Date(2021, time.March, 10, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "daily macro",
+			spec: "@daily",
+			want: time.Date(2021, time.March, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekdays at 2am by name",
+			spec: "0 2 * * mon-fri",
+			want: time.Date(2021, time.March, 11, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sunday as 7",
+			spec: "0 0 * * 7",
+			want: time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or day of week",
+			spec: "0 0 1 * fri",
+			want: time.Date(2021, time.March, 12, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "list of months rolls over the year",
+			spec: "0 0 1 jan,feb *",
+			want: time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "never matching date",
+			spec: "0 0 31 2 *",
+			want: time.Time{},
+		},
+		{
+			name:    "too few fields",
+			spec:    "0 0 * *",
+			wantErr: true,
+		},
+		{
+			name:    "value out of range",
+			spec:    "60 * * * *",
+			wantErr: true,
+		},
+		{
+			name:    "inverted range",
+			spec:    "0 5-1 * * *",
+			wantErr: true,
+		},
+		{
+			name:    "invalid step",
+			spec:    "*/0 * * * *",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := parseCron(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseCron() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := schedule.Next(after); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
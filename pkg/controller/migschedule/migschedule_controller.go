@@ -0,0 +1,174 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migschedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/konveyor/mig-controller/pkg/errorutil"
+	migref "github.com/konveyor/mig-controller/pkg/reference"
+	"github.com/opentracing/opentracing-go"
+	"k8s.io/klog/v2/klogr"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = klogr.New().WithName("schedule")
+
+// Add creates a new MigSchedule Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileMigSchedule{Client: mgr.GetClient(), scheme: mgr.GetScheme(), EventRecorder: mgr.GetEventRecorderFor("migschedule_controller")}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("migschedule-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to MigSchedule
+	err = c.Watch(
+		&source.Kind{Type: &migapi.MigSchedule{}},
+		&handler.EnqueueRequestForObject{},
+		&SchedulePredicate{
+			Namespace: migapi.OpenshiftMigrationNamespace,
+		})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to MigPlans referenced by MigSchedules.
+	err = c.Watch(
+		&source.Kind{Type: &migapi.MigPlan{}},
+		handler.EnqueueRequestsFromMapFunc(func(a client.Object) []reconcile.Request {
+			return migref.GetRequests(a, migapi.OpenshiftMigrationNamespace, migapi.MigSchedule{})
+		}),
+		&PlanPredicate{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileMigSchedule{}
+
+// ReconcileMigSchedule reconciles a MigSchedule object
+type ReconcileMigSchedule struct {
+	client.Client
+	record.EventRecorder
+
+	scheme *runtime.Scheme
+	tracer opentracing.Tracer
+}
+
+func (r *ReconcileMigSchedule) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	var err error
+	log = log.WithName("schedule").WithValues("migSchedule", request.Name)
+
+	// Fetch the MigSchedule instance
+	schedule := &migapi.MigSchedule{}
+	err = r.Get(context.TODO(), request.NamespacedName, schedule)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{Requeue: false}, nil
+		}
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	// Get jaeger span for reconcile, add to ctx
+	reconcileSpan := r.initTracer(schedule)
+	if reconcileSpan != nil {
+		ctx = opentracing.ContextWithSpan(ctx, reconcileSpan)
+		defer reconcileSpan.Finish()
+	}
+
+	// Report reconcile error.
+	defer func() {
+		log.Info("CR", "conditions", schedule.Status.Conditions)
+		schedule.Status.Conditions.RecordEvents(schedule, r.EventRecorder)
+		if err == nil || errors.IsConflict(errorutil.Unwrap(err)) {
+			return
+		}
+		schedule.Status.SetReconcileFailed(err)
+		err := r.Update(context.TODO(), schedule)
+		if err != nil {
+			log.Error(err, " ")
+			return
+		}
+	}()
+
+	// Begin staging conditions.
+	schedule.Status.BeginStagingConditions()
+
+	// Validations.
+	plan, err := r.validate(ctx, schedule)
+	if err != nil {
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	// Ready
+	schedule.Status.SetReady(
+		!schedule.Status.HasBlockerCondition(),
+		"The schedule is ready.")
+
+	// Create the scheduled migration when due.
+	requeueAfter, err := r.schedule(ctx, schedule, plan, time.Now().UTC())
+	if err != nil {
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	// End staging conditions.
+	schedule.Status.EndStagingConditions()
+
+	// Apply changes.
+	schedule.MarkReconciled()
+	err = r.Update(context.TODO(), schedule)
+	if err != nil {
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	// Wait for the next run.
+	if requeueAfter > 0 {
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// Done
+	return reconcile.Result{Requeue: false}, nil
+}
@@ -0,0 +1,125 @@
+package migschedule
+
+import (
+	"reflect"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	migref "github.com/konveyor/mig-controller/pkg/reference"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+type SchedulePredicate struct {
+	predicate.Funcs
+	Namespace string
+}
+
+func (r SchedulePredicate) Create(e event.CreateEvent) bool {
+	if r.Namespace != "" && r.Namespace != e.Object.GetNamespace() {
+		return false
+	}
+	schedule, cast := e.Object.(*migapi.MigSchedule)
+	if cast {
+		r.mapRefs(schedule)
+	}
+	return true
+}
+
+func (r SchedulePredicate) Update(e event.UpdateEvent) bool {
+	if r.Namespace != "" && r.Namespace != e.ObjectNew.GetNamespace() {
+		return false
+	}
+	old, cast := e.ObjectOld.(*migapi.MigSchedule)
+	if !cast {
+		return true
+	}
+	new, cast := e.ObjectNew.(*migapi.MigSchedule)
+	if !cast {
+		return true
+	}
+	changed := !reflect.DeepEqual(old.Spec, new.Spec)
+	if changed {
+		r.unmapRefs(old)
+		r.mapRefs(new)
+	}
+	return changed
+}
+
+func (r SchedulePredicate) Delete(e event.DeleteEvent) bool {
+	if r.Namespace != "" && r.Namespace != e.Object.GetNamespace() {
+		return false
+	}
+	schedule, cast := e.Object.(*migapi.MigSchedule)
+	if cast {
+		r.unmapRefs(schedule)
+	}
+	return true
+}
+
+func (r SchedulePredicate) mapRefs(schedule *migapi.MigSchedule) {
+	refMap := migref.GetMap()
+
+	refOwner := migref.RefOwner{
+		Kind:      migref.ToKind(schedule),
+		Namespace: schedule.Namespace,
+		Name:      schedule.Name,
+	}
+
+	// Plan
+	ref := schedule.Spec.MigPlanRef
+	if migref.RefSet(ref) {
+		refMap.Add(refOwner, migref.RefTarget{
+			Kind:      migref.ToKind(migapi.MigPlan{}),
+			Namespace: ref.Namespace,
+			Name:      ref.Name,
+		})
+	}
+}
+
+func (r SchedulePredicate) unmapRefs(schedule *migapi.MigSchedule) {
+	refMap := migref.GetMap()
+
+	refOwner := migref.RefOwner{
+		Kind:      migref.ToKind(schedule),
+		Namespace: schedule.Namespace,
+		Name:      schedule.Name,
+	}
+
+	// Plan
+	ref := schedule.Spec.MigPlanRef
+	if migref.RefSet(ref) {
+		refMap.Delete(refOwner, migref.RefTarget{
+			Kind:      migref.ToKind(migapi.MigPlan{}),
+			Namespace: ref.Namespace,
+			Name:      ref.Name,
+		})
+	}
+}
+
+// Plan predicate, only changes affecting
+// the ability to schedule migrations are relevant.
+type PlanPredicate struct {
+	predicate.Funcs
+}
+
+func (r PlanPredicate) Create(e event.CreateEvent) bool {
+	return true
+}
+
+func (r PlanPredicate) Update(e event.UpdateEvent) bool {
+	old, cast := e.ObjectOld.(*migapi.MigPlan)
+	if !cast {
+		return false
+	}
+	new, cast := e.ObjectNew.(*migapi.MigPlan)
+	if !cast {
+		return false
+	}
+	closed := old.Spec.Closed != new.Spec.Closed
+	ready := old.Status.IsReady() != new.Status.IsReady()
+	return closed || ready
+}
+
+func (r PlanPredicate) Delete(e event.DeleteEvent) bool {
+	return true
+}
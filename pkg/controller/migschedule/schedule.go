@@ -0,0 +1,209 @@
+package migschedule
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/opentracing/opentracing-go"
+	kapi "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Phase of a migration that has finished, successfully or not.
+const Completed = "Completed"
+
+// Condition of a migration that has completed with warnings.
+const SucceededWithWarnings = "SucceededWithWarnings"
+
+// Create the stage migration when the schedule is due and
+// prune migrations beyond the history limits.
+// Returns the duration until the next scheduled run, 0 when
+// there is nothing scheduled.
+func (r *ReconcileMigSchedule) schedule(ctx context.Context, schedule *migapi.MigSchedule, plan *migapi.MigPlan, now time.Time) (time.Duration, error) {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "schedule")
+		defer span.Finish()
+	}
+
+	err := r.prune(schedule)
+	if err != nil {
+		return 0, err
+	}
+
+	if plan == nil || schedule.Status.HasBlockerCondition() {
+		schedule.Status.NextScheduleTime = nil
+		return 0, nil
+	}
+	cron, err := parseCron(schedule.Spec.Schedule)
+	if err != nil {
+		return 0, err
+	}
+
+	// Runs are counted from the last run, or from the
+	// creation of the schedule. Missed runs are not
+	// caught up, a single migration is created for them.
+	last := schedule.CreationTimestamp.Time
+	if schedule.Status.LastScheduleTime != nil {
+		last = schedule.Status.LastScheduleTime.Time
+	}
+	next := cron.Next(last.UTC())
+	if !next.IsZero() && !next.After(now) {
+		if !schedule.Spec.Suspend {
+			err = r.run(schedule, plan, next, now)
+			if err != nil {
+				return 0, err
+			}
+		}
+		next = cron.Next(now)
+	}
+	if next.IsZero() {
+		schedule.Status.NextScheduleTime = nil
+		return 0, nil
+	}
+	schedule.Status.NextScheduleTime = &metav1.Time{Time: next}
+	if schedule.Spec.Suspend {
+		return 0, nil
+	}
+
+	return next.Sub(now), nil
+}
+
+// Run the schedule.
+// A stage migration is created unless the concurrency
+// policy forbids it while another migration of the plan is running.
+// The migration is named after the scheduled time, a run retried before
+// the last schedule time is persisted does not create another migration.
+func (r *ReconcileMigSchedule) run(schedule *migapi.MigSchedule, plan *migapi.MigPlan, scheduled, now time.Time) error {
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: now}
+
+	if schedule.GetConcurrencyPolicy() == migapi.ForbidConcurrent {
+		running, err := r.findRunningMigration(plan)
+		if err != nil {
+			return err
+		}
+		if running != nil {
+			log.Info("Skipped scheduled migration, a migration of the plan is running.",
+				"migMigration", path.Join(running.Namespace, running.Name))
+			schedule.Status.SetCondition(migapi.Condition{
+				Type:     Skipped,
+				Status:   True,
+				Reason:   Running,
+				Category: Advisory,
+				Message: fmt.Sprintf("The scheduled migration at %s was skipped, migration %s is running.",
+					now.Format(time.RFC3339), running.Name),
+				Durable: true,
+			})
+			return nil
+		}
+	}
+
+	migration := &migapi.MigMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      migrationName(schedule, scheduled),
+			Namespace: schedule.Namespace,
+			Labels:    schedule.GetCorrelationLabels(),
+		},
+		Spec: migapi.MigMigrationSpec{
+			MigPlanRef: &kapi.ObjectReference{
+				Namespace: plan.Namespace,
+				Name:      plan.Name,
+			},
+			Stage:       true,
+			QuiescePods: schedule.Spec.QuiescePods,
+		},
+	}
+	err := r.Create(context.TODO(), migration)
+	switch {
+	case err == nil:
+		log.Info("Created scheduled migration.",
+			"migMigration", path.Join(migration.Namespace, migration.Name))
+	case k8serror.IsAlreadyExists(err):
+		log.Info("Found scheduled migration already created.",
+			"migMigration", path.Join(migration.Namespace, migration.Name))
+	default:
+		return err
+	}
+	schedule.Status.DeleteCondition(Skipped)
+	schedule.Status.LastMigrationRef = &kapi.ObjectReference{
+		Namespace: migration.Namespace,
+		Name:      migration.Name,
+	}
+
+	return nil
+}
+
+// Get the name of the migration of a scheduled run.
+// The scheduled time is in minutes, the resolution of the schedules.
+func migrationName(schedule *migapi.MigSchedule, scheduled time.Time) string {
+	return fmt.Sprintf("%s-%d", schedule.Name, scheduled.Unix()/60)
+}
+
+// Find a migration of the plan that is running or waiting to run.
+// Returns `nil` when not found.
+func (r *ReconcileMigSchedule) findRunningMigration(plan *migapi.MigPlan) (*migapi.MigMigration, error) {
+	migrations, err := plan.ListMigrations(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		if m.Status.Phase == Completed || m.Status.HasBlockerCondition() {
+			continue
+		}
+		if m.Status.HasAnyCondition(migapi.Succeeded, SucceededWithWarnings, migapi.Failed) {
+			continue
+		}
+		return m, nil
+	}
+
+	return nil, nil
+}
+
+// Delete the completed migrations created by the schedule
+// beyond the succeeded and failed history limits, oldest first.
+// A migration completed with warnings has succeeded.
+func (r *ReconcileMigSchedule) prune(schedule *migapi.MigSchedule) error {
+	migrations, err := schedule.ListMigrations(r)
+	if err != nil {
+		return err
+	}
+
+	// Sort migrations by timestamp, newest first.
+	sort.Slice(migrations, func(i, j int) bool {
+		ts1 := migrations[i].CreationTimestamp
+		ts2 := migrations[j].CreationTimestamp
+		return ts1.Time.After(ts2.Time)
+	})
+
+	succeeded := 0
+	failed := 0
+	for i := range migrations {
+		m := &migrations[i]
+		if m.Status.Phase != Completed {
+			continue
+		}
+		if m.Status.HasAnyCondition(migapi.Succeeded, SucceededWithWarnings) {
+			succeeded++
+			if succeeded <= schedule.GetSucceededHistoryLimit() {
+				continue
+			}
+		} else {
+			failed++
+			if failed <= schedule.GetFailedHistoryLimit() {
+				continue
+			}
+		}
+		err = r.Delete(context.TODO(), m)
+		if err != nil && !k8serror.IsNotFound(err) {
+			return err
+		}
+		log.Info("Pruned scheduled migration beyond history limit.",
+			"migMigration", path.Join(m.Namespace, m.Name))
+	}
+
+	return nil
+}
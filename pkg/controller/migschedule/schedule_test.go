@@ -0,0 +1,101 @@
+package migschedule
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeClient(t *testing.T, objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := migapi.AddToScheme(scheme); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+func TestReconcileMigSchedule_prune(t *testing.T) {
+	one := int32(1)
+	schedule := &migapi.MigSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: migapi.OpenshiftMigrationNamespace, UID: "schedule-uid"},
+		Spec: migapi.MigScheduleSpec{
+			SucceededHistoryLimit: &one,
+			FailedHistoryLimit:    &one,
+		},
+	}
+	created := time.Date(2021, time.March, 10, 0, 0, 0, 0, time.UTC)
+	migration := func(name string, age int, conditions ...string) *migapi.MigMigration {
+		m := &migapi.MigMigration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         schedule.Namespace,
+				Labels:            schedule.GetCorrelationLabels(),
+				CreationTimestamp: metav1.Time{Time: created.Add(-time.Duration(age) * time.Hour)},
+			},
+			Status: migapi.MigMigrationStatus{Phase: Completed},
+		}
+		for _, condition := range conditions {
+			m.Status.SetCondition(migapi.Condition{Type: condition, Status: True, Durable: true})
+		}
+		return m
+	}
+	r := &ReconcileMigSchedule{
+		Client: newFakeClient(t,
+			migration("succeeded-new", 1, migapi.Succeeded),
+			migration("warnings-old", 2, SucceededWithWarnings),
+			migration("failed-new", 3, migapi.Failed),
+			migration("failed-old", 4, migapi.Failed)),
+	}
+	if err := r.prune(schedule); err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	migrations, err := schedule.ListMigrations(r)
+	if err != nil {
+		t.Fatalf("ListMigrations() error = %v", err)
+	}
+	got := []string{}
+	for _, m := range migrations {
+		got = append(got, m.Name)
+	}
+	sort.Strings(got)
+	want := []string{"failed-new", "succeeded-new"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("prune() kept %v, want %v", got, want)
+	}
+}
+
+func TestReconcileMigSchedule_run(t *testing.T) {
+	schedule := &migapi.MigSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: migapi.OpenshiftMigrationNamespace, UID: "schedule-uid"},
+		Spec:       migapi.MigScheduleSpec{ConcurrencyPolicy: migapi.AllowConcurrent},
+	}
+	plan := &migapi.MigPlan{
+		ObjectMeta: metav1.ObjectMeta{Name: "plan", Namespace: migapi.OpenshiftMigrationNamespace},
+	}
+	r := &ReconcileMigSchedule{Client: newFakeClient(t)}
+	scheduled := time.Date(2021, time.March, 10, 2, 0, 0, 0, time.UTC)
+	// the status update of the first run failed, the run is retried
+	for _, now := range []time.Time{scheduled.Add(time.Second), scheduled.Add(time.Minute)} {
+		if err := r.run(schedule.DeepCopy(), plan, scheduled, now); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+	}
+	list := migapi.MigMigrationList{}
+	if err := r.List(context.TODO(), &list); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("run() created %d migrations, want 1", len(list.Items))
+	}
+	if name := list.Items[0].Name; name != "nightly-26922360" {
+		t.Errorf("run() migration name = %v, want nightly-26922360", name)
+	}
+}
@@ -0,0 +1,166 @@
+package migschedule
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	migref "github.com/konveyor/mig-controller/pkg/reference"
+	"github.com/opentracing/opentracing-go"
+)
+
+// Types
+const (
+	InvalidPlanRef           = "InvalidPlanRef"
+	PlanNotReady             = "PlanNotReady"
+	PlanClosed               = "PlanClosed"
+	InvalidSchedule          = "InvalidSchedule"
+	InvalidConcurrencyPolicy = "InvalidConcurrencyPolicy"
+	Skipped                  = "Skipped"
+)
+
+// Categories
+const (
+	Critical = migapi.Critical
+	Advisory = migapi.Advisory
+)
+
+// Reasons
+const (
+	NotSet       = "NotSet"
+	NotFound     = "NotFound"
+	NotSupported = "NotSupported"
+	Running      = "Running"
+)
+
+// Statuses
+const (
+	True  = migapi.True
+	False = migapi.False
+)
+
+// Validate the schedule resource.
+// Returns the plan, `nil` when the reference cannot be resolved.
+func (r ReconcileMigSchedule) validate(ctx context.Context, schedule *migapi.MigSchedule) (*migapi.MigPlan, error) {
+	if opentracing.SpanFromContext(ctx) != nil {
+		var span opentracing.Span
+		span, ctx = opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validate")
+		defer span.Finish()
+	}
+
+	// Plan
+	plan, err := r.validatePlan(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schedule
+	r.validateSchedule(ctx, schedule)
+
+	return plan, nil
+}
+
+// Validate the referenced plan.
+func (r ReconcileMigSchedule) validatePlan(ctx context.Context, schedule *migapi.MigSchedule) (*migapi.MigPlan, error) {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validatePlan")
+		defer span.Finish()
+	}
+	ref := schedule.Spec.MigPlanRef
+
+	// NotSet
+	if !migref.RefSet(ref) {
+		schedule.Status.SetCondition(migapi.Condition{
+			Type:     InvalidPlanRef,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "The `migPlanRef` must reference a valid `migplan`.",
+		})
+		return nil, nil
+	}
+
+	plan, err := migapi.GetPlan(r, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// NotFound
+	if plan == nil {
+		schedule.Status.SetCondition(migapi.Condition{
+			Type:     InvalidPlanRef,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message: fmt.Sprintf("The `migPlanRef` must reference a valid `migplan`, subject: %s.",
+				path.Join(ref.Namespace, ref.Name)),
+		})
+		return nil, nil
+	}
+
+	// NotReady
+	if !plan.Status.IsReady() {
+		schedule.Status.SetCondition(migapi.Condition{
+			Type:     PlanNotReady,
+			Status:   True,
+			Category: Critical,
+			Message: fmt.Sprintf("The referenced `migPlanRef` does not have a `Ready` condition, subject: %s.",
+				path.Join(ref.Namespace, ref.Name)),
+		})
+	}
+
+	// Closed
+	if plan.Spec.Closed {
+		schedule.Status.SetCondition(migapi.Condition{
+			Type:     PlanClosed,
+			Status:   True,
+			Category: Critical,
+			Message: fmt.Sprintf("The associated migration plan is closed, subject: %s.",
+				path.Join(ref.Namespace, ref.Name)),
+		})
+	}
+
+	return plan, nil
+}
+
+// Validate the cron expression and the concurrency policy.
+func (r ReconcileMigSchedule) validateSchedule(ctx context.Context, schedule *migapi.MigSchedule) {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateSchedule")
+		defer span.Finish()
+	}
+
+	// Schedule
+	if schedule.Spec.Schedule == "" {
+		schedule.Status.SetCondition(migapi.Condition{
+			Type:     InvalidSchedule,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "The `schedule` must be set to a cron expression.",
+		})
+	} else if _, err := parseCron(schedule.Spec.Schedule); err != nil {
+		schedule.Status.SetCondition(migapi.Condition{
+			Type:     InvalidSchedule,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message:  fmt.Sprintf("The `schedule` is not a valid cron expression: %s.", err),
+		})
+	}
+
+	// Concurrency policy
+	switch schedule.GetConcurrencyPolicy() {
+	case migapi.ForbidConcurrent, migapi.AllowConcurrent:
+	default:
+		schedule.Status.SetCondition(migapi.Condition{
+			Type:     InvalidConcurrencyPolicy,
+			Status:   True,
+			Reason:   NotSupported,
+			Category: Critical,
+			Message: fmt.Sprintf("The `concurrencyPolicy` must be one of: %s, %s.",
+				migapi.ForbidConcurrent, migapi.AllowConcurrent),
+		})
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2021 Red Hat Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migwave
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/konveyor/mig-controller/pkg/errorutil"
+	migref "github.com/konveyor/mig-controller/pkg/reference"
+	"github.com/opentracing/opentracing-go"
+	"k8s.io/klog/v2/klogr"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = klogr.New().WithName("wave")
+
+// Add creates a new MigWave Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileMigWave{Client: mgr.GetClient(), scheme: mgr.GetScheme(), EventRecorder: mgr.GetEventRecorderFor("migwave_controller")}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("migwave-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to MigWave
+	err = c.Watch(
+		&source.Kind{Type: &migapi.MigWave{}},
+		&handler.EnqueueRequestForObject{},
+		&WavePredicate{
+			Namespace: migapi.OpenshiftMigrationNamespace,
+		})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to MigMigrations created by MigWaves.
+	err = c.Watch(
+		&source.Kind{Type: &migapi.MigMigration{}},
+		handler.EnqueueRequestsFromMapFunc(func(a client.Object) []reconcile.Request {
+			return getWaveRequests(mgr.GetClient(), a)
+		}),
+		&migref.MigrationNamespacePredicate{Namespace: migapi.OpenshiftMigrationNamespace})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to MigPlans referenced by MigWaves.
+	err = c.Watch(
+		&source.Kind{Type: &migapi.MigPlan{}},
+		handler.EnqueueRequestsFromMapFunc(func(a client.Object) []reconcile.Request {
+			return migref.GetRequests(a, migapi.OpenshiftMigrationNamespace, migapi.MigWave{})
+		}),
+		&PlanPredicate{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileMigWave{}
+
+// ReconcileMigWave reconciles a MigWave object
+type ReconcileMigWave struct {
+	client.Client
+	record.EventRecorder
+
+	scheme *runtime.Scheme
+	tracer opentracing.Tracer
+}
+
+func (r *ReconcileMigWave) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	var err error
+	log = log.WithName("wave").WithValues("migWave", request.Name)
+
+	// Fetch the MigWave instance
+	wave := &migapi.MigWave{}
+	err = r.Get(context.TODO(), request.NamespacedName, wave)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{Requeue: false}, nil
+		}
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	// Deleted: wait for the migrations of the wave to complete.
+	if wave.DeletionTimestamp != nil {
+		return r.finalize(wave)
+	}
+
+	// Get jaeger span for reconcile, add to ctx
+	reconcileSpan := r.initTracer(wave)
+	if reconcileSpan != nil {
+		ctx = opentracing.ContextWithSpan(ctx, reconcileSpan)
+		defer reconcileSpan.Finish()
+	}
+
+	// Report reconcile error.
+	defer func() {
+		log.Info("CR", "conditions", wave.Status.Conditions)
+		wave.Status.Conditions.RecordEvents(wave, r.EventRecorder)
+		if err == nil || errors.IsConflict(errorutil.Unwrap(err)) {
+			return
+		}
+		wave.Status.SetReconcileFailed(err)
+		err := r.Update(context.TODO(), wave)
+		if err != nil {
+			log.Error(err, " ")
+			return
+		}
+	}()
+
+	// Begin staging conditions.
+	wave.Status.BeginStagingConditions()
+
+	// Validations.
+	plans, err := r.validate(ctx, wave)
+	if err != nil {
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	// Ready
+	wave.Status.SetReady(
+		!wave.Status.HasBlockerCondition(),
+		"The wave is ready.")
+
+	// Keep the wave while its migrations run.
+	controllerutil.AddFinalizer(wave, migapi.MigWaveFinalizer)
+
+	// Migrate the plans.
+	err = r.run(ctx, wave, plans)
+	if err != nil {
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	// End staging conditions.
+	wave.Status.EndStagingConditions()
+
+	// Apply changes.
+	wave.MarkReconciled()
+	err = r.Update(context.TODO(), wave)
+	if err != nil {
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	// Done
+	return reconcile.Result{Requeue: false}, nil
+}
+
+// Finalize a deleted wave.
+// The migrations of the wave are not deleted with the wave. The deletion
+// is refused, keeping the finalizer, while any of them is running.
+func (r *ReconcileMigWave) finalize(wave *migapi.MigWave) (reconcile.Result, error) {
+	if !controllerutil.ContainsFinalizer(wave, migapi.MigWaveFinalizer) {
+		return reconcile.Result{Requeue: false}, nil
+	}
+	migrations, err := wave.ListMigrations(r)
+	if err != nil {
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+	running := runningMigrations(migrations)
+	if len(running) > 0 {
+		log.Info("Deletion of the wave waits for its migrations to complete.",
+			"migrations", running)
+		wave.Status.SetCondition(migapi.Condition{
+			Type:     DeletionBlocked,
+			Status:   True,
+			Reason:   MigrationRunning,
+			Category: Warn,
+			Message: fmt.Sprintf("The wave is deleted once its running migrations complete: [%s].",
+				strings.Join(running, ", ")),
+		})
+	} else {
+		controllerutil.RemoveFinalizer(wave, migapi.MigWaveFinalizer)
+	}
+	err = r.Update(context.TODO(), wave)
+	if err != nil {
+		log.Error(err, " ")
+		return reconcile.Result{Requeue: true}, nil
+	}
+
+	return reconcile.Result{Requeue: false}, nil
+}
+
+// Get the request for the wave which created a migration.
+// The wave is found using the correlation label of the migration.
+func getWaveRequests(c client.Client, a client.Object) []reconcile.Request {
+	key, _ := migapi.CorrelationLabel(&migapi.MigWave{}, "")
+	uid, found := a.GetLabels()[key]
+	if !found {
+		return nil
+	}
+	list := migapi.MigWaveList{}
+	err := c.List(context.TODO(), &list, client.InNamespace(a.GetNamespace()))
+	if err != nil {
+		log.Error(err, " ")
+		return nil
+	}
+	for _, wave := range list.Items {
+		if string(wave.UID) == uid {
+			return []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Namespace: wave.Namespace,
+						Name:      wave.Name,
+					},
+				},
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package migwave
+
+import (
+	"reflect"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	migref "github.com/konveyor/mig-controller/pkg/reference"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+type WavePredicate struct {
+	predicate.Funcs
+	Namespace string
+}
+
+func (r WavePredicate) Create(e event.CreateEvent) bool {
+	if r.Namespace != "" && r.Namespace != e.Object.GetNamespace() {
+		return false
+	}
+	wave, cast := e.Object.(*migapi.MigWave)
+	if cast {
+		r.mapRefs(wave)
+	}
+	return true
+}
+
+func (r WavePredicate) Update(e event.UpdateEvent) bool {
+	if r.Namespace != "" && r.Namespace != e.ObjectNew.GetNamespace() {
+		return false
+	}
+	old, cast := e.ObjectOld.(*migapi.MigWave)
+	if !cast {
+		return true
+	}
+	new, cast := e.ObjectNew.(*migapi.MigWave)
+	if !cast {
+		return true
+	}
+	changed := !reflect.DeepEqual(old.Spec, new.Spec)
+	if changed {
+		r.unmapRefs(old)
+		r.mapRefs(new)
+	}
+	return changed ||
+		!reflect.DeepEqual(old.DeletionTimestamp, new.DeletionTimestamp)
+}
+
+func (r WavePredicate) Delete(e event.DeleteEvent) bool {
+	if r.Namespace != "" && r.Namespace != e.Object.GetNamespace() {
+		return false
+	}
+	wave, cast := e.Object.(*migapi.MigWave)
+	if cast {
+		r.unmapRefs(wave)
+	}
+	return true
+}
+
+func (r WavePredicate) mapRefs(wave *migapi.MigWave) {
+	refMap := migref.GetMap()
+
+	refOwner := migref.RefOwner{
+		Kind:      migref.ToKind(wave),
+		Namespace: wave.Namespace,
+		Name:      wave.Name,
+	}
+
+	// Plans
+	for _, wavePlan := range wave.Spec.Plans {
+		ref := wavePlan.MigPlanRef
+		if migref.RefSet(ref) {
+			refMap.Add(refOwner, migref.RefTarget{
+				Kind:      migref.ToKind(migapi.MigPlan{}),
+				Namespace: ref.Namespace,
+				Name:      ref.Name,
+			})
+		}
+	}
+}
+
+func (r WavePredicate) unmapRefs(wave *migapi.MigWave) {
+	refMap := migref.GetMap()
+
+	refOwner := migref.RefOwner{
+		Kind:      migref.ToKind(wave),
+		Namespace: wave.Namespace,
+		Name:      wave.Name,
+	}
+
+	// Plans
+	for _, wavePlan := range wave.Spec.Plans {
+		ref := wavePlan.MigPlanRef
+		if migref.RefSet(ref) {
+			refMap.Delete(refOwner, migref.RefTarget{
+				Kind:      migref.ToKind(migapi.MigPlan{}),
+				Namespace: ref.Namespace,
+				Name:      ref.Name,
+			})
+		}
+	}
+}
+
+// Plan predicate, only changes affecting
+// the ability to migrate the plan are relevant.
+type PlanPredicate struct {
+	predicate.Funcs
+}
+
+func (r PlanPredicate) Create(e event.CreateEvent) bool {
+	return true
+}
+
+func (r PlanPredicate) Update(e event.UpdateEvent) bool {
+	old, cast := e.ObjectOld.(*migapi.MigPlan)
+	if !cast {
+		return false
+	}
+	new, cast := e.ObjectNew.(*migapi.MigPlan)
+	if !cast {
+		return false
+	}
+	closed := old.Spec.Closed != new.Spec.Closed
+	ready := old.Status.IsReady() != new.Status.IsReady()
+	return closed || ready
+}
+
+func (r PlanPredicate) Delete(e event.DeleteEvent) bool {
+	return true
+}
@@ -0,0 +1,281 @@
+package migwave
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	migref "github.com/konveyor/mig-controller/pkg/reference"
+	"github.com/opentracing/opentracing-go"
+)
+
+// Types
+const (
+	NoPlans           = "NoPlans"
+	InvalidPlanRef    = "InvalidPlanRef"
+	DuplicatePlan     = "DuplicatePlan"
+	InvalidDependency = "InvalidDependency"
+	DependencyCycle   = "DependencyCycle"
+	PlanNotReady      = "PlanNotReady"
+	PlanClosed        = "PlanClosed"
+	DeletionBlocked   = "DeletionBlocked"
+)
+
+// Categories
+const (
+	Critical = migapi.Critical
+	Advisory = migapi.Advisory
+	Warn     = migapi.Warn
+)
+
+// Reasons
+const (
+	NotSet           = "NotSet"
+	NotFound         = "NotFound"
+	MigrationRunning = "MigrationRunning"
+)
+
+// Statuses
+const (
+	True  = migapi.True
+	False = migapi.False
+)
+
+// Validate the wave resource.
+// Returns the resolved plans keyed by name.
+func (r ReconcileMigWave) validate(ctx context.Context, wave *migapi.MigWave) (map[string]*migapi.MigPlan, error) {
+	if opentracing.SpanFromContext(ctx) != nil {
+		var span opentracing.Span
+		span, ctx = opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validate")
+		defer span.Finish()
+	}
+
+	// Plans
+	plans, err := r.validatePlans(ctx, wave)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dependencies
+	r.validateDependencies(ctx, wave)
+
+	return plans, nil
+}
+
+// Validate the referenced plans.
+// Readiness is only required for the plans not yet started by the wave.
+func (r ReconcileMigWave) validatePlans(ctx context.Context, wave *migapi.MigWave) (map[string]*migapi.MigPlan, error) {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validatePlans")
+		defer span.Finish()
+	}
+	plans := map[string]*migapi.MigPlan{}
+
+	// NoPlans
+	if len(wave.Spec.Plans) == 0 {
+		wave.Status.SetCondition(migapi.Condition{
+			Type:     NoPlans,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "The `plans` must reference at least one `migplan`.",
+		})
+		return plans, nil
+	}
+
+	notSet := false
+	notFound := []string{}
+	duplicates := []string{}
+	notReady := []string{}
+	closed := []string{}
+	for _, wavePlan := range wave.Spec.Plans {
+		ref := wavePlan.MigPlanRef
+		if !migref.RefSet(ref) {
+			notSet = true
+			continue
+		}
+		if _, found := plans[ref.Name]; found {
+			duplicates = append(duplicates, ref.Name)
+			continue
+		}
+		plan, err := migapi.GetPlan(r, ref)
+		if err != nil {
+			return nil, err
+		}
+		if plan == nil {
+			notFound = append(notFound, path.Join(ref.Namespace, ref.Name))
+			continue
+		}
+		plans[ref.Name] = plan
+		status := wave.Status.FindPlan(ref.Name)
+		if status != nil && status.MigrationRef != nil {
+			continue
+		}
+		if !plan.Status.IsReady() {
+			notReady = append(notReady, plan.Name)
+		}
+		if plan.Spec.Closed {
+			closed = append(closed, plan.Name)
+		}
+	}
+
+	// NotSet
+	if notSet {
+		wave.Status.SetCondition(migapi.Condition{
+			Type:     InvalidPlanRef,
+			Status:   True,
+			Reason:   NotSet,
+			Category: Critical,
+			Message:  "The `migPlanRef` of every plan in the wave must reference a valid `migplan`.",
+		})
+	}
+
+	// NotFound
+	if len(notFound) > 0 {
+		wave.Status.SetCondition(migapi.Condition{
+			Type:     InvalidPlanRef,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message:  "The `migPlanRef` must reference a valid `migplan`, subjects: [].",
+			Items:    notFound,
+		})
+	}
+
+	// Duplicate
+	if len(duplicates) > 0 {
+		wave.Status.SetCondition(migapi.Condition{
+			Type:     DuplicatePlan,
+			Status:   True,
+			Category: Critical,
+			Message:  "Plans [] are listed more than once in the wave.",
+			Items:    duplicates,
+		})
+	}
+
+	// NotReady
+	if len(notReady) > 0 {
+		wave.Status.SetCondition(migapi.Condition{
+			Type:     PlanNotReady,
+			Status:   True,
+			Category: Critical,
+			Message:  "Plans [] do not have a `Ready` condition.",
+			Items:    notReady,
+		})
+	}
+
+	// Closed
+	if len(closed) > 0 {
+		wave.Status.SetCondition(migapi.Condition{
+			Type:     PlanClosed,
+			Status:   True,
+			Category: Critical,
+			Message:  "Plans [] are closed.",
+			Items:    closed,
+		})
+	}
+
+	return plans, nil
+}
+
+// Validate the dependencies between the plans.
+func (r ReconcileMigWave) validateDependencies(ctx context.Context, wave *migapi.MigWave) {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "validateDependencies")
+		defer span.Finish()
+	}
+
+	// Unknown
+	names := map[string]bool{}
+	for _, wavePlan := range wave.Spec.Plans {
+		if migref.RefSet(wavePlan.MigPlanRef) {
+			names[wavePlan.MigPlanRef.Name] = true
+		}
+	}
+	invalid := []string{}
+	for _, wavePlan := range wave.Spec.Plans {
+		if !migref.RefSet(wavePlan.MigPlanRef) {
+			continue
+		}
+		for _, dependency := range wavePlan.DependsOn {
+			if !names[dependency] || dependency == wavePlan.MigPlanRef.Name {
+				invalid = append(invalid,
+					fmt.Sprintf("%s->%s", wavePlan.MigPlanRef.Name, dependency))
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		wave.Status.SetCondition(migapi.Condition{
+			Type:     InvalidDependency,
+			Status:   True,
+			Reason:   NotFound,
+			Category: Critical,
+			Message:  "Dependencies [] must reference other plans listed in the wave.",
+			Items:    invalid,
+		})
+		return
+	}
+
+	// Cycle
+	cycle := findCycle(wave)
+	if len(cycle) > 0 {
+		wave.Status.SetCondition(migapi.Condition{
+			Type:     DependencyCycle,
+			Status:   True,
+			Category: Critical,
+			Message:  "The dependencies of plans [] form a cycle.",
+			Items:    cycle,
+		})
+	}
+}
+
+// Find a dependency cycle between the plans of the wave.
+// Returns the names of the plans in the cycle, empty when none.
+func findCycle(wave *migapi.MigWave) []string {
+	dependsOn := map[string][]string{}
+	for _, wavePlan := range wave.Spec.Plans {
+		if migref.RefSet(wavePlan.MigPlanRef) {
+			dependsOn[wavePlan.MigPlanRef.Name] = wavePlan.DependsOn
+		}
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	stack := []string{}
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			for i := range stack {
+				if stack[i] == name {
+					return append([]string{}, stack[i:]...)
+				}
+			}
+		}
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dependency := range dependsOn[name] {
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		return nil
+	}
+	for _, wavePlan := range wave.Spec.Plans {
+		if !migref.RefSet(wavePlan.MigPlanRef) {
+			continue
+		}
+		if cycle := visit(wavePlan.MigPlanRef.Name); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
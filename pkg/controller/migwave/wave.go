@@ -0,0 +1,432 @@
+package migwave
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	"github.com/opentracing/opentracing-go"
+	kapi "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Phase of a migration that has finished, successfully or not.
+const Completed = "Completed"
+
+// Condition of a migration that has completed with warnings.
+const SucceededWithWarnings = "SucceededWithWarnings"
+
+// Run the wave.
+// The status of each plan is refreshed from its migrations, then
+// the plans ready to be migrated (or rolled back) are started
+// within the parallelism limit and the overall status is reported.
+func (r *ReconcileMigWave) run(ctx context.Context, wave *migapi.MigWave, plans map[string]*migapi.MigPlan) error {
+	if opentracing.SpanFromContext(ctx) != nil {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, r.tracer, "run")
+		defer span.Finish()
+	}
+
+	r.ensurePlanStatus(wave)
+	err := r.updatePlanStatus(wave)
+	if err != nil {
+		return err
+	}
+
+	if !wave.Status.HasBlockerCondition() && !isFinished(wave) {
+		if rollingBack(wave) {
+			err = r.rollback(wave)
+		} else {
+			err = r.start(wave, plans)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	updateWaveStatus(wave)
+
+	return nil
+}
+
+// Ensure the status lists every plan of the wave, in order.
+func (r *ReconcileMigWave) ensurePlanStatus(wave *migapi.MigWave) {
+	list := []migapi.WavePlanStatus{}
+	for _, wavePlan := range wave.Spec.Plans {
+		if wavePlan.MigPlanRef == nil || wavePlan.MigPlanRef.Name == "" {
+			continue
+		}
+		status := wave.Status.FindPlan(wavePlan.MigPlanRef.Name)
+		if status != nil {
+			list = append(list, *status)
+			continue
+		}
+		list = append(list, migapi.WavePlanStatus{
+			Name:  wavePlan.MigPlanRef.Name,
+			Phase: migapi.WavePending,
+		})
+	}
+	wave.Status.Plans = list
+}
+
+// Refresh the phase and progress of each plan using its migrations.
+func (r *ReconcileMigWave) updatePlanStatus(wave *migapi.MigWave) error {
+	for i := range wave.Status.Plans {
+		status := &wave.Status.Plans[i]
+		ref := status.MigrationRef
+		rollback := status.RollbackRef != nil
+		if rollback {
+			ref = status.RollbackRef
+		}
+		if ref == nil {
+			continue
+		}
+		migration, err := migapi.GetMigration(r, ref)
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			status.Phase = migapi.WaveFailed
+			status.Message = fmt.Sprintf("Migration %s not found.", ref.Name)
+			continue
+		}
+		status.Step, status.CompletedSteps, status.TotalSteps = pipelineProgress(migration)
+		setPlanPhase(status, migration, rollback)
+	}
+
+	return nil
+}
+
+// Set the phase of a plan from its migration (or rollback).
+// A migration completed with warnings has succeeded.
+func setPlanPhase(status *migapi.WavePlanStatus, migration *migapi.MigMigration, rollback bool) {
+	switch {
+	case migration.Status.HasAnyCondition(migapi.Succeeded, SucceededWithWarnings):
+		status.Phase = migapi.WaveSucceeded
+		if rollback {
+			status.Phase = migapi.WaveRolledBack
+		}
+		status.Message = ""
+	case migration.Status.HasCondition(migapi.Failed),
+		migration.Status.Phase == Completed:
+		status.Phase = migapi.WaveFailed
+		status.Message = fmt.Sprintf("Migration %s failed.", migration.Name)
+		if rollback {
+			status.Message = fmt.Sprintf("Rollback %s failed.", migration.Name)
+		}
+	default:
+		status.Phase = migapi.WaveRunning
+		if rollback {
+			status.Phase = migapi.WaveRollingBack
+		}
+		status.Message = ""
+	}
+}
+
+// Start the migration of the pending plans having all
+// dependencies migrated successfully.
+func (r *ReconcileMigWave) start(wave *migapi.MigWave, plans map[string]*migapi.MigPlan) error {
+	policy := wave.GetFailurePolicy()
+	if policy == migapi.StopWave && wave.Status.HasPlanPhase(migapi.WaveFailed) {
+		return nil
+	}
+	running := countPlanPhase(wave, migapi.WaveRunning)
+	for _, wavePlan := range nextPlans(wave) {
+		if running >= wave.GetMaxParallel() {
+			break
+		}
+		if _, found := plans[wavePlan.MigPlanRef.Name]; !found {
+			continue
+		}
+		ref, err := r.ensureMigration(wave, wavePlan.MigPlanRef, false)
+		if err != nil {
+			return err
+		}
+		status := findStatus(wave, wavePlan)
+		status.MigrationRef = ref
+		status.Phase = migapi.WaveRunning
+		running++
+		if wave.Status.StartTimestamp == nil {
+			now := metav1.Now()
+			wave.Status.StartTimestamp = &now
+		}
+	}
+
+	// Plans depending on a failed plan are never started.
+	if policy == migapi.ContinueWave {
+		for _, wavePlan := range wave.Spec.Plans {
+			status := findStatus(wave, wavePlan)
+			if status == nil || status.Phase != migapi.WavePending {
+				continue
+			}
+			for _, dependency := range wavePlan.DependsOn {
+				depStatus := wave.Status.FindPlan(dependency)
+				if depStatus == nil {
+					continue
+				}
+				if depStatus.Phase == migapi.WaveFailed || depStatus.Phase == migapi.WaveSkipped {
+					status.Phase = migapi.WaveSkipped
+					status.Message = fmt.Sprintf("Dependency %s was not migrated.", dependency)
+					break
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Roll back every migrated plan of the wave.
+// Running migrations are waited for first. A plan is rolled
+// back only after the plans depending on it were rolled back.
+func (r *ReconcileMigWave) rollback(wave *migapi.MigWave) error {
+	if wave.Status.HasPlanPhase(migapi.WaveRunning) {
+		return nil
+	}
+	rollingBack := countPlanPhase(wave, migapi.WaveRollingBack)
+	for _, wavePlan := range rollbackPlans(wave) {
+		if rollingBack >= wave.GetMaxParallel() {
+			break
+		}
+		status := findStatus(wave, wavePlan)
+		ref, err := r.ensureMigration(wave, wavePlan.MigPlanRef, true)
+		if err != nil {
+			return err
+		}
+		status.RollbackRef = ref
+		status.Phase = migapi.WaveRollingBack
+		status.Step, status.CompletedSteps, status.TotalSteps = "", 0, 0
+		rollingBack++
+	}
+
+	return nil
+}
+
+// Ensure the (rollback) migration of a plan exists.
+// The migration has a deterministic name so it is never created twice.
+func (r *ReconcileMigWave) ensureMigration(wave *migapi.MigWave, planRef *kapi.ObjectReference, rollback bool) (*kapi.ObjectReference, error) {
+	name := fmt.Sprintf("%s-%s", wave.Name, planRef.Name)
+	if rollback {
+		name += "-rollback"
+	}
+	ref := &kapi.ObjectReference{
+		Namespace: wave.Namespace,
+		Name:      name,
+	}
+	migration := &migapi.MigMigration{}
+	err := r.Get(context.TODO(), types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, migration)
+	if err == nil {
+		return ref, nil
+	}
+	if !k8serror.IsNotFound(err) {
+		return nil, err
+	}
+	// The migration is labeled rather than owned by the wave so
+	// that deleting the wave does not delete its migrations.
+	migration = &migapi.MigMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.Name,
+			Namespace: ref.Namespace,
+			Labels:    wave.GetCorrelationLabels(),
+		},
+		Spec: migapi.MigMigrationSpec{
+			MigPlanRef: &kapi.ObjectReference{
+				Namespace: planRef.Namespace,
+				Name:      planRef.Name,
+			},
+			Rollback:    rollback,
+			QuiescePods: wave.Spec.QuiescePods && !rollback,
+		},
+	}
+	err = r.Create(context.TODO(), migration)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created wave migration.",
+		"migMigration", path.Join(migration.Namespace, migration.Name),
+		"rollback", rollback)
+
+	return ref, nil
+}
+
+// Get the names of the migrations not yet completed.
+func runningMigrations(migrations []migapi.MigMigration) []string {
+	names := []string{}
+	for _, migration := range migrations {
+		if migration.Status.HasAnyCondition(migapi.Succeeded, SucceededWithWarnings, migapi.Failed) ||
+			migration.Status.Phase == Completed {
+			continue
+		}
+		names = append(names, migration.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get the pending plans having all dependencies migrated successfully, in order.
+func nextPlans(wave *migapi.MigWave) []migapi.WavePlan {
+	list := []migapi.WavePlan{}
+	for _, wavePlan := range wave.Spec.Plans {
+		status := findStatus(wave, wavePlan)
+		if status == nil || status.Phase != migapi.WavePending {
+			continue
+		}
+		ready := true
+		for _, dependency := range wavePlan.DependsOn {
+			depStatus := wave.Status.FindPlan(dependency)
+			if depStatus == nil || depStatus.Phase != migapi.WaveSucceeded {
+				ready = false
+				break
+			}
+		}
+		if ready {
+			list = append(list, wavePlan)
+		}
+	}
+
+	return list
+}
+
+// Get the migrated plans not yet rolled back that no plan still
+// to be rolled back depends on, in reverse order.
+func rollbackPlans(wave *migapi.MigWave) []migapi.WavePlan {
+	// Plans that still need to be rolled back, or are rolling back.
+	remaining := map[string]bool{}
+	for _, status := range wave.Status.Plans {
+		if status.MigrationRef == nil {
+			continue
+		}
+		if status.RollbackRef == nil || status.Phase == migapi.WaveRollingBack {
+			remaining[status.Name] = true
+		}
+	}
+	list := []migapi.WavePlan{}
+	for i := len(wave.Spec.Plans) - 1; i >= 0; i-- {
+		wavePlan := wave.Spec.Plans[i]
+		status := findStatus(wave, wavePlan)
+		if status == nil || status.MigrationRef == nil || status.RollbackRef != nil {
+			continue
+		}
+		blocked := false
+		for _, dependent := range wave.Spec.Plans {
+			if dependent.MigPlanRef == nil || !remaining[dependent.MigPlanRef.Name] {
+				continue
+			}
+			for _, dependency := range dependent.DependsOn {
+				if dependency == wavePlan.MigPlanRef.Name {
+					blocked = true
+				}
+			}
+		}
+		if !blocked {
+			list = append(list, wavePlan)
+		}
+	}
+
+	return list
+}
+
+// Get whether the wave rolls back the migrated plans.
+func rollingBack(wave *migapi.MigWave) bool {
+	if wave.GetFailurePolicy() != migapi.RollbackWave {
+		return false
+	}
+	for _, status := range wave.Status.Plans {
+		if status.RollbackRef != nil || status.Phase == migapi.WaveFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get whether the wave has finished.
+func isFinished(wave *migapi.MigWave) bool {
+	return wave.Status.CompletionTimestamp != nil
+}
+
+// Set the phase and progress of the wave using the status of the plans.
+func updateWaveStatus(wave *migapi.MigWave) {
+	completed := 0
+	total := 0
+	for _, status := range wave.Status.Plans {
+		completed += status.CompletedSteps
+		total += status.TotalSteps
+	}
+	wave.Status.CompletedSteps = completed
+	wave.Status.TotalSteps = total
+
+	if isFinished(wave) {
+		return
+	}
+
+	phase := migapi.WavePending
+	running := wave.Status.HasPlanPhase(migapi.WaveRunning, migapi.WaveRollingBack)
+	switch {
+	case rollingBack(wave):
+		phase = migapi.WaveRollingBack
+		if !running && len(rollbackPlans(wave)) == 0 {
+			phase = migapi.WaveRolledBack
+			for _, status := range wave.Status.Plans {
+				if status.MigrationRef != nil && status.Phase != migapi.WaveRolledBack {
+					phase = migapi.WaveFailed
+				}
+			}
+		}
+	case running:
+		phase = migapi.WaveRunning
+	case countPlanPhase(wave, migapi.WaveSucceeded) == len(wave.Status.Plans) && len(wave.Status.Plans) > 0:
+		phase = migapi.WaveSucceeded
+	case wave.Status.HasPlanPhase(migapi.WaveFailed):
+		phase = migapi.WaveFailed
+	}
+	wave.Status.Phase = phase
+
+	switch phase {
+	case migapi.WaveSucceeded, migapi.WaveFailed, migapi.WaveRolledBack:
+		now := metav1.Now()
+		wave.Status.CompletionTimestamp = &now
+		log.Info("Wave completed.", "phase", phase)
+	}
+}
+
+// Get the pipeline progress of a migration.
+// Returns the current step, the number of completed steps and the number of steps.
+func pipelineProgress(migration *migapi.MigMigration) (string, int, int) {
+	current := ""
+	completed := 0
+	for _, step := range migration.Status.Pipeline {
+		if step.Completed != nil {
+			completed++
+			continue
+		}
+		if current == "" && step.Started != nil {
+			current = step.Name
+		}
+	}
+
+	return current, completed, len(migration.Status.Pipeline)
+}
+
+// Count the plans of the wave in the specified phase.
+func countPlanPhase(wave *migapi.MigWave, phase string) int {
+	count := 0
+	for _, status := range wave.Status.Plans {
+		if status.Phase == phase {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Find the status of a plan of the wave.
+func findStatus(wave *migapi.MigWave, wavePlan migapi.WavePlan) *migapi.WavePlanStatus {
+	if wavePlan.MigPlanRef == nil {
+		return nil
+	}
+	return wave.Status.FindPlan(wavePlan.MigPlanRef.Name)
+}
@@ -0,0 +1,300 @@
+package migwave
+
+import (
+	"reflect"
+	"testing"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	kapi "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newWave(dependencies map[string][]string, order []string, phases map[string]string) *migapi.MigWave {
+	wave := &migapi.MigWave{}
+	for _, name := range order {
+		wave.Spec.Plans = append(wave.Spec.Plans, migapi.WavePlan{
+			MigPlanRef: &kapi.ObjectReference{Namespace: "openshift-migration", Name: name},
+			DependsOn:  dependencies[name],
+		})
+		status := migapi.WavePlanStatus{Name: name, Phase: phases[name]}
+		if status.Phase == "" {
+			status.Phase = migapi.WavePending
+		}
+		switch status.Phase {
+		case migapi.WaveRunning, migapi.WaveSucceeded, migapi.WaveFailed:
+			status.MigrationRef = &kapi.ObjectReference{Name: name}
+		case migapi.WaveRollingBack, migapi.WaveRolledBack:
+			status.MigrationRef = &kapi.ObjectReference{Name: name}
+			status.RollbackRef = &kapi.ObjectReference{Name: name + "-rollback"}
+		}
+		wave.Status.Plans = append(wave.Status.Plans, status)
+	}
+	return wave
+}
+
+func planNames(plans []migapi.WavePlan) []string {
+	names := []string{}
+	for _, plan := range plans {
+		names = append(names, plan.MigPlanRef.Name)
+	}
+	return names
+}
+
+func Test_findCycle(t *testing.T) {
+	tests := []struct {
+		name         string
+		order        []string
+		dependencies map[string][]string
+		want         []string
+	}{
+		{
+			name:         "no dependencies",
+			order:        []string{"db", "api", "web"},
+			dependencies: map[string][]string{},
+			want:         nil,
+		},
+		{
+			name:  "chain",
+			order: []string{"db", "api", "web"},
+			dependencies: map[string][]string{
+				"api": {"db"},
+				"web": {"api"},
+			},
+			want: nil,
+		},
+		{
+			name:  "diamond",
+			order: []string{"db", "cache", "api", "web"},
+			dependencies: map[string][]string{
+				"api": {"db", "cache"},
+				"web": {"db", "api"},
+			},
+			want: nil,
+		},
+		{
+			name:  "cycle",
+			order: []string{"db", "api", "web"},
+			dependencies: map[string][]string{
+				"db":  {"web"},
+				"api": {"db"},
+				"web": {"api"},
+			},
+			want: []string{"db", "web", "api"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wave := newWave(tt.dependencies, tt.order, nil)
+			if got := findCycle(wave); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_nextPlans(t *testing.T) {
+	dependencies := map[string][]string{
+		"api": {"db", "cache"},
+		"web": {"api"},
+	}
+	order := []string{"db", "cache", "api", "web"}
+	tests := []struct {
+		name   string
+		phases map[string]string
+		want   []string
+	}{
+		{
+			name:   "nothing started",
+			phases: map[string]string{},
+			want:   []string{"db", "cache"},
+		},
+		{
+			name: "dependency running",
+			phases: map[string]string{
+				"db":    migapi.WaveSucceeded,
+				"cache": migapi.WaveRunning,
+			},
+			want: []string{},
+		},
+		{
+			name: "dependencies succeeded",
+			phases: map[string]string{
+				"db":    migapi.WaveSucceeded,
+				"cache": migapi.WaveSucceeded,
+			},
+			want: []string{"api"},
+		},
+		{
+			name: "dependency failed",
+			phases: map[string]string{
+				"db":    migapi.WaveSucceeded,
+				"cache": migapi.WaveFailed,
+			},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wave := newWave(dependencies, order, tt.phases)
+			if got := planNames(nextPlans(wave)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nextPlans() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rollbackPlans(t *testing.T) {
+	dependencies := map[string][]string{
+		"api": {"db"},
+		"web": {"api"},
+	}
+	order := []string{"db", "api", "web"}
+	tests := []struct {
+		name   string
+		phases map[string]string
+		want   []string
+	}{
+		{
+			name: "dependents first",
+			phases: map[string]string{
+				"db":  migapi.WaveSucceeded,
+				"api": migapi.WaveSucceeded,
+				"web": migapi.WaveFailed,
+			},
+			want: []string{"web"},
+		},
+		{
+			name: "dependent rolling back",
+			phases: map[string]string{
+				"db":  migapi.WaveSucceeded,
+				"api": migapi.WaveSucceeded,
+				"web": migapi.WaveRollingBack,
+			},
+			want: []string{},
+		},
+		{
+			name: "dependent rolled back",
+			phases: map[string]string{
+				"db":  migapi.WaveSucceeded,
+				"api": migapi.WaveSucceeded,
+				"web": migapi.WaveRolledBack,
+			},
+			want: []string{"api"},
+		},
+		{
+			name: "dependent never migrated",
+			phases: map[string]string{
+				"db":  migapi.WaveSucceeded,
+				"api": migapi.WaveFailed,
+			},
+			want: []string{"api"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wave := newWave(dependencies, order, tt.phases)
+			wave.Spec.FailurePolicy = migapi.RollbackWave
+			if got := planNames(rollbackPlans(wave)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rollbackPlans() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runningMigrations(t *testing.T) {
+	newMigration := func(name, phase string, conditions ...string) migapi.MigMigration {
+		migration := migapi.MigMigration{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		migration.Status.Phase = phase
+		for _, condition := range conditions {
+			migration.Status.SetCondition(migapi.Condition{Type: condition, Status: migapi.True})
+		}
+		return migration
+	}
+	migrations := []migapi.MigMigration{
+		newMigration("web", "EnsureInitialBackup", migapi.Running),
+		newMigration("db", Completed, migapi.Succeeded),
+		newMigration("api", ""),
+		newMigration("cache", Completed, migapi.Failed),
+		newMigration("queue", Completed),
+	}
+	want := []string{"api", "web"}
+	if got := runningMigrations(migrations); !reflect.DeepEqual(got, want) {
+		t.Errorf("runningMigrations() = %v, want %v", got, want)
+	}
+}
+
+func Test_setPlanPhase(t *testing.T) {
+	newMigration := func(phase string, conditions ...string) *migapi.MigMigration {
+		migration := &migapi.MigMigration{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+		migration.Status.Phase = phase
+		for _, condition := range conditions {
+			migration.Status.SetCondition(migapi.Condition{Type: condition, Status: migapi.True})
+		}
+		return migration
+	}
+	tests := []struct {
+		name      string
+		migration *migapi.MigMigration
+		rollback  bool
+		want      string
+	}{
+		{
+			name:      "running migration",
+			migration: newMigration("EnsureInitialBackup", migapi.Running),
+			want:      migapi.WaveRunning,
+		},
+		{
+			name:      "succeeded migration",
+			migration: newMigration(Completed, migapi.Succeeded),
+			want:      migapi.WaveSucceeded,
+		},
+		{
+			name:      "migration succeeded with warnings",
+			migration: newMigration(Completed, SucceededWithWarnings),
+			want:      migapi.WaveSucceeded,
+		},
+		{
+			name:      "failed migration",
+			migration: newMigration(Completed, migapi.Failed),
+			want:      migapi.WaveFailed,
+		},
+		{
+			name:      "migration completed without result",
+			migration: newMigration(Completed),
+			want:      migapi.WaveFailed,
+		},
+		{
+			name:      "running rollback",
+			migration: newMigration("DeleteMigrated", migapi.Running),
+			rollback:  true,
+			want:      migapi.WaveRollingBack,
+		},
+		{
+			name:      "succeeded rollback",
+			migration: newMigration(Completed, migapi.Succeeded),
+			rollback:  true,
+			want:      migapi.WaveRolledBack,
+		},
+		{
+			name:      "rollback succeeded with warnings",
+			migration: newMigration(Completed, SucceededWithWarnings),
+			rollback:  true,
+			want:      migapi.WaveRolledBack,
+		},
+		{
+			name:      "failed rollback",
+			migration: newMigration(Completed, migapi.Failed),
+			rollback:  true,
+			want:      migapi.WaveFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &migapi.WavePlanStatus{Name: "web", Phase: migapi.WavePending}
+			setPlanPhase(status, tt.migration, tt.rollback)
+			if status.Phase != tt.want {
+				t.Errorf("setPlanPhase() = %v, want %v", status.Phase, tt.want)
+			}
+		})
+	}
+}
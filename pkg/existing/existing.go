@@ -0,0 +1,173 @@
+package existing
+
+import (
+	"context"
+	"strings"
+
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Resources never reported. Velero does not restore them or
+// they are managed by the destination cluster.
+var ignoredResources = map[string]bool{
+	"events":                          true,
+	"events.events.k8s.io":            true,
+	"endpoints":                       true,
+	"endpointslices.discovery.k8s.io": true,
+	"pods.metrics.k8s.io":             true,
+	"backups.velero.io":               true,
+	"restores.velero.io":              true,
+	"resticrepositories.velero.io":    true,
+}
+
+// Resources created in each namespace by the destination cluster.
+var namespaceDefaults = map[string][]string{
+	"serviceaccounts": {"default", "builder", "deployer"},
+	"configmaps":      {"kube-root-ca.crt", "openshift-service-ca.crt"},
+}
+
+// Resource of a source namespace existing in the destination namespace.
+type Resource struct {
+	// The resource (resource.group).
+	Resource string `json:"resource"`
+	// The source namespace.
+	Namespace string `json:"namespace"`
+	// The destination namespace.
+	DestinationNamespace string `json:"destinationNamespace"`
+	// The name.
+	Name string `json:"name"`
+	// Restored by a migration of the plan.
+	Migrated bool `json:"migrated"`
+}
+
+// Finder finds the resources of the source namespaces
+// already existing in the destination namespaces.
+type Finder struct {
+	// Source cluster client.
+	Source dynamic.Interface
+	// Destination cluster client.
+	Destination dynamic.Interface
+	// Namespaced resources of the source cluster.
+	GVRs []schema.GroupVersionResource
+	// The plan.
+	Plan *migapi.MigPlan
+	// Resource filters selecting the migrated resources.
+	Filters migapi.ResourceFilters
+}
+
+// Find the existing resources.
+// The resources excluded from the migration, owned by other resources and
+// created by the destination cluster in each namespace are not reported.
+func (r *Finder) Find() ([]Resource, error) {
+	found := []Resource{}
+	excluded := map[string]bool{}
+	for _, resource := range r.Plan.Status.ResourceList() {
+		excluded[resource] = true
+	}
+	mapping := r.Plan.GetNamespaceMapping()
+	for _, gvr := range r.GVRs {
+		resource := gvr.GroupResource().String()
+		if ignoredResources[resource] || excluded[resource] {
+			continue
+		}
+		for _, ns := range r.Plan.GetSourceNamespaces() {
+			destNs := mapping[ns]
+			destList, err := r.list(r.Destination, gvr, destNs)
+			if err != nil {
+				return nil, err
+			}
+			if len(destList) == 0 {
+				continue
+			}
+			srcList, err := r.list(r.Source, gvr, ns)
+			if err != nil {
+				return nil, err
+			}
+			for name, object := range destList {
+				source, exists := srcList[name]
+				if !exists || ignored(gvr, object) {
+					continue
+				}
+				selected, err := r.Filters.Selects(source.GroupVersionKind().GroupKind(), source)
+				if err != nil {
+					return nil, err
+				}
+				if !selected {
+					continue
+				}
+				found = append(found, Resource{
+					Resource:             resource,
+					Namespace:            ns,
+					DestinationNamespace: destNs,
+					Name:                 name,
+					Migrated:             object.GetLabels()[migapi.MigPlanLabel] == string(r.Plan.UID),
+				})
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// List the resources of a namespace keyed by name.
+// Resources not served by the cluster are not listed.
+func (r *Finder) list(client dynamic.Interface, gvr schema.GroupVersionResource, namespace string) (map[string]*unstructured.Unstructured, error) {
+	listed := map[string]*unstructured.Unstructured{}
+	list, err := client.Resource(gvr).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		if k8serror.IsMethodNotSupported(err) || k8serror.IsNotFound(err) || k8serror.IsForbidden(err) {
+			return listed, nil
+		}
+		return nil, err
+	}
+	for i := range list.Items {
+		object := &list.Items[i]
+		listed[object.GetName()] = object
+	}
+	return listed, nil
+}
+
+// Get whether the destination resource is not reported.
+func ignored(gvr schema.GroupVersionResource, object *unstructured.Unstructured) bool {
+	if len(object.GetOwnerReferences()) > 0 {
+		return true
+	}
+	for _, name := range namespaceDefaults[gvr.GroupResource().String()] {
+		if object.GetName() == name {
+			return true
+		}
+	}
+	switch gvr.GroupResource().String() {
+	case "secrets":
+		if _, found := object.GetAnnotations()[corev1.ServiceAccountNameKey]; found {
+			return true
+		}
+	case "rolebindings.rbac.authorization.k8s.io":
+		if strings.HasPrefix(object.GetName(), "system:") {
+			return true
+		}
+	}
+	return false
+}
+
+// Conflicts returns the existing resources not restored by a migration of the plan.
+func Conflicts(resources []Resource) []Resource {
+	conflicts := []Resource{}
+	for _, resource := range resources {
+		if !resource.Migrated {
+			conflicts = append(conflicts, resource)
+		}
+	}
+	return conflicts
+}
+
+// String representation of the resource.
+func (r Resource) String() string {
+	return r.Resource + " " + r.DestinationNamespace + "/" + r.Name
+}
@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	mapset "github.com/deckarep/golang-set"
@@ -315,6 +316,27 @@ func GetNamespacedGVRsForCluster(cluster *migapi.MigCluster, c client.Client) (d
 	return dynamic, GVRs, nil
 }
 
+// GetNamespacedGVRsForConfig collects all namespace-scoped GVRs for the cluster of the provided REST configuration
+func GetNamespacedGVRsForConfig(restCfg *rest.Config) (dynamic.Interface, []schema.GroupVersionResource, error) {
+	dynamic, err := dynamic.NewForConfig(restCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	discovery, err := discovery.NewDiscoveryClientForConfig(restCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	resourceList, err := collectNamespacedResources(discovery)
+	if err != nil {
+		return nil, nil, err
+	}
+	GVRs, err := convertToGVRList(resourceList)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dynamic, GVRs, nil
+}
+
 func excludeSubresources(resources []metav1.APIResource) []metav1.APIResource {
 	filteredList := []metav1.APIResource{}
 	for _, res := range resources {
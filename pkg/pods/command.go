@@ -2,34 +2,61 @@ package pods
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"net/http"
+	"time"
+
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
 
 // Command executed on a Pod.
 // RestCfg - The REST configuration for the cluster.
 // Pod - The pod on which to execute the command.
+// Container - An (optional) container, defaults to the only container.
 // Args - The command (and args) to execute.
+// Timeout - An (optional) limit on the time to wait for the command.
 // In - An (optional) command input stream.
 // Out - The command output stream set by `Run()`.
 // Err - the command error stream set by `Run()`.
 type PodCommand struct {
-	RestCfg *rest.Config
-	Pod     *v1.Pod
-	Args    []string
-	In      io.Reader
-	Out     bytes.Buffer
-	Err     bytes.Buffer
+	RestCfg   *rest.Config
+	Pod       *v1.Pod
+	Container string
+	Args      []string
+	Timeout   time.Duration
+	In        io.Reader
+	Out       bytes.Buffer
+	Err       bytes.Buffer
 }
 
 // Run the command.
+// The command is cancelled when the (optional) timeout expires.
 func (p *PodCommand) Run() error {
+	if p.Timeout == 0 {
+		return p.RunWithContext(context.Background())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout)
+	defer cancel()
+	err := p.RunWithContext(ctx)
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("command timed out after %s", p.Timeout)
+	}
+	return err
+}
+
+// RunWithContext runs the command until it completes or the context is done.
+// The connection to the pod is closed when the context is done.
+func (p *PodCommand) RunWithContext(ctx context.Context) error {
 	codec := serializer.NewCodecFactory(scheme.Scheme)
 	restClient, err := apiutil.RESTClientForGVK(
 		schema.GroupVersionKind{
@@ -49,14 +76,25 @@ func (p *PodCommand) Run() error {
 		SubResource("exec")
 	post.VersionedParams(
 		&v1.PodExecOptions{
-			Command: p.Args,
-			Stdin:   true,
-			Stdout:  true,
-			Stderr:  true,
+			Container: p.Container,
+			Command:   p.Args,
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
 		},
 		scheme.ParameterCodec)
-	executor, err := remotecommand.NewSPDYExecutor(
-		p.RestCfg,
+	transport, upgrader, err := spdy.RoundTripperFor(p.RestCfg)
+	if err != nil {
+		return err
+	}
+	stream := &contextStream{
+		ctx:       ctx,
+		transport: transport,
+		upgrader:  upgrader,
+	}
+	executor, err := remotecommand.NewSPDYExecutorForTransports(
+		stream,
+		stream,
 		"POST",
 		post.URL())
 	if err != nil {
@@ -67,15 +105,45 @@ func (p *PodCommand) Run() error {
 	}
 	p.Out = bytes.Buffer{}
 	p.Err = bytes.Buffer{}
-	err = executor.Stream(remotecommand.StreamOptions{
-		Stdin:  p.In,
-		Stdout: &p.Out,
-		Stderr: &p.Err,
-		Tty:    false,
-	})
-	if err != nil {
-		return err
+	err = executor.Stream(
+		remotecommand.StreamOptions{
+			Stdin:  p.In,
+			Stdout: &p.Out,
+			Stderr: &p.Err,
+			Tty:    false,
+		})
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
+	return err
+}
+
+// Binds the SPDY connection of a command to a context.
+// The request is cancelled and the upgraded connection is closed
+// when the context is done, which ends the streaming.
+type contextStream struct {
+	ctx       context.Context
+	transport http.RoundTripper
+	upgrader  spdy.Upgrader
+}
 
-	return nil
+// RoundTrip sends the request bound to the context.
+func (s *contextStream) RoundTrip(req *http.Request) (*http.Response, error) {
+	return s.transport.RoundTrip(req.WithContext(s.ctx))
+}
+
+// NewConnection creates the upgraded connection closed when the context is done.
+func (s *contextStream) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	conn, err := s.upgrader.NewConnection(resp)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			conn.Close()
+		case <-conn.CloseChan():
+		}
+	}()
+	return conn, nil
 }
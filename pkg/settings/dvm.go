@@ -17,6 +17,9 @@ const (
 	RsyncOptInfo                  = "RSYNC_OPT_INFO"
 	RsyncOptExtras                = "RSYNC_OPT_EXTRAS"
 	RsyncBackOffLimit             = "RSYNC_BACKOFF_LIMIT"
+	RsyncMaxTransfers             = "RSYNC_MAX_TRANSFERS"
+	RsyncMaxTransfersPerNode      = "RSYNC_MAX_TRANSFERS_PER_NODE"
+	RsyncMaxTransfersPerSC        = "RSYNC_MAX_TRANSFERS_PER_STORAGE_CLASS"
 	EnablePVResizing              = "ENABLE_DVM_PV_RESIZING"
 	TCPProxyKey                   = "STUNNEL_TCP_PROXY"
 	StunnelVerifyCAKey            = "STUNNEL_VERIFY_CA"
@@ -45,6 +48,17 @@ type RsyncOpts struct {
 	BackOffLimit int
 }
 
+// RsyncTransferLimitOpts maximum numbers of concurrent Rsync transfers, 0 when not limited
+//
+//	MaxTransfers: transfers of the DVMs on a source cluster
+//	MaxTransfersPerNode: transfers of the DVMs per source node
+//	MaxTransfersPerStorageClass: transfers of the DVMs per source storage class
+type RsyncTransferLimitOpts struct {
+	MaxTransfers                int
+	MaxTransfersPerNode         int
+	MaxTransfersPerStorageClass int
+}
+
 type FileOwnershipOpts struct {
 	SourceSupplementalGroups      []int64
 	DestinationSupplementalGroups []int64
@@ -84,6 +98,7 @@ func parseIntListFromString(str string) ([]int64, error) {
 // DvmOpts DVM settings
 type DvmOpts struct {
 	RsyncOpts
+	RsyncTransferLimitOpts
 	FileOwnershipOpts
 	EnablePVResizing     bool
 	StunnelTCPProxy      string
@@ -117,6 +132,24 @@ func (r *RsyncOpts) Load() error {
 	return err
 }
 
+// Load load rsync transfer limits
+func (r *RsyncTransferLimitOpts) Load() error {
+	var err error
+	r.MaxTransfers, err = getEnvLimit(RsyncMaxTransfers, 0)
+	if err != nil {
+		return err
+	}
+	r.MaxTransfersPerNode, err = getEnvLimit(RsyncMaxTransfersPerNode, 0)
+	if err != nil {
+		return err
+	}
+	r.MaxTransfersPerStorageClass, err = getEnvLimit(RsyncMaxTransfersPerSC, 0)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // Load loads DVM options
 func (r *DvmOpts) Load() error {
 	var err error
@@ -131,6 +164,10 @@ func (r *DvmOpts) Load() error {
 	if err != nil {
 		return err
 	}
+	err = r.RsyncTransferLimitOpts.Load()
+	if err != nil {
+		return err
+	}
 	err = r.FileOwnershipOpts.Load()
 	if err != nil {
 		return err
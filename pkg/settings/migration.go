@@ -0,0 +1,88 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Migration options
+const (
+	PhaseTimeout  = "MIGRATION_PHASE_TIMEOUT"
+	PhaseTimeouts = "MIGRATION_PHASE_TIMEOUTS"
+)
+
+// Migration settings.
+//
+//	PhaseTimeout: Default deadline of every migration phase, 0 means no deadline.
+//	PhaseTimeouts: Deadlines of individual migration phases keyed by phase name.
+//	  Set with a comma separated list of <phase>=<duration> pairs.
+type Migration struct {
+	PhaseTimeout  time.Duration
+	PhaseTimeouts map[string]time.Duration
+}
+
+// Load settings.
+func (r *Migration) Load() error {
+	var err error
+	r.PhaseTimeout, err = getEnvDuration(PhaseTimeout, 0)
+	if err != nil {
+		return err
+	}
+	r.PhaseTimeouts, err = parseDurationMap(os.Getenv(PhaseTimeouts))
+	if err != nil {
+		return fmt.Errorf("%s: %w", PhaseTimeouts, err)
+	}
+	return nil
+}
+
+// GetPhaseTimeout returns the deadline of a migration phase.
+// Returns 0 when the phase has no deadline.
+func (r *Migration) GetPhaseTimeout(phase string) time.Duration {
+	if timeout, found := r.PhaseTimeouts[phase]; found {
+		return timeout
+	}
+	return r.PhaseTimeout
+}
+
+// Get a non-negative duration from the environment
+// using the specified variable name and default.
+func getEnvDuration(name string, def time.Duration) (time.Duration, error) {
+	s, found := os.LookupEnv(name)
+	if !found {
+		return def, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration", name)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s must be >= 0", name)
+	}
+	return d, nil
+}
+
+// Parse a comma separated list of <key>=<duration> pairs.
+func parseDurationMap(str string) (map[string]time.Duration, error) {
+	durations := map[string]time.Duration{}
+	for _, pair := range strings.Split(str, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected <name>=<duration>", pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration in entry %q", pair)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("negative duration in entry %q", pair)
+		}
+		durations[strings.TrimSpace(kv[0])] = d
+	}
+	return durations, nil
+}
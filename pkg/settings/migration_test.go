@@ -0,0 +1,62 @@
+package settings
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_parseDurationMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		str     string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{
+			name: "given a list of valid pairs, must return map of durations, must not return error",
+			str:  "WaitForResticReady=30m, EnsureQuiesced=1h",
+			want: map[string]time.Duration{
+				"WaitForResticReady": 30 * time.Minute,
+				"EnsureQuiesced":     time.Hour,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "given an empty string, must return empty map, must not return error",
+			str:     "",
+			want:    map[string]time.Duration{},
+			wantErr: false,
+		},
+		{
+			name:    "given a pair without duration, must return error",
+			str:     "EnsureQuiesced",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "given an invalid duration, must return error",
+			str:     "EnsureQuiesced=forever",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "given a negative duration, must return error",
+			str:     "EnsureQuiesced=-1m",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDurationMap(tt.str)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDurationMap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDurationMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
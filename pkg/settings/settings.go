@@ -34,9 +34,11 @@ var Settings = _Settings{}
 // Settings
 //
 //	Plan: Plan settings.
+//	Migration: Migration settings.
 type _Settings struct {
 	Discovery
 	Plan
+	Migration
 	DvmOpts
 	DisImgCopy         bool
 	EnableCachedClient bool
@@ -55,6 +57,10 @@ func (r *_Settings) Load() error {
 	if err != nil {
 		return err
 	}
+	err = r.Migration.Load()
+	if err != nil {
+		return err
+	}
 	err = r.DvmOpts.Load()
 	if err != nil {
 		return err
@@ -0,0 +1,143 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	migapi "github.com/konveyor/mig-controller/pkg/apis/migration/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Validate the transform.
+// The patches must be valid JSON or YAML documents.
+func Validate(transform *migapi.RestoreTransform) error {
+	if transform.Name == "" {
+		return errors.New("name must be set")
+	}
+	if transform.Kind == "" {
+		return errors.New("kind must be set")
+	}
+	if transform.JSONPatch == "" && transform.MergePatch == "" {
+		return errors.New("jsonPatch or mergePatch must be set")
+	}
+	if strings.Contains(transform.Name, ",") {
+		return errors.New("name must not contain commas")
+	}
+	if transform.LabelSelector != nil {
+		_, err := metav1.LabelSelectorAsSelector(transform.LabelSelector)
+		if err != nil {
+			return fmt.Errorf("labelSelector: %s", err.Error())
+		}
+	}
+	if transform.JSONPatch != "" {
+		_, err := decodeJSONPatch(transform.JSONPatch)
+		if err != nil {
+			return fmt.Errorf("jsonPatch: %s", err.Error())
+		}
+	}
+	if transform.MergePatch != "" {
+		_, err := yaml.ToJSON([]byte(transform.MergePatch))
+		if err != nil {
+			return fmt.Errorf("mergePatch: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// Selects returns whether the transform applies to the resource.
+// The namespace is the source namespace of the resource.
+func Selects(transform *migapi.RestoreTransform, namespace string, object *unstructured.Unstructured) (bool, error) {
+	if object.GroupVersionKind().GroupKind() != transform.GroupKind() {
+		return false, nil
+	}
+	if !transform.AppliesTo(namespace) {
+		return false, nil
+	}
+	if transform.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(transform.LabelSelector)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(labels.Set(object.GetLabels())) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Applied returns whether the transform has been applied to the resource.
+func Applied(transform *migapi.RestoreTransform, object *unstructured.Unstructured) bool {
+	applied := object.GetAnnotations()[migapi.TransformsAnnotation]
+	for _, name := range strings.Split(applied, ",") {
+		if name == transform.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply the patches of the transform to the resource.
+// The transform is recorded in the annotations of the returned resource.
+func Apply(transform *migapi.RestoreTransform, object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	content, err := object.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if transform.MergePatch != "" {
+		patch, err := yaml.ToJSON([]byte(transform.MergePatch))
+		if err != nil {
+			return nil, err
+		}
+		dataStruct, err := scheme.Scheme.New(object.GroupVersionKind())
+		if err == nil {
+			content, err = strategicpatch.StrategicMergePatch(content, patch, dataStruct)
+		} else {
+			content, err = jsonpatch.MergePatch(content, patch)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if transform.JSONPatch != "" {
+		patch, err := decodeJSONPatch(transform.JSONPatch)
+		if err != nil {
+			return nil, err
+		}
+		content, err = patch.Apply(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	transformed := &unstructured.Unstructured{}
+	err = transformed.UnmarshalJSON(content)
+	if err != nil {
+		return nil, err
+	}
+	annotations := transformed.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if applied := annotations[migapi.TransformsAnnotation]; applied != "" {
+		annotations[migapi.TransformsAnnotation] = applied + "," + transform.Name
+	} else {
+		annotations[migapi.TransformsAnnotation] = transform.Name
+	}
+	transformed.SetAnnotations(annotations)
+	return transformed, nil
+}
+
+// Decode a JSON patch in JSON or YAML.
+func decodeJSONPatch(document string) (jsonpatch.Patch, error) {
+	content, err := yaml.ToJSON([]byte(document))
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.DecodePatch(content)
+}